@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"sync"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -21,7 +20,8 @@ var buildCmd = &cobra.Command{
 	Short: "Build the project or specific packages",
 	Long: `Build the project with optimizations.
 
-Supports parallel builds and watch mode for faster development.`,
+Packages are built in dependency order, in parallel where possible, and
+skipped when their sources have not changed since the last build.`,
 	RunE: runBuild,
 }
 
@@ -36,7 +36,6 @@ func runBuild(cmd *cobra.Command, args []string) error {
 	green := color.New(color.FgGreen, color.Bold)
 	yellow := color.New(color.FgYellow)
 
-	// Check if we're in a monorepo or single project
 	isMonorepo := fileExists("pnpm-workspace.yaml")
 
 	if buildWatch {
@@ -45,111 +44,31 @@ func runBuild(cmd *cobra.Command, args []string) error {
 
 	cyan.Println("\n🔨 Building project...")
 
-	var buildCommand string
-	var buildArgs []string
-
-	if isMonorepo {
-		if buildPackage != "" {
-			// Build specific package
-			cyan.Printf("\n📦 Building package: %s\n\n", buildPackage)
-			buildCommand = "pnpm"
-			buildArgs = []string{"--filter", buildPackage, "build"}
-		} else if buildParallel {
-			// Build all packages in parallel
-			cyan.Println("\n⚡ Building all packages in parallel...\n")
-			return buildMonorepoParallel()
-		} else {
-			// Build sequentially using the script
-			buildCommand = "pnpm"
-			buildArgs = []string{"run", "build"}
-		}
-	} else {
-		// Single project build
-		if buildWatch {
-			buildCommand = "pnpm"
-			buildArgs = []string{"run", "dev"}
-		} else {
-			buildCommand = "pnpm"
-			buildArgs = []string{"run", "build"}
-		}
-	}
-
-	if buildWatch && isMonorepo {
-		buildArgs = append(buildArgs, "--watch")
-	}
-
-	// Execute build
-	cmdExec := exec.Command(buildCommand, buildArgs...)
-	cmdExec.Stdout = os.Stdout
-	cmdExec.Stderr = os.Stderr
-	cmdExec.Stdin = os.Stdin
-
-	if err := cmdExec.Run(); err != nil {
-		return fmt.Errorf("build failed: %w", err)
+	opts := taskRunOptions{}
+	if buildPackage != "" {
+		cyan.Printf("\n📦 Building package: %s\n", buildPackage)
+		opts.filter = []string{buildPackage}
 	}
-
-	green.Println("\n✅ Build completed successfully!\n")
-	return nil
-}
-
-// buildMonorepoParallel builds all packages in the monorepo in parallel
-func buildMonorepoParallel() error {
-	// Define build order for dependencies
-	packages := [][]string{
-		// Layer 1: No dependencies
-		{"@sldm/utils"},
-		// Layer 2: Depends on utils
-		{"@sldm/testing", "@sldm/core"},
-		// Layer 3: Depends on core
-		{"@sldm/debug", "@sldm/web-ai", "@sldm/ui", "@sldm/ui-chalk", "@sldm/router", "@sldm/store", "@sldm/context"},
-		// Layer 4: Depends on multiple packages
-		{"@sldm/ssr", "@sldm/storage", "@sldm/integrations"},
-		// Layer 5: Final packages
-		{"@sldm/dev-reports"},
+	if !buildParallel {
+		opts.concurrency = 1
 	}
 
-	green := color.New(color.FgGreen)
-	red := color.New(color.FgRed, color.Bold)
-	cyan := color.New(color.FgCyan)
-
-	// Build each layer in parallel
-	for i, layer := range packages {
-		cyan.Printf("🔨 Building layer %d/%d...\n", i+1, len(packages))
-
-		var wg sync.WaitGroup
-		errors := make(chan error, len(layer))
-
-		for _, pkg := range layer {
-			wg.Add(1)
-			go func(packageName string) {
-				defer wg.Done()
-
-				fmt.Printf("  → Building %s...\n", packageName)
-
-				cmd := exec.Command("pnpm", "--filter", packageName, "build")
-				output, err := cmd.CombinedOutput()
-
-				if err != nil {
-					errors <- fmt.Errorf("%s failed: %w\n%s", packageName, err, string(output))
-					return
-				}
-
-				green.Printf("  ✓ %s built successfully\n", packageName)
-			}(pkg)
-		}
-
-		wg.Wait()
-		close(errors)
-
-		// Check for errors
-		for err := range errors {
-			red.Printf("\n❌ %v\n", err)
-			return err
+	task := "build"
+	if buildWatch {
+		// Watchers never exit, so they all start at once
+		opts.parallel = true
+		if isMonorepo {
+			opts.args = []string{"--watch"}
+		} else {
+			task = "dev"
 		}
+	}
 
-		fmt.Println()
+	if err := runTasks(cmd.Context(), []string{task}, opts); err != nil {
+		return fmt.Errorf("build failed: %w", err)
 	}
 
+	green.Print("✅ Build completed successfully!\n\n")
 	return nil
 }
 
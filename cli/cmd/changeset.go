@@ -0,0 +1,385 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/git"
+	"github.com/kluth/solidum-cli/internal/release"
+	"github.com/kluth/solidum-cli/internal/semver"
+	"github.com/kluth/solidum-cli/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	changesetPackages []string
+	changesetBump     string
+	changesetMessage  string
+	changesetSince    string
+	changesetMode     string
+	changesetDryRun   bool
+	changesetNoCommit bool
+)
+
+var changesetCmd = &cobra.Command{
+	Use:   "changeset",
+	Short: "Declare and release changes with changesets",
+	Long: `Changesets are Markdown files under .changesets/ that a change commits
+alongside itself, naming the packages it affects, the bump each needs and a
+summary for their changelogs:
+
+  ---
+  "@sldm/router": minor
+  ---
+
+  Add nested routes
+
+solidum changeset version turns the pending changesets into version bumps
+and changelog entries.`,
+}
+
+var changesetAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Write a changeset for the current change",
+	Long: `Write a changeset, asking which packages the change affects, the bump
+each needs and a summary. Packages changed since --since are offered first.
+Pass --packages, --bump and --message to skip the questions.`,
+	Example: `  solidum changeset add
+  solidum changeset add -p @sldm/router --bump minor -m "Add nested routes"`,
+	Args: cobra.NoArgs,
+	RunE: runChangesetAdd,
+}
+
+var changesetStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check that changed packages have a changeset",
+	Long: `Validate the pending changesets and check that every public package
+changed since --since is named in one. Exits non-zero when a package is
+missing a changeset.`,
+	Args: cobra.NoArgs,
+	RunE: runChangesetStatus,
+}
+
+var changesetVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Turn the pending changesets into version bumps",
+	Long: `Bump every package named in a changeset by the largest bump asked for
+it, release the packages depending on them with a patch, add the changeset
+summaries to the changelogs and delete the changesets. The result is
+committed and tagged like solidum version.`,
+	Args: cobra.NoArgs,
+	RunE: runChangesetVersion,
+}
+
+func init() {
+	changesetAddCmd.Flags().StringSliceVarP(&changesetPackages, "packages", "p", nil, "Packages the change affects (names, prefix* or ./dir)")
+	changesetAddCmd.Flags().StringVar(&changesetBump, "bump", "", "Bump for every package: major, minor or patch")
+	changesetAddCmd.Flags().StringVarP(&changesetMessage, "message", "m", "", "Summary for the changelogs")
+	for _, c := range []*cobra.Command{changesetAddCmd, changesetStatusCmd} {
+		c.Flags().StringVar(&changesetSince, "since", "origin/HEAD", "Git ref to find changed packages against")
+	}
+	changesetVersionCmd.Flags().StringVar(&changesetMode, "mode", "", "Versioning mode: fixed or independent (default from solidum.json, else fixed)")
+	changesetVersionCmd.Flags().BoolVar(&changesetDryRun, "dry-run", false, "Show the new versions and file changes without writing anything")
+	changesetVersionCmd.Flags().BoolVar(&changesetNoCommit, "no-commit", false, "Write the files but don't commit or tag")
+
+	changesetCmd.AddCommand(changesetAddCmd)
+	changesetCmd.AddCommand(changesetStatusCmd)
+	changesetCmd.AddCommand(changesetVersionCmd)
+}
+
+func runChangesetAdd(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	faint := color.New(color.Faint)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	if changesetBump != "" && !isChangesetBump(changesetBump) {
+		return fmt.Errorf("unknown bump %q, expected major, minor or patch", changesetBump)
+	}
+	reader := bufio.NewReader(os.Stdin)
+	set := &release.Changeset{Releases: make(map[string]string)}
+
+	var picked []*workspace.Package
+	if len(changesetPackages) > 0 {
+		for _, pkg := range ws.Filter(changesetPackages...) {
+			if !pkg.Private && pkg.Version != "" {
+				picked = append(picked, pkg)
+			}
+		}
+		if len(picked) == 0 {
+			return fmt.Errorf("no public package matches %s", strings.Join(changesetPackages, ", "))
+		}
+	} else {
+		var candidates []*workspace.Package
+		for _, pkg := range ws.Packages {
+			if !pkg.Private && pkg.Name != "" && pkg.Version != "" {
+				candidates = append(candidates, pkg)
+			}
+		}
+		if len(candidates) == 0 {
+			return fmt.Errorf("no public packages to release")
+		}
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].Name < candidates[j].Name })
+
+		// Changed packages are the default answer; without a usable ref there is none
+		changed := make(map[string]bool)
+		if repo, err := git.Root(ws.Root); err == nil {
+			if pkgs, err := changedPackages(ws, repo, changesetSince); err == nil {
+				for _, pkg := range pkgs {
+					changed[pkg.Name] = true
+				}
+			}
+		}
+		cyan.Print("\n📦 Which packages does this change affect?\n\n")
+		var defaults []string
+		for i, pkg := range candidates {
+			fmt.Printf("  %2d) %-28s", i+1, pkg.Name)
+			if changed[pkg.Name] {
+				faint.Print(" changed")
+				defaults = append(defaults, strconv.Itoa(i+1))
+			}
+			fmt.Println()
+		}
+		fmt.Println()
+		for len(picked) == 0 {
+			answer, err := prompt(reader, "Packages (numbers or names, comma-separated)", strings.Join(defaults, ","))
+			if err != nil {
+				return err
+			}
+			if picked, err = pickPackages(candidates, answer); err != nil {
+				color.New(color.FgRed).Println(err)
+			}
+		}
+	}
+
+	if changesetBump == "" {
+		fmt.Println()
+	}
+	for _, pkg := range picked {
+		bump := changesetBump
+		for bump == "" {
+			if bump, err = prompt(reader, fmt.Sprintf("Bump for %s (major/minor/patch)", pkg.Name), semver.Patch); err != nil {
+				return err
+			}
+			if !isChangesetBump(bump) {
+				color.New(color.FgRed).Printf("Unknown bump %q\n", bump)
+				bump = ""
+			}
+		}
+		set.Releases[pkg.Name] = bump
+	}
+
+	set.Summary = strings.TrimSpace(changesetMessage)
+	if set.Summary == "" {
+		fmt.Println()
+	}
+	for set.Summary == "" {
+		if set.Summary, err = prompt(reader, "Summary", ""); err != nil {
+			return err
+		}
+	}
+
+	rel := release.NewChangesetPath(ws, set.Summary)
+	if err := os.MkdirAll(ws.Path(release.ChangesetDir), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(ws.Path(rel), set.Bytes(), 0644); err != nil {
+		return err
+	}
+	green.Printf("\n✅ Added %s\n", filepath.ToSlash(rel))
+	fmt.Print("Commit it with your change.\n\n")
+	return nil
+}
+
+func isChangesetBump(bump string) bool {
+	return bump == semver.Major || bump == semver.Minor || bump == semver.Patch
+}
+
+// prompt asks a question on stdout and returns the trimmed answer, or def
+// for an empty one. It fails once stdin is closed, instead of asking again.
+func prompt(reader *bufio.Reader, question, def string) (string, error) {
+	if def != "" {
+		fmt.Printf("%s [%s]: ", question, def)
+	} else {
+		fmt.Printf("%s: ", question)
+	}
+	answer, err := reader.ReadString('\n')
+	answer = strings.TrimSpace(answer)
+	if answer == "" && err != nil {
+		fmt.Println()
+		return "", fmt.Errorf("no answer to %q", question)
+	}
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// pickPackages resolves a comma-separated list of numbers and names
+func pickPackages(candidates []*workspace.Package, answer string) ([]*workspace.Package, error) {
+	var picked []*workspace.Package
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(answer, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		var pkg *workspace.Package
+		if n, err := strconv.Atoi(field); err == nil && n >= 1 && n <= len(candidates) {
+			pkg = candidates[n-1]
+		}
+		for _, c := range candidates {
+			if c.Name == field {
+				pkg = c
+			}
+		}
+		if pkg == nil {
+			return nil, fmt.Errorf("unknown package %q", field)
+		}
+		if !seen[pkg.Name] {
+			seen[pkg.Name] = true
+			picked = append(picked, pkg)
+		}
+	}
+	if len(picked) == 0 {
+		return nil, fmt.Errorf("pick at least one package")
+	}
+	return picked, nil
+}
+
+// changedPackages returns the public packages with files changed since ref,
+// ignoring changesets themselves
+func changedPackages(ws *workspace.Workspace, repo, ref string) ([]*workspace.Package, error) {
+	paths, err := git.Changed(repo, ref)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var pkgs []*workspace.Package
+	for _, path := range paths {
+		rel := ws.Rel(path)
+		if filepath.IsAbs(rel) || strings.HasPrefix(rel, release.ChangesetDir+"/") {
+			continue
+		}
+		pkg := ws.Owner(rel)
+		if pkg == nil || pkg.Private || pkg.Version == "" || seen[pkg.Name] {
+			continue
+		}
+		// The root package of a monorepo only owns what no package does
+		if ws.IsMonorepo() && pkg == ws.RootPackage {
+			continue
+		}
+		seen[pkg.Name] = true
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Name < pkgs[j].Name })
+	return pkgs, nil
+}
+
+func runChangesetStatus(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+	faint := color.New(color.Faint)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	repo, err := git.Root(ws.Root)
+	if err != nil {
+		return err
+	}
+	sets, err := release.ReadChangesets(ws)
+	if err != nil {
+		return err
+	}
+	bumps := make(map[string][]string)
+	for _, set := range sets {
+		if err := set.Validate(ws); err != nil {
+			return err
+		}
+		for name, bump := range set.Releases {
+			bumps[name] = append(bumps[name], bump)
+		}
+	}
+	changed, err := changedPackages(ws, repo, changesetSince)
+	if err != nil {
+		return err
+	}
+
+	cyan.Printf("\n🦋 %d changeset(s), %d package(s) changed since %s\n\n", len(sets), len(changed), changesetSince)
+	var missing []string
+	for _, pkg := range changed {
+		if len(bumps[pkg.Name]) == 0 {
+			missing = append(missing, pkg.Name)
+			fmt.Printf("  ❌ %s", pkg.Name)
+			faint.Print(" has no changeset\n")
+		}
+	}
+	for _, name := range sortedKeys(bumps) {
+		fmt.Printf("  ✓ %-28s %s", name, release.Largest(bumps[name]...))
+		faint.Printf(" (%d changeset(s))\n", len(bumps[name]))
+	}
+
+	if len(missing) > 0 {
+		red.Printf("\n❌ %d changed package(s) without a changeset\n", len(missing))
+		fmt.Print("Run `solidum changeset add` to declare the release they need.\n\n")
+		return &ExitError{Code: 1, Err: fmt.Errorf("missing changesets for %s", strings.Join(missing, ", "))}
+	}
+	green.Print("\n✅ Every changed package has a changeset\n\n")
+	return nil
+}
+
+func runChangesetVersion(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return err
+	}
+	repo, err := git.Root(ws.Root)
+	if err != nil {
+		return err
+	}
+	sets, err := release.ReadChangesets(ws)
+	if err != nil {
+		return err
+	}
+	if len(sets) == 0 {
+		yellow.Print("\nNo changesets to release. Add one with `solidum changeset add`.\n\n")
+		return nil
+	}
+
+	mode := releaseMode(changesetMode, cfg)
+	if changesetDryRun {
+		yellow.Print("\n🔍 Running in dry-run mode - nothing will be written\n")
+	}
+	cyan.Printf("\n🦋 Versioning %d changeset(s) (%s)...\n\n", len(sets), mode)
+
+	plan, err := release.NewChangesetPlan(ws, repo, sets, release.Options{Mode: mode, Date: time.Now()})
+	if err != nil {
+		return err
+	}
+	remove := make([]string, len(sets))
+	for i, set := range sets {
+		remove[i] = set.Path
+	}
+	return finishRelease(ws, repo, plan, remove, changesetDryRun, changesetNoCommit)
+}
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/junit"
+	"github.com/kluth/solidum-cli/internal/tasks"
+	"github.com/kluth/solidum-cli/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ciJUnit   string
+	ciSummary string
+	ciSkip    []string
+)
+
+var ciCmd = &cobra.Command{
+	Use:   "ci",
+	Short: "Run the full CI pipeline",
+	Long: `Run the CI pipeline natively: build first, then typecheck, lint, format
+check and tests in parallel.
+
+Writes a JUnit XML summary and a Markdown step summary (also appended to
+$GITHUB_STEP_SUMMARY when set). The exit code identifies the first stage
+that failed:
+
+  10  build
+  11  typecheck
+  12  lint
+  13  format
+  14  test`,
+	RunE: runCI,
+}
+
+func init() {
+	ciCmd.Flags().StringVar(&ciJUnit, "junit", ".solidum/ci/junit.xml", "Path of the JUnit XML summary")
+	ciCmd.Flags().StringVar(&ciSummary, "summary", ".solidum/ci/summary.md", "Path of the Markdown step summary")
+	ciCmd.Flags().StringSliceVar(&ciSkip, "skip", nil, "Stages to skip (e.g., lint,format)")
+}
+
+// ciStage is one step of the pipeline, backed by a task
+type ciStage struct {
+	name        string
+	task        string
+	args        []string
+	concurrency int
+	exitCode    int
+
+	summary *tasks.Summary
+	skipped bool
+	err     error
+}
+
+func (s *ciStage) failed() bool {
+	return s.err != nil
+}
+
+func ciStages() (*ciStage, []*ciStage) {
+	build := &ciStage{name: "build", task: "build", exitCode: 10}
+	parallel := []*ciStage{
+		{name: "typecheck", task: "typecheck", exitCode: 11},
+		{name: "lint", task: "lint", exitCode: 12},
+		{name: "format", task: "format:check", exitCode: 13},
+		// Package test suites share ports and fixtures, so they run one at a time
+		{name: "test", task: "test", args: []string{"--reporter=verbose"}, concurrency: 1, exitCode: 14},
+	}
+	return build, parallel
+}
+
+func runCI(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return err
+	}
+
+	skip := make(map[string]bool)
+	for _, name := range ciSkip {
+		skip[name] = true
+	}
+
+	start := time.Now()
+	build, parallel := ciStages()
+	stages := append([]*ciStage{build}, parallel...)
+	for _, s := range stages {
+		s.skipped = skip[s.name]
+	}
+
+	cyan.Println("\n🤖 Running CI pipeline...")
+
+	if !build.skipped {
+		cyan.Print("\n🔨 Stage 1/2: build\n\n")
+		runCIStage(cmd.Context(), ws, cfg, build, true)
+	}
+
+	if build.failed() {
+		for _, s := range parallel {
+			s.skipped = true
+		}
+	} else {
+		var names []string
+		for _, s := range parallel {
+			if !s.skipped {
+				names = append(names, s.name)
+			}
+		}
+		cyan.Printf("\n⚡ Stage 2/2: %s\n\n", strings.Join(names, ", "))
+
+		var wg sync.WaitGroup
+		var printMu sync.Mutex
+		for _, s := range parallel {
+			if s.skipped {
+				continue
+			}
+			wg.Add(1)
+			go func(s *ciStage) {
+				defer wg.Done()
+				runCIStage(cmd.Context(), ws, cfg, s, false)
+
+				printMu.Lock()
+				defer printMu.Unlock()
+				printCIStage(s)
+			}(s)
+		}
+		wg.Wait()
+	}
+
+	elapsed := time.Since(start)
+
+	if err := junit.Write(ciJUnit, ciJUnitReport(stages)); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	summary := ciMarkdown(stages, elapsed)
+	if err := writeFile(ciSummary, summary); err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" {
+		if f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err == nil {
+			f.WriteString(summary)
+			f.Close()
+		}
+	}
+
+	fmt.Println()
+	for _, s := range stages {
+		switch {
+		case s.skipped:
+			fmt.Printf("  - %s  skipped\n", s.name)
+		case s.failed():
+			red.Printf("  ✗ %s  %v\n", s.name, s.err)
+		default:
+			green.Printf("  ✓ %s\n", s.name)
+		}
+	}
+	fmt.Printf("\nJUnit: %s\nSummary: %s\n\n", ciJUnit, ciSummary)
+
+	for _, s := range stages {
+		if s.failed() {
+			red.Printf("❌ CI failed at stage: %s (%s)\n\n", s.name, elapsed.Round(10*time.Millisecond))
+			return &ExitError{Code: s.exitCode, Err: fmt.Errorf("%s stage failed", s.name)}
+		}
+	}
+
+	green.Printf("✅ All CI checks passed! (%s)\n\n", elapsed.Round(10*time.Millisecond))
+	return nil
+}
+
+func runCIStage(ctx context.Context, ws *workspace.Workspace, cfg *config.Config, s *ciStage, stream bool) {
+	plan, err := tasks.NewPlan(ws, cfg, []string{s.task}, ws.Packages)
+	if err != nil {
+		s.err = err
+		return
+	}
+
+	concurrency := s.concurrency
+	if concurrency == 0 {
+		concurrency = cfg.Concurrency
+	}
+
+	s.summary = tasks.Run(ctx, ws, plan, tasks.Options{
+		Concurrency: concurrency,
+		Args:        s.args,
+		Stream:      stream,
+	})
+
+	if failed := s.summary.Failed(); len(failed) > 0 {
+		ids := make([]string, len(failed))
+		for i, r := range failed {
+			ids[i] = r.Node.Package.Name
+		}
+		s.err = fmt.Errorf("%s failed", strings.Join(ids, ", "))
+		return
+	}
+	// An interrupted run must not pass: cancelled or blocked nodes never ran
+	if err := ctx.Err(); err != nil {
+		s.err = fmt.Errorf("interrupted: %w", err)
+		return
+	}
+	if blocked := s.summary.Count(tasks.Blocked); blocked > 0 {
+		s.err = fmt.Errorf("%d task(s) did not run", blocked)
+	}
+}
+
+func printCIStage(s *ciStage) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed, color.Bold)
+	faint := color.New(color.Faint)
+
+	if !s.failed() {
+		green.Printf("  ✓ %s", s.name)
+		if s.summary != nil {
+			faint.Printf("  %s", s.summary.Duration.Round(10*time.Millisecond))
+		}
+		fmt.Println()
+		return
+	}
+
+	red.Printf("  ✗ %s  %v\n", s.name, s.err)
+	if s.summary == nil {
+		return
+	}
+	for _, r := range s.summary.Failed() {
+		faint.Printf("\n── %s ──\n", r.Node.ID())
+		fmt.Println(strings.TrimRight(r.Output, "\n"))
+	}
+	fmt.Println()
+}
+
+func ciJUnitReport(stages []*ciStage) *junit.Suites {
+	report := &junit.Suites{Name: "solidum ci"}
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+
+	for _, s := range stages {
+		suite := &junit.Suite{Name: s.name, Timestamp: timestamp}
+
+		switch {
+		case s.summary == nil && s.err != nil:
+			suite.Add(&junit.Case{Name: s.name, Classname: s.name, Error: &junit.Failure{Message: s.err.Error()}})
+		case s.summary == nil:
+			suite.Add(&junit.Case{Name: s.name, Classname: s.name, Skipped: &junit.Skipped{Message: "stage skipped"}})
+		default:
+			for _, r := range s.summary.Results {
+				if !r.Node.Requested {
+					continue
+				}
+				c := &junit.Case{
+					Name:      r.Node.Package.Name,
+					Classname: s.name,
+					Time:      r.Duration.Seconds(),
+				}
+				switch r.Status {
+				case tasks.Failed:
+					c.Failure = &junit.Failure{Message: r.Err.Error(), Type: s.task, Body: r.Output}
+				case tasks.Blocked:
+					c.Skipped = &junit.Skipped{Message: r.Err.Error()}
+				case tasks.Skipped:
+					c.Skipped = &junit.Skipped{Message: "no " + s.task + " script"}
+				}
+				suite.Add(c)
+			}
+		}
+
+		report.Add(suite)
+	}
+
+	return report
+}
+
+func ciMarkdown(stages []*ciStage, elapsed time.Duration) string {
+	var b strings.Builder
+	b.WriteString("## Solidum CI\n\n")
+	b.WriteString("| Stage | Status | Duration | Details |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+
+	for _, s := range stages {
+		status, duration, details := "✅ passed", "", ""
+		if s.summary != nil {
+			duration = s.summary.Duration.Round(10 * time.Millisecond).String()
+			ran, cached := 0, 0
+			for _, r := range s.summary.Results {
+				if !r.Node.Requested {
+					continue
+				}
+				switch r.Status {
+				case tasks.Success, tasks.Failed:
+					ran++
+				case tasks.Cached:
+					cached++
+				}
+			}
+			details = fmt.Sprintf("%d run, %d cached", ran, cached)
+		}
+		switch {
+		case s.skipped:
+			status = "⏭️ skipped"
+		case s.failed():
+			status = "❌ failed"
+			details = s.err.Error()
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", s.name, status, duration, details)
+	}
+
+	fmt.Fprintf(&b, "\nTotal time: %s\n", elapsed.Round(10*time.Millisecond))
+
+	for _, s := range stages {
+		if s.summary == nil {
+			continue
+		}
+		for _, r := range s.summary.Failed() {
+			fmt.Fprintf(&b, "\n<details><summary>%s</summary>\n\n```\n%s\n```\n\n</details>\n", r.Node.ID(), tail(r.Output, 50))
+		}
+	}
+
+	return b.String()
+}
+
+// tail returns the last n lines of s
+func tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeFile writes content to path, creating parent directories
+func writeFile(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
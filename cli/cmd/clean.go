@@ -1,11 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 
 	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/proc"
 	"github.com/spf13/cobra"
 )
 
@@ -57,7 +59,7 @@ func runClean(cmd *cobra.Command, args []string) error {
 	if cleanDist {
 		yellow.Println("→ Removing dist folders...")
 		if isMonorepo {
-			if err := runCleanCommand("pnpm", "-r", "exec", "rm", "-rf", "dist"); err != nil {
+			if err := runCleanCommand(cmd.Context(), "pnpm", "-r", "exec", "rm", "-rf", "dist"); err != nil {
 				fmt.Printf("  Warning: %v\n", err)
 			}
 		} else {
@@ -70,7 +72,7 @@ func runClean(cmd *cobra.Command, args []string) error {
 	if cleanNodeModules {
 		yellow.Println("→ Removing node_modules...")
 		if isMonorepo {
-			if err := runCleanCommand("pnpm", "-r", "exec", "rm", "-rf", "node_modules"); err != nil {
+			if err := runCleanCommand(cmd.Context(), "pnpm", "-r", "exec", "rm", "-rf", "node_modules"); err != nil {
 				fmt.Printf("  Warning: %v\n", err)
 			}
 		}
@@ -82,7 +84,7 @@ func runClean(cmd *cobra.Command, args []string) error {
 	if cleanCache {
 		yellow.Println("→ Cleaning package manager cache...")
 		if commandExists("pnpm") {
-			if err := runCleanCommand("pnpm", "store", "prune"); err != nil {
+			if err := runCleanCommand(cmd.Context(), "pnpm", "store", "prune"); err != nil {
 				fmt.Printf("  Warning: failed to clean pnpm cache: %v\n", err)
 			} else {
 				green.Println("  ✓ pnpm cache cleaned")
@@ -99,9 +101,9 @@ func runClean(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func runCleanCommand(name string, args ...string) error {
+func runCleanCommand(ctx context.Context, name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return proc.Run(ctx, name, cmd)
 }
@@ -1,18 +1,27 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"sync"
 
 	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/devserver"
+	"github.com/kluth/solidum-cli/internal/ports"
+	"github.com/kluth/solidum-cli/internal/proc"
+	"github.com/kluth/solidum-cli/internal/tasks"
+	"github.com/kluth/solidum-cli/internal/workspace"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
 	devPort    int
 	devPackage string
 	devAll     bool
+	devNoTUI   bool
 )
 
 var devCmd = &cobra.Command{
@@ -20,7 +29,15 @@ var devCmd = &cobra.Command{
 	Short: "Start development server",
 	Long: `Start the development server with hot module reloading.
 
-In a monorepo, can start dev servers for all packages or specific ones.`,
+In a monorepo, can start dev servers for all packages or specific ones.
+With --all, every package's dev server runs under a dashboard with a tab
+per package showing its status, URL and logs:
+
+  ←/→, 1-9   switch package
+  r / R      restart the package / all packages
+  o          open the package URL in the browser
+  /          filter log lines (esc clears)
+  q          stop all servers and quit`,
 	RunE: runDev,
 }
 
@@ -28,6 +45,7 @@ func init() {
 	devCmd.Flags().IntVarP(&devPort, "port", "p", 0, "Port to run dev server on")
 	devCmd.Flags().StringVarP(&devPackage, "package", "f", "", "Run dev server for specific package")
 	devCmd.Flags().BoolVarP(&devAll, "all", "a", false, "Run dev servers for all packages in parallel")
+	devCmd.Flags().BoolVar(&devNoTUI, "no-tui", false, "With --all, print prefixed output instead of the dashboard")
 }
 
 func runDev(cmd *cobra.Command, args []string) error {
@@ -40,6 +58,7 @@ func runDev(cmd *cobra.Command, args []string) error {
 
 	var devCommand string
 	var devArgs []string
+	var devEnv []string
 
 	if isMonorepo {
 		if devPackage != "" {
@@ -47,11 +66,16 @@ func runDev(cmd *cobra.Command, args []string) error {
 			cyan.Printf("\n📦 Starting dev server for: %s\n\n", devPackage)
 			devCommand = "pnpm"
 			devArgs = []string{"--filter", devPackage, "dev"}
+
+			// Reuse the package's port from earlier runs unless one is given
+			if devPort == 0 {
+				if pkg, port := assignPackagePort(devPackage); port > 0 {
+					devEnv = append(devEnv, fmt.Sprintf("PORT=%d", port), fmt.Sprintf("SOLIDUM_PORT=%d", port))
+					devArgs = append(devArgs, devserver.PortArgs(pkg, port)...)
+				}
+			}
 		} else if devAll {
-			// Dev mode for all packages in parallel
-			cyan.Println("\n⚡ Starting dev servers for all packages...\n")
-			devCommand = "pnpm"
-			devArgs = []string{"-r", "--parallel", "dev"}
+			return runDevAll(cmd.Context())
 		} else {
 			// Use the monorepo dev script
 			devCommand = "pnpm"
@@ -75,10 +99,135 @@ func runDev(cmd *cobra.Command, args []string) error {
 	cmdExec.Stdout = os.Stdout
 	cmdExec.Stderr = os.Stderr
 	cmdExec.Stdin = os.Stdin
+	cmdExec.Env = append(os.Environ(), devEnv...)
 
-	if err := cmdExec.Run(); err != nil {
+	if err := proc.Run(cmd.Context(), "dev", cmdExec); err != nil {
 		return fmt.Errorf("dev server failed: %w", err)
 	}
 
 	return nil
 }
+
+// runDevAll runs the dev script of every package, under the dashboard when
+// stdout is a terminal
+func runDevAll(ctx context.Context) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+	yellow := color.New(color.FgYellow)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+
+	m := devserver.NewManager(ws, ws.Packages)
+	if len(m.Servers) == 0 {
+		return fmt.Errorf("no package has a dev script")
+	}
+
+	names := make([]string, len(m.Servers))
+	for i, s := range m.Servers {
+		names[i] = s.Name()
+	}
+	assignments, err := ports.Assign(ws.Root, names, devPort)
+	if err != nil {
+		return err
+	}
+	for i, a := range assignments {
+		m.Servers[i].Port = a.Port
+		if a.Previous > 0 {
+			yellow.Printf("⚠️  Port %d of %s is in use, using %d\n", a.Previous, a.Package, a.Port)
+		}
+	}
+
+	if devNoTUI || !term.IsTerminal(int(os.Stdout.Fd())) {
+		cyan.Printf("\n⚡ Starting dev servers for %d packages...\n\n", len(m.Servers))
+
+		var mu sync.Mutex
+		printed := false
+		m.Output = func(s *devserver.Server, line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Printf("%s │ %s\n", tasks.LabelColor(s.Name()).Sprint(s.Name()), line)
+		}
+		m.OnReady = func(*devserver.Server) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, s := range m.Servers {
+				if s.Snapshot().Status == devserver.Starting {
+					return
+				}
+			}
+			if !printed {
+				printed = true
+				printDevServers(m.Servers)
+			}
+		}
+		m.Start(ctx)
+		m.Wait()
+	} else if err := devserver.RunTUI(ctx, m); err != nil {
+		return fmt.Errorf("dashboard failed: %w", err)
+	}
+
+	var failed int
+	fmt.Println()
+	for _, s := range m.Servers {
+		snap := s.Snapshot()
+		if snap.Status == devserver.Error {
+			failed++
+			red.Printf("  ✗ %s  %v\n", snap.Name, snap.Err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d dev server(s) failed", failed)
+	}
+	return nil
+}
+
+// printDevServers prints the package → URL table
+func printDevServers(servers []*devserver.Server) {
+	green := color.New(color.FgGreen, color.Bold)
+	faint := color.New(color.Faint)
+
+	width := 0
+	for _, s := range servers {
+		if len(s.Name()) > width {
+			width = len(s.Name())
+		}
+	}
+
+	green.Print("\n✨ Dev servers ready:\n\n")
+	for _, s := range servers {
+		snap := s.Snapshot()
+		fmt.Printf("  %-*s  ", width, snap.Name)
+		switch {
+		case snap.Status == devserver.Error:
+			color.New(color.FgRed).Println(snap.Err)
+		case snap.URL != "":
+			fmt.Println(snap.URL)
+		case snap.Status == devserver.Ready:
+			faint.Println("watching")
+		default:
+			faint.Println(snap.Status)
+		}
+	}
+	fmt.Println()
+}
+
+// assignPackagePort returns the package matching the filter and its stable
+// port, or a port of 0 when the filter does not name exactly one package
+func assignPackagePort(filter string) (*workspace.Package, int) {
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return nil, 0
+	}
+	pkgs := ws.Filter(filter)
+	if len(pkgs) != 1 {
+		return nil, 0
+	}
+	assignments, err := ports.Assign(ws.Root, []string{pkgs[0].Name}, 0)
+	if err != nil {
+		return nil, 0
+	}
+	return pkgs[0], assignments[0].Port
+}
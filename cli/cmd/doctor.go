@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/doctor"
+	"github.com/kluth/solidum-cli/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorJSON bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check your environment and project health",
+	Long: `Check tool versions and workspace consistency.
+
+Verifies Node, pnpm and git against the project's requirements, that the
+pnpm-workspace.yaml globs resolve, that @sldm/* versions line up, that each
+package has the scripts the CLI expects and that dist is not stale.`,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Output the report as JSON")
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+
+	report := doctor.Run(ws)
+
+	if doctorJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(report)
+	}
+
+	if failed := report.Count(doctor.Fail); failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func printDoctorReport(report *doctor.Report) {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen)
+	yellow := color.New(color.FgYellow)
+	red := color.New(color.FgRed, color.Bold)
+	faint := color.New(color.Faint)
+
+	cyan.Println("\n🩺 Checking project health...")
+
+	category := ""
+	for _, c := range report.Checks {
+		if c.Category != category {
+			category = c.Category
+			cyan.Printf("\n%s\n", category)
+		}
+
+		switch c.Status {
+		case doctor.Pass:
+			green.Printf("  ✓ %s", c.Name)
+		case doctor.Warn:
+			yellow.Printf("  ⚠ %s", c.Name)
+		case doctor.Fail:
+			red.Printf("  ✗ %s", c.Name)
+		}
+		fmt.Printf("  %s\n", c.Message)
+		if c.Fix != "" {
+			faint.Printf("      → %s\n", c.Fix)
+		}
+	}
+
+	fmt.Printf("\n%d passed, %d warning(s), %d failed\n\n",
+		report.Count(doctor.Pass), report.Count(doctor.Warn), report.Count(doctor.Fail))
+}
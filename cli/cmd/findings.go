@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/findings"
+	"github.com/kluth/solidum-cli/internal/junit"
+	"github.com/kluth/solidum-cli/internal/sarif"
+	"github.com/kluth/solidum-cli/internal/tasks"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// checkReportFormats validates the formats given to --report
+func checkReportFormats(formats []string) error {
+	for _, f := range formats {
+		known := false
+		for _, k := range findings.Formats {
+			known = known || f == k
+		}
+		if !known {
+			return fmt.Errorf("unknown report format %q, expected %s", f, strings.Join(findings.Formats, ", "))
+		}
+	}
+	return nil
+}
+
+// writeFindings writes r in each of the formats: JUnit XML and SARIF files
+// named after the command, annotations on stdout
+func writeFindings(ws *workspace.Workspace, name string, r *findings.Report, formats []string) error {
+	faint := color.New(color.Faint)
+
+	for _, format := range formats {
+		switch format {
+		case "junit":
+			path := ws.Path(findings.Dir, name+".junit.xml")
+			if err := junit.Write(path, r.Suites); err != nil {
+				return err
+			}
+			faint.Printf("📄 JUnit report: %s\n", ws.Rel(path))
+		case "sarif":
+			path := ws.Path(findings.Dir, name+".sarif")
+			if err := sarif.Write(path, r.Sarif(rootCmd.Version)); err != nil {
+				return err
+			}
+			faint.Printf("📄 SARIF report: %s\n", ws.Rel(path))
+		case "annotations":
+			if err := r.WriteAnnotations(os.Stdout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addTaskFailures records the failed tasks whose output the report could not
+// parse, by node ID
+func addTaskFailures(r *findings.Report, summary *tasks.Summary, parsed map[string]bool) {
+	for _, res := range summary.Failed() {
+		if !parsed[res.Node.ID()] {
+			r.AddFailure(res.Node.Package.Name, res.Node.ID()+" failed: "+res.Err.Error(), res.Output)
+		}
+	}
+}
+
+// printFindings lists the findings with their location, then the totals
+func printFindings(r *findings.Report) {
+	red := color.New(color.FgRed)
+	yellow := color.New(color.FgYellow)
+	faint := color.New(color.Faint)
+
+	if len(r.Findings) == 0 {
+		return
+	}
+	fmt.Println()
+	for _, f := range r.Findings {
+		mark, c := "✗", red
+		if f.Level != findings.Error {
+			mark, c = "⚠", yellow
+		}
+		where := f.File
+		if where == "" {
+			where = f.Package
+		} else if f.Line > 0 {
+			where = fmt.Sprintf("%s:%d:%d", f.File, f.Line, f.Col)
+		}
+		c.Printf("  %s %s", mark, where)
+		msg, _, _ := strings.Cut(f.Message, "\n")
+		fmt.Printf("  %s", msg)
+		faint.Printf("  %s\n", f.Rule)
+	}
+	errors, warnings := r.Counts()
+	fmt.Printf("\n%d error(s), %d warning(s)\n\n", errors, warnings)
+}
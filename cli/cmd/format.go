@@ -1,62 +1,226 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 
 	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/git"
+	"github.com/kluth/solidum-cli/internal/proc"
+	"github.com/kluth/solidum-cli/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 var (
-	formatCheck bool
+	formatCheck   bool
+	formatStaged  bool
+	formatChanged string
 )
 
+// maxArgsLength keeps each Prettier invocation within the command line
+// limits of every platform, the smallest being cmd.exe's 8191 characters
+const maxArgsLength = 7000
+
 var formatCmd = &cobra.Command{
 	Use:   "format",
 	Short: "Format code with Prettier",
 	Long: `Format code using Prettier.
 
-By default, formats all files. Use --check to only verify formatting.`,
+By default, formats all files. Use --check to only verify formatting.
+
+--staged formats the files staged for commit and stages them again;
+--changed formats the files that differ from a git ref (HEAD by default),
+including untracked ones. Only those files are passed to Prettier.`,
+	Example: `  solidum format --staged
+  solidum format --check --changed=origin/main`,
 	RunE: runFormat,
 }
 
 func init() {
 	formatCmd.Flags().BoolVarP(&formatCheck, "check", "c", false, "Check formatting without modifying files")
+	formatCmd.Flags().BoolVar(&formatStaged, "staged", false, "Only format files staged for commit")
+	formatCmd.Flags().StringVar(&formatChanged, "changed", "", "Only format files changed since a git ref (default HEAD)")
+	formatCmd.Flags().Lookup("changed").NoOptDefVal = "HEAD"
 }
 
 func runFormat(cmd *cobra.Command, args []string) error {
 	cyan := color.New(color.FgCyan, color.Bold)
 	green := color.New(color.FgGreen, color.Bold)
 
+	if formatStaged && formatChanged != "" {
+		return fmt.Errorf("--staged and --changed can't be combined")
+	}
+	if formatStaged || formatChanged != "" {
+		return runFormatFiles(cmd.Context())
+	}
+
+	task := "format"
 	if formatCheck {
 		cyan.Println("\n🔍 Checking code formatting...")
+		task = "format:check"
 	} else {
 		cyan.Println("\n✨ Formatting code...")
 	}
 
-	var formatArgs []string
+	if err := runTasks(cmd.Context(), []string{task}, taskRunOptions{}); err != nil {
+		return fmt.Errorf("formatting failed: %w", err)
+	}
+
 	if formatCheck {
-		formatArgs = []string{"run", "format:check"}
+		green.Print("✅ All files are properly formatted!\n\n")
 	} else {
-		formatArgs = []string{"run", "format"}
+		green.Print("✅ Code formatted successfully!\n\n")
 	}
+	return nil
+}
 
-	// Execute format
-	cmdExec := exec.Command("pnpm", formatArgs...)
-	cmdExec.Stdout = os.Stdout
-	cmdExec.Stderr = os.Stderr
-	cmdExec.Stdin = os.Stdin
+// runFormatFiles runs Prettier on the files git reports as staged or
+// changed, in batches
+func runFormatFiles(ctx context.Context) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	faint := color.New(color.Faint)
 
-	if err := cmdExec.Run(); err != nil {
-		return fmt.Errorf("formatting failed: %w", err)
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	root, err := git.Root(ws.Root)
+	if err != nil {
+		return err
+	}
+
+	var paths, unstaged []string
+	describe := func(n int) string { return fmt.Sprintf("%d staged file(s)", n) }
+	if formatStaged {
+		paths, err = git.Staged(root)
+		if err == nil {
+			unstaged, err = git.Unstaged(root)
+		}
+	} else {
+		describe = func(n int) string { return fmt.Sprintf("%d file(s) changed since %s", n, formatChanged) }
+		paths, err = git.Changed(root, formatChanged)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Prettier runs at the workspace root and sees paths relative to it
+	var files []string
+	for _, path := range paths {
+		if rel := ws.Rel(path); rel != path {
+			files = append(files, rel)
+		}
+	}
+	if len(files) == 0 {
+		green.Printf("\n✅ No files to format (%s)\n\n", describe(0))
+		return nil
+	}
+
+	// Prettier only sees the working tree, so for a file with unstaged
+	// changes it would neither check nor fix what is being committed
+	if partial := partiallyStaged(ws, files, unstaged); len(partial) > 0 {
+		red := color.New(color.FgRed)
+		red.Print("\n❌ Some staged files also have unstaged changes:\n")
+		for _, file := range partial {
+			red.Printf("  ✗ %s\n", file)
+		}
+		fmt.Println()
+		return fmt.Errorf("stage or stash the rest of the changes to %d file(s) before formatting them", len(partial))
 	}
 
+	mode := "--write"
 	if formatCheck {
-		green.Println("\n✅ All files are properly formatted!\n")
+		mode = "--check"
+		cyan.Printf("\n🔍 Checking formatting of %s...\n\n", describe(len(files)))
 	} else {
-		green.Println("\n✅ Code formatted successfully!\n")
+		cyan.Printf("\n✨ Formatting %s...\n\n", describe(len(files)))
+	}
+
+	batches := batchArgs(files, maxArgsLength)
+	var failed bool
+	for i, batch := range batches {
+		if len(batches) > 1 {
+			faint.Printf("$ prettier %s (batch %d/%d, %d file(s))\n", mode, i+1, len(batches), len(batch))
+		}
+		c := exec.Command("pnpm", append([]string{"exec", "prettier", mode, "--ignore-unknown", "--"}, batch...)...)
+		c.Dir = ws.Root
+		c.Stdout, c.Stderr = os.Stdout, os.Stderr
+		if err := proc.Run(ctx, "prettier", c); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("interrupted: %w", ctx.Err())
+			}
+			failed = true
+		}
+	}
+
+	if failed {
+		if formatCheck {
+			return fmt.Errorf("formatting check failed; run solidum format without --check to fix")
+		}
+		return fmt.Errorf("formatting failed")
+	}
+
+	if formatStaged && !formatCheck {
+		if err := restage(root, ws, files); err != nil {
+			return err
+		}
+	}
+
+	if formatCheck {
+		green.Print("\n✅ All files are properly formatted!\n\n")
+	} else {
+		green.Print("\n✅ Code formatted successfully!\n\n")
 	}
 	return nil
 }
+
+// partiallyStaged returns the files that also have unstaged changes
+func partiallyStaged(ws *workspace.Workspace, files, unstaged []string) []string {
+	changed := make(map[string]bool)
+	for _, path := range unstaged {
+		changed[ws.Rel(path)] = true
+	}
+	var partial []string
+	for _, file := range files {
+		if changed[file] {
+			partial = append(partial, file)
+		}
+	}
+	return partial
+}
+
+// restage adds the formatted files back to the index
+func restage(root string, ws *workspace.Workspace, files []string) error {
+	add := make([]string, len(files))
+	for i, file := range files {
+		add[i] = ws.Path(file)
+	}
+	for _, batch := range batchArgs(add, maxArgsLength) {
+		if err := git.Add(root, batch...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// batchArgs splits args into groups whose joined length stays under limit
+func batchArgs(args []string, limit int) [][]string {
+	var batches [][]string
+	var batch []string
+	length := 0
+	for _, arg := range args {
+		if len(batch) > 0 && length+len(arg)+1 > limit {
+			batches = append(batches, batch)
+			batch, length = nil, 0
+		}
+		batch = append(batch, arg)
+		length += len(arg) + 1
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var hooksForce bool
+
+// hookMarker identifies hooks written by solidum hooks install
+const hookMarker = "# Installed by `solidum hooks install`"
+
+// hooks are the scripts installed, by git hook name
+var hooks = map[string]string{
+	"pre-commit": `# Format and lint what is staged, then typecheck and test everything
+set -e
+{{solidum}} format --staged
+{{solidum}} lint --staged
+{{solidum}} typecheck
+exec {{solidum}} test
+`,
+	"pre-push": `# Check what is about to be pushed against the upstream branch
+upstream=$(git rev-parse --abbrev-ref '@{upstream}' 2>/dev/null) || upstream=origin/HEAD
+exec {{solidum}} format --check --changed="$upstream"
+`,
+}
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage git hooks",
+	Long: `Install git hooks that run solidum on commit and push, in place of
+husky and lint-staged.`,
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install pre-commit and pre-push hooks",
+	Long: `Install git hooks that check what is committed and pushed:
+
+  pre-commit  solidum format --staged, solidum lint --staged,
+              solidum typecheck and solidum test, stopping at the first
+              that fails
+  pre-push    solidum format --check --changed=<upstream>
+
+A core.hooksPath set by husky is removed so git runs these hooks. Existing
+hooks that solidum didn't write are kept unless --force is given.`,
+	RunE: runHooksInstall,
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the hooks installed by solidum",
+	RunE:  runHooksUninstall,
+}
+
+func init() {
+	hooksInstallCmd.Flags().BoolVarP(&hooksForce, "force", "f", false, "Overwrite existing hooks")
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+
+	root, err := git.Root(".")
+	if err != nil {
+		return err
+	}
+	cyan.Print("\n🪝 Installing git hooks...\n\n")
+
+	dir, err := git.HooksDir(root)
+	if err != nil {
+		return err
+	}
+	// Refuse before changing anything
+	for _, name := range sortedKeys(hooks) {
+		if existing, err := os.ReadFile(filepath.Join(dir, name)); err == nil && !hooksForce && !strings.Contains(string(existing), hookMarker) {
+			return fmt.Errorf("%s already has a %s hook; use --force to replace it", dir, name)
+		}
+	}
+
+	if path := git.Config(root, "core.hooksPath"); path != "" {
+		if err := git.UnsetConfig(root, "core.hooksPath"); err != nil {
+			return fmt.Errorf("core.hooksPath is set to %s: %w", path, err)
+		}
+		yellow.Printf("  Removed core.hooksPath=%s, so git runs the hooks below\n", path)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	bin := solidumCommand()
+	for _, name := range sortedKeys(hooks) {
+		script := "#!/bin/sh\n" + hookMarker + "\n" + strings.ReplaceAll(hooks[name], "{{solidum}}", bin)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+			return err
+		}
+		fmt.Printf("  ✓ %s\n", name)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, ".husky")); err == nil {
+		yellow.Print("\n  .husky is no longer used; husky and lint-staged can be removed from package.json\n")
+	}
+	green.Print("\n✅ Git hooks installed!\n\n")
+	return nil
+}
+
+func runHooksUninstall(cmd *cobra.Command, args []string) error {
+	green := color.New(color.FgGreen, color.Bold)
+
+	root, err := git.Root(".")
+	if err != nil {
+		return err
+	}
+	dir, err := git.HooksDir(root)
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, name := range sortedKeys(hooks) {
+		path := filepath.Join(dir, name)
+		if data, err := os.ReadFile(path); err == nil && strings.Contains(string(data), hookMarker) {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			removed++
+		}
+	}
+	green.Printf("\n✅ Removed %d hook(s)\n\n", removed)
+	return nil
+}
+
+// solidumCommand is how hooks invoke solidum: by name when it is on the
+// PATH, otherwise by the path of the running binary
+func solidumCommand() string {
+	if _, err := exec.LookPath("solidum"); err == nil {
+		return "solidum"
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "solidum"
+	}
+	return "'" + strings.ReplaceAll(exe, "'", `'\''`) + "'"
+}
@@ -1,31 +1,57 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/findings"
+	"github.com/kluth/solidum-cli/internal/git"
+	"github.com/kluth/solidum-cli/internal/lint"
+	"github.com/kluth/solidum-cli/internal/sarif"
+	"github.com/kluth/solidum-cli/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 var (
-	lintFix     bool
-	lintPackage string
+	lintFix         bool
+	lintPackage     string
+	lintReport      []string
+	lintFormat      string
+	lintOutput      string
+	lintSolidumOnly bool
+	lintStaged      bool
 )
 
 var lintCmd = &cobra.Command{
 	Use:   "lint",
 	Short: "Run ESLint on the project",
-	Long: `Run ESLint to check code quality and style.
+	Long: `Run ESLint to check code quality and style, after the built-in
+Solidum rules: atoms read outside a tracking scope, useContext outside
+render and imports of unexported or legacy package paths.
 
-Supports auto-fixing common issues with --fix flag.`,
+Supports auto-fixing common issues with --fix flag. Rules are configured in
+the "lint" section of solidum.json and can be disabled inline with
+// solidum-disable-next-line <rule> comments.
+
+--staged only lints the packages that hold files staged for commit, as the
+pre-commit hook of solidum hooks install does.`,
 	RunE: runLint,
 }
 
 func init() {
 	lintCmd.Flags().BoolVarP(&lintFix, "fix", "f", false, "Automatically fix problems")
 	lintCmd.Flags().StringVarP(&lintPackage, "package", "p", "", "Lint specific package")
+	lintCmd.Flags().StringSliceVar(&lintReport, "report", nil, "Also write problems as junit, sarif and/or annotations")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format of the Solidum rules: text, json or sarif")
+	lintCmd.Flags().StringVarP(&lintOutput, "output", "o", "", "Write the Solidum rules report to a file")
+	lintCmd.Flags().BoolVar(&lintSolidumOnly, "solidum-only", false, "Only run the Solidum rules, not ESLint")
+	lintCmd.Flags().BoolVar(&lintStaged, "staged", false, "Only lint packages with files staged for commit")
 }
 
 func runLint(cmd *cobra.Command, args []string) error {
@@ -33,7 +59,17 @@ func runLint(cmd *cobra.Command, args []string) error {
 	green := color.New(color.FgGreen, color.Bold)
 	yellow := color.New(color.FgYellow)
 
-	isMonorepo := fileExists("pnpm-workspace.yaml")
+	if err := checkReportFormats(lintReport); err != nil {
+		return err
+	}
+	switch lintFormat {
+	case "text", "json", "sarif":
+	default:
+		return fmt.Errorf("unknown format %q, expected text, json or sarif", lintFormat)
+	}
+	if lintStaged && lintPackage != "" {
+		return fmt.Errorf("--staged and --package can't be combined")
+	}
 
 	if lintFix {
 		yellow.Println("\n🔧 Auto-fix mode enabled...")
@@ -41,44 +77,335 @@ func runLint(cmd *cobra.Command, args []string) error {
 
 	cyan.Println("\n🔍 Linting code...")
 
-	var lintCommand string
-	var lintArgs []string
+	opts := taskRunOptions{}
+	if lintPackage != "" {
+		cyan.Printf("\n📦 Linting package: %s\n", lintPackage)
+		opts.filter = []string{lintPackage}
+	}
+	if lintFix {
+		opts.args = []string{"--fix"}
+	}
 
-	if isMonorepo {
-		if lintPackage != "" {
-			// Lint specific package
-			cyan.Printf("\n📦 Linting package: %s\n\n", lintPackage)
-			lintCommand = "pnpm"
-			lintArgs = []string{"--filter", lintPackage, "lint"}
-		} else {
-			// Lint all packages
-			lintCommand = "pnpm"
-			lintArgs = []string{"-r", "lint"}
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	if lintStaged {
+		opts.filter, err = stagedPackages(ws)
+		if err != nil {
+			return err
 		}
-	} else {
-		// Single project lint
-		lintCommand = "pnpm"
-		lintArgs = []string{"run", "lint"}
+		// No patterns would select every package
+		if len(opts.filter) == 0 {
+			green.Print("\n✅ No staged files to lint\n\n")
+			return nil
+		}
+		cyan.Printf("\n📦 Linting %d package(s) with staged files\n", len(opts.filter))
+	}
+	sol, err := runSolidumRules(ws, ws.Filter(opts.filter...))
+	if err != nil {
+		return err
 	}
+	// With --report the problems are listed along with ESLint's
+	listed := len(lintReport) > 0 && !lintSolidumOnly
+	if err := writeSolidumRules(sol, !listed); err != nil {
+		return err
+	}
+	solidumErr := sol.err()
 
-	if lintFix {
-		lintArgs = append(lintArgs, "--", "--fix")
+	if lintSolidumOnly {
+		if len(lintReport) > 0 {
+			r := findings.New("solidum")
+			sol.addTo(r)
+			if err := writeFindings(ws, "lint", r, lintReport); err != nil {
+				return err
+			}
+		}
+		if solidumErr != nil {
+			return solidumErr
+		}
+		green.Print("✅ No Solidum rule errors found!\n\n")
+		return nil
 	}
 
-	// Execute lint
-	cmdExec := exec.Command(lintCommand, lintArgs...)
-	cmdExec.Stdout = os.Stdout
-	cmdExec.Stderr = os.Stderr
-	cmdExec.Stdin = os.Stdin
+	if len(lintReport) > 0 {
+		return runLintReport(cmd, ws, opts, sol)
+	}
 
-	if err := cmdExec.Run(); err != nil {
+	if err := runTasks(cmd.Context(), []string{"lint"}, opts); err != nil {
 		return fmt.Errorf("linting failed: %w", err)
 	}
+	if solidumErr != nil {
+		return solidumErr
+	}
 
 	if lintFix {
-		green.Println("\n✅ Linting completed and issues fixed!\n")
+		green.Print("✅ Linting completed and issues fixed!\n\n")
 	} else {
-		green.Println("\n✅ No linting errors found!\n")
+		green.Print("✅ No linting errors found!\n\n")
+	}
+	return nil
+}
+
+// stagedPackages returns filter patterns for the packages that hold files
+// staged for commit
+func stagedPackages(ws *workspace.Workspace) ([]string, error) {
+	root, err := git.Root(ws.Root)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := git.Staged(root)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var patterns []string
+	for _, path := range paths {
+		rel := ws.Rel(path)
+		if filepath.IsAbs(rel) {
+			continue
+		}
+		pkg := ws.Owner(rel)
+		if pkg == nil || seen[pkg.Dir] {
+			continue
+		}
+		// The root package of a monorepo only owns what no package does
+		if ws.IsMonorepo() && pkg == ws.RootPackage {
+			continue
+		}
+		seen[pkg.Dir] = true
+		patterns = append(patterns, "./"+filepath.ToSlash(pkg.Dir))
+	}
+	sort.Strings(patterns)
+	return patterns, nil
+}
+
+// runLintReport lints with ESLint's JSON formatter, prints the problems
+// itself along with those of the Solidum rules and writes them in the
+// --report formats
+func runLintReport(cmd *cobra.Command, ws *workspace.Workspace, opts taskRunOptions, sol *solidumRules) error {
+	green := color.New(color.FgGreen, color.Bold)
+
+	pkgs := ws.Filter(opts.filter...)
+	for _, pkg := range pkgs {
+		os.Remove(ws.Path(pkg.Dir, findings.ESLintFile))
+	}
+
+	opts.args = append(opts.args, "--format", "json", "--output-file", findings.ESLintFile)
+	// A cached lint would leave no JSON behind to report from
+	opts.noCache = true
+	summary, runErr := runTasksSummary(cmd.Context(), []string{"lint"}, opts)
+	if summary == nil || cmd.Context().Err() != nil {
+		return fmt.Errorf("linting failed: %w", runErr)
+	}
+
+	r := findings.New("eslint")
+	parsed := make(map[string]bool)
+	for _, pkg := range pkgs {
+		err := r.AddESLint(pkg.Name, ws.Path(pkg.Dir, findings.ESLintFile), ws.Rel)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		parsed[pkg.Name+"#lint"] = true
+	}
+	addTaskFailures(r, summary, parsed)
+	sol.addTo(r)
+
+	printFindings(r)
+	if err := writeFindings(ws, "lint", r, lintReport); err != nil {
+		return err
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("linting failed: %w", runErr)
 	}
+	if errors, _ := r.Counts(); errors > 0 {
+		return fmt.Errorf("linting failed with %d error(s)", errors)
+	}
+	green.Print("✅ No linting errors found!\n\n")
 	return nil
 }
+
+// solidumRules is what the built-in rules found in the linted packages
+type solidumRules struct {
+	files    int
+	fixed    int
+	errors   int
+	warnings int
+	fixable  int
+
+	diags     []lint.Diagnostic
+	byPackage map[string][]lint.Diagnostic
+}
+
+// runSolidumRules checks the sources of pkgs with the built-in rules and,
+// with --fix, writes the fixes
+func runSolidumRules(ws *workspace.Workspace, pkgs []*workspace.Package) (*solidumRules, error) {
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return nil, err
+	}
+	l, err := lint.New(ws, cfg.Lint)
+	if err != nil {
+		return nil, err
+	}
+
+	sol := &solidumRules{byPackage: make(map[string][]lint.Diagnostic)}
+	for _, pkg := range pkgs {
+		files, err := l.Files(pkg)
+		if err != nil {
+			return nil, err
+		}
+		sol.byPackage[pkg.Name] = nil
+		for _, path := range files {
+			res, err := l.File(path, lintFix)
+			if err != nil {
+				return nil, err
+			}
+			if res.Changed() {
+				if err := res.Write(); err != nil {
+					return nil, err
+				}
+				sol.fixed += res.Fixed
+			}
+			sol.files++
+			sol.diags = append(sol.diags, res.Diagnostics...)
+			sol.byPackage[pkg.Name] = append(sol.byPackage[pkg.Name], res.Diagnostics...)
+		}
+	}
+	lint.SortDiagnostics(sol.diags)
+
+	for _, d := range sol.diags {
+		if d.Severity == lint.SeverityError {
+			sol.errors++
+		} else {
+			sol.warnings++
+		}
+		if d.Fixable {
+			sol.fixable++
+		}
+	}
+	return sol, nil
+}
+
+func (s *solidumRules) err() error {
+	if s.errors > 0 {
+		return fmt.Errorf("linting failed with %d Solidum rule error(s)", s.errors)
+	}
+	return nil
+}
+
+// addTo adds the problems to a --report
+func (s *solidumRules) addTo(r *findings.Report) {
+	for _, name := range sortedKeys(s.byPackage) {
+		r.AddLint(name, s.byPackage[name])
+	}
+}
+
+// writeSolidumRules prints the problems or writes them in --format to
+// --output. print is false when they are listed with ESLint's instead.
+func writeSolidumRules(s *solidumRules, print bool) error {
+	var out []byte
+	var err error
+	switch lintFormat {
+	case "json":
+		out, err = json.MarshalIndent(struct {
+			Files       int               `json:"files"`
+			Errors      int               `json:"errors"`
+			Warnings    int               `json:"warnings"`
+			Fixed       int               `json:"fixed"`
+			Diagnostics []lint.Diagnostic `json:"diagnostics"`
+		}{s.files, s.errors, s.warnings, s.fixed, append([]lint.Diagnostic{}, s.diags...)}, "", "  ")
+		out = append(out, '\n')
+	case "sarif":
+		out, err = lintSarif(s.diags).Marshal()
+	default:
+		if lintOutput == "" {
+			if print {
+				printSolidumRules(s)
+			}
+			return nil
+		}
+		out = []byte(formatLintDiagnostics(s.diags))
+	}
+	if err != nil {
+		return err
+	}
+
+	if lintOutput == "" {
+		os.Stdout.Write(out)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(lintOutput), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(lintOutput, out, 0644); err != nil {
+		return err
+	}
+	color.New(color.Faint).Printf("📝 Solidum rules report written to %s\n", lintOutput)
+	return nil
+}
+
+func printSolidumRules(s *solidumRules) {
+	red := color.New(color.FgRed, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	faint := color.New(color.Faint)
+
+	fmt.Println()
+	for _, d := range s.diags {
+		level := yellow.Sprint("warning")
+		if d.Severity == lint.SeverityError {
+			level = red.Sprint("error")
+		}
+		rule := d.Rule
+		if d.Fixable {
+			rule += " (fixable)"
+		}
+		fmt.Printf("%s:%d:%d  %s  %s  %s\n", d.File, d.Line, d.Col, level, d.Message, faint.Sprint(rule))
+	}
+
+	if s.fixed > 0 {
+		green.Printf("\n🔧 Fixed %d problem(s)\n", s.fixed)
+	}
+	switch {
+	case s.errors > 0:
+		red.Printf("\n❌ Solidum rules: %d error(s), %d warning(s) in %d file(s)\n", s.errors, s.warnings, s.files)
+	case s.warnings > 0:
+		yellow.Printf("\n⚠️  Solidum rules: %d warning(s) in %d file(s)\n", s.warnings, s.files)
+	default:
+		green.Printf("✅ Solidum rules passed for %d file(s)\n", s.files)
+	}
+	if s.fixable > 0 {
+		faint.Printf("🔧 %d problem(s) can be fixed with --fix\n", s.fixable)
+	}
+}
+
+// formatLintDiagnostics renders diagnostics as plain file:line:col lines
+func formatLintDiagnostics(diags []lint.Diagnostic) string {
+	var b strings.Builder
+	for _, d := range diags {
+		fmt.Fprintf(&b, "%s:%d:%d: %s: %s [%s]\n", d.File, d.Line, d.Col, d.Severity, d.Message, d.Rule)
+	}
+	return b.String()
+}
+
+func lintSarif(diags []lint.Diagnostic) *sarif.Log {
+	log := sarif.New("solidum lint", rootCmd.Version)
+	run := log.Run()
+	rules := make([]string, 0, len(lint.Rules))
+	for id := range lint.Rules {
+		rules = append(rules, id)
+	}
+	sort.Strings(rules)
+	for _, id := range rules {
+		run.AddRule(id, lint.Rules[id].Description)
+	}
+	for _, d := range diags {
+		run.Add(d.Rule, string(d.Severity), d.Message, d.File, d.Line, d.Col)
+	}
+	return log
+}
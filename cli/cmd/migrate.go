@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/codemod"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateDryRun bool
+	migrateList   bool
+	migratePath   string
+	migrateFrom   string
+	migrateTo     string
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [migration...]",
+	Short: "Migrate your code to a newer Solidum version",
+	Long: `Run codemods that rewrite your source for a newer Solidum version.
+
+Migrations rename import paths (e.g. @solidum/* → @sldm/*) and identifiers
+using a TypeScript-aware tokenizer, so strings and comments are left alone.
+Use --dry-run to preview the changes as unified diffs.`,
+	RunE: runMigrate,
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "Print diffs without writing files")
+	migrateCmd.Flags().BoolVarP(&migrateList, "list", "l", false, "List available migrations")
+	migrateCmd.Flags().StringVarP(&migratePath, "path", "p", "src", "Directory to migrate")
+	migrateCmd.Flags().StringVar(&migrateFrom, "from", "", "Version to migrate from (e.g., 0.1)")
+	migrateCmd.Flags().StringVar(&migrateTo, "to", "", "Version to migrate to (e.g., 0.3)")
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+
+	if migrateList {
+		cyan.Print("\n📋 Available migrations:\n\n")
+		for _, m := range codemod.Migrations() {
+			fmt.Printf("  %-22s %s → %s  %s\n", m.ID, m.From, m.To, m.Description)
+		}
+		fmt.Println()
+		return nil
+	}
+
+	selected, err := selectMigrations(args)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no migrations selected\nRun 'solidum migrate --list' to see available migrations")
+	}
+
+	if migrateDryRun {
+		yellow.Println("\n🔍 Running in dry-run mode - no files will be changed")
+	}
+
+	cyan.Printf("\n🔄 Migrating %s...\n\n", migratePath)
+	for _, m := range selected {
+		fmt.Printf("  → %s: %s\n", m.ID, m.Description)
+	}
+	fmt.Println()
+
+	files, err := codemod.FindSources(migratePath)
+	if err != nil {
+		return fmt.Errorf("failed to find sources: %w", err)
+	}
+
+	transforms := codemod.Transforms(selected)
+	changedFiles, totalChanges := 0, 0
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		result, err := codemod.Run(path, string(data), transforms)
+		if err != nil {
+			return err
+		}
+		if !result.Changed() {
+			continue
+		}
+
+		changedFiles++
+		totalChanges += len(result.Changes)
+
+		for _, c := range result.Changes {
+			fmt.Printf("  %s:%d:%d  %s\n", c.File, c.Line, c.Col, c.Reason)
+		}
+
+		if migrateDryRun {
+			fmt.Println()
+			fmt.Print(codemod.UnifiedDiff(path, result.Original, result.Updated))
+			fmt.Println()
+			continue
+		}
+
+		if err := os.WriteFile(path, []byte(result.Updated), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	if changedFiles == 0 {
+		green.Println("✅ Nothing to migrate - your code is up to date!")
+		fmt.Println()
+		return nil
+	}
+
+	if migrateDryRun {
+		green.Printf("\n✅ %d change(s) in %d file(s) would be applied\n\n", totalChanges, changedFiles)
+	} else {
+		green.Printf("\n✅ Applied %d change(s) in %d file(s)\n\n", totalChanges, changedFiles)
+	}
+	return nil
+}
+
+func selectMigrations(ids []string) ([]*codemod.Migration, error) {
+	if len(ids) > 0 {
+		var selected []*codemod.Migration
+		for _, id := range ids {
+			m, err := codemod.Lookup(id)
+			if err != nil {
+				return nil, err
+			}
+			selected = append(selected, m)
+		}
+		return selected, nil
+	}
+
+	if migrateFrom != "" || migrateTo != "" {
+		return codemod.Between(migrateFrom, migrateTo), nil
+	}
+
+	return codemod.Migrations(), nil
+}
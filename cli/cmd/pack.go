@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/pack"
+	"github.com/kluth/solidum-cli/internal/size"
+	"github.com/kluth/solidum-cli/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	packFilter      []string
+	packCheck       bool
+	packOutput      string
+	packMaxFileSize string
+)
+
+var packCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "Pack packages into tarballs and check what they would publish",
+	Long: `Run pnpm pack for each public package and report the tarball sizes.
+
+With --check, each tarball is inspected and the command fails when:
+
+  - a main, module, types, bin or exports target is missing
+  - dist/ is missing from a package that is built
+  - it holds test files (*.test.ts, *.spec.ts, __tests__/) or .env files
+  - a file is larger than the limit (1 MB, or pack.maxFileSize in solidum.json)
+  - a dependency still has a workspace: range
+
+solidum publish runs the same checks before publishing anything.`,
+	Example: `  solidum pack --check
+  solidum pack --check -p @sldm/core --max-file-size 512KB`,
+	Args: cobra.NoArgs,
+	RunE: runPack,
+}
+
+func init() {
+	packCmd.Flags().StringSliceVarP(&packFilter, "packages", "p", nil, "Only pack these packages (names, prefix* or ./dir)")
+	packCmd.Flags().BoolVar(&packCheck, "check", false, "Check the tarball contents and fail on problems")
+	packCmd.Flags().StringVarP(&packOutput, "output", "o", ".solidum/pack", "Directory to write the tarballs to")
+	packCmd.Flags().StringVar(&packMaxFileSize, "max-file-size", "", "Largest file a tarball may hold (default from solidum.json, else 1 MB)")
+}
+
+func runPack(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return err
+	}
+	limit, err := maxFileSize(packMaxFileSize, cfg)
+	if err != nil {
+		return err
+	}
+
+	var pkgs []*workspace.Package
+	for _, pkg := range ws.Filter(packFilter...) {
+		if !pkg.Private && pkg.Name != "" && pkg.Version != "" {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	if len(pkgs) == 0 {
+		return fmt.Errorf("no public packages to pack")
+	}
+
+	dest := packOutput
+	if !filepath.IsAbs(dest) {
+		dest = ws.Path(dest)
+	}
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	cyan.Printf("\n📦 Packing %d package(s)...\n\n", len(pkgs))
+	problems, err := packPackages(ws, pkgs, dest, packCheck, limit)
+	if err != nil {
+		return err
+	}
+	if problems > 0 {
+		red.Printf("❌ %d problem(s) in the tarballs\n\n", problems)
+		return &ExitError{Code: 1, Err: fmt.Errorf("pack check found %d problem(s)", problems)}
+	}
+	if packCheck {
+		green.Print("✅ Every tarball passed the checks\n\n")
+	} else {
+		green.Printf("✅ Packed into %s\n\n", ws.Rel(dest))
+	}
+	return nil
+}
+
+// maxFileSize returns the file size limit of the pack checks from a flag,
+// solidum.json or the default
+func maxFileSize(flag string, cfg *config.Config) (int64, error) {
+	if flag == "" && cfg.Pack != nil {
+		flag = cfg.Pack.MaxFileSize
+	}
+	if flag == "" {
+		return pack.DefaultMaxFileSize, nil
+	}
+	return size.ParseBytes(flag)
+}
+
+// packPackages packs each package into dest and prints its tarball, with
+// the problems found when check is set. It returns the number of problems.
+func packPackages(ws *workspace.Workspace, pkgs []*workspace.Package, dest string, check bool, limit int64) (int, error) {
+	red := color.New(color.FgRed)
+	faint := color.New(color.Faint)
+
+	problems := 0
+	for _, pkg := range pkgs {
+		file, err := pack.Pack(ws.Path(pkg.Dir), dest)
+		if err != nil {
+			return problems, err
+		}
+		t, err := pack.Read(file)
+		if err != nil {
+			return problems, err
+		}
+
+		var violations []pack.Violation
+		if check {
+			violations = t.Check(limit)
+		}
+		mark := "✓"
+		if len(violations) > 0 {
+			mark = "✗"
+		}
+		fmt.Printf("  %s %-32s %10s", mark, pkg.Name+"@"+pkg.Version, size.Format(t.Size))
+		faint.Printf("  %s unpacked, %d file(s)", size.Format(t.Unpacked), len(t.Files))
+		// Tarballs packed only to be checked live outside the workspace
+		if rel := ws.Rel(file); !filepath.IsAbs(rel) {
+			faint.Printf("  %s", rel)
+		}
+		fmt.Println()
+		for _, v := range violations {
+			red.Printf("      %-16s", v.Rule)
+			fmt.Printf(" %s", v.Message)
+			if v.File != "" && !strings.Contains(v.Message, v.File) {
+				faint.Printf(" (%s)", v.File)
+			}
+			fmt.Println()
+		}
+		problems += len(violations)
+	}
+	fmt.Println()
+	return problems, nil
+}
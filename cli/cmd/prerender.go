@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/prerender"
+	"github.com/kluth/solidum-cli/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	prerenderEntry       string
+	prerenderRoutes      string
+	prerenderOutDir      string
+	prerenderSiteURL     string
+	prerenderConcurrency int
+	prerenderSkipBuild   bool
+	prerenderTimeout     time.Duration
+)
+
+var prerenderCmd = &cobra.Command{
+	Use:   "prerender",
+	Short: "Pre-render routes to static HTML",
+	Long: `Pre-render an app's routes to static HTML files (static site generation).
+
+Builds the SSR entry with Vite, renders every route through Node workers
+managed by the CLI and writes dist/<route>/index.html, inserting each page
+into the client build's index.html. A sitemap.xml is written when the site
+URL is known.
+
+The SSR entry (src/entry-server.ts by default) must export render(url),
+returning an HTML string or { html, title?, head? } - typically built with
+@sldm/ssr's renderToString. Routes come from, in order:
+  --routes <file>          a JSON array of paths or a RouteConfig object
+  prerender.routes         in solidum.json
+  routes / getRoutes()     exported by the SSR entry, e.g. the router config
+
+Dynamic routes such as /users/:id are skipped.`,
+	Example: `  solidum build && solidum prerender
+  solidum prerender --routes routes.json --site-url https://example.com`,
+	RunE: runPrerender,
+}
+
+func init() {
+	prerenderCmd.Flags().StringVar(&prerenderEntry, "entry", "", "SSR entry module (default: src/entry-server.ts)")
+	prerenderCmd.Flags().StringVar(&prerenderRoutes, "routes", "", "Routes manifest (JSON array of paths or RouteConfig object)")
+	prerenderCmd.Flags().StringVarP(&prerenderOutDir, "out-dir", "o", "dist", "Client build directory to write pages into")
+	prerenderCmd.Flags().StringVar(&prerenderSiteURL, "site-url", "", "Public site URL for sitemap.xml (default: prerender.siteUrl or package.json homepage)")
+	prerenderCmd.Flags().IntVarP(&prerenderConcurrency, "concurrency", "c", runtime.NumCPU(), "Number of render workers")
+	prerenderCmd.Flags().BoolVar(&prerenderSkipBuild, "skip-build", false, "Reuse the existing SSR bundle")
+	prerenderCmd.Flags().DurationVar(&prerenderTimeout, "timeout", 30*time.Second, "Fail a route that takes longer to render (0 waits forever)")
+}
+
+func runPrerender(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+	red := color.New(color.FgRed)
+	faint := color.New(color.Faint)
+
+	cfg, err := config.Load(".")
+	if err != nil {
+		return err
+	}
+	settings := cfg.Prerender
+	if settings == nil {
+		settings = &config.Prerender{}
+	}
+
+	entry := prerenderEntry
+	if entry == "" {
+		entry = settings.Entry
+	}
+	if entry == "" {
+		entry = "src/entry-server.ts"
+	}
+	if !fileExists(entry) {
+		return fmt.Errorf("SSR entry %s not found; create it or pass --entry", entry)
+	}
+
+	if !prerenderSkipBuild {
+		cyan.Printf("\n🔨 Building SSR bundle from %s...\n\n", entry)
+		if err := runCommandInteractive(cmd.Context(), "pnpm", "exec", "vite", "build",
+			"--ssr", entry, "--outDir", prerender.BuildDir, "--emptyOutDir"); err != nil {
+			return fmt.Errorf("SSR build failed: %w", err)
+		}
+	}
+
+	bundle, err := findSSRBundle(entry)
+	if err != nil {
+		return err
+	}
+	script, err := prerender.WriteWorkerScript(prerender.BuildDir)
+	if err != nil {
+		return err
+	}
+
+	shell, found, err := prerender.ReadShell(prerenderOutDir, prerender.ShellDir)
+	if err != nil {
+		return err
+	}
+	if !found {
+		yellow.Printf("\n⚠️  %s has no index.html, using a minimal document (run 'solidum build' first)\n", prerenderOutDir)
+	}
+
+	opts := prerender.Options{
+		Bundle:      bundle,
+		Script:      script,
+		OutDir:      prerenderOutDir,
+		Shell:       shell,
+		Concurrency: prerenderConcurrency,
+		Timeout:     prerenderTimeout,
+	}
+
+	var routes []string
+	switch {
+	case prerenderRoutes != "":
+		if routes, err = prerender.LoadManifest(prerenderRoutes); err != nil {
+			return err
+		}
+	case len(settings.Routes) > 0:
+		routes = settings.Routes
+	default:
+		var ok bool
+		routes, ok, err = prerender.DiscoverRoutes(opts)
+		if err != nil {
+			return fmt.Errorf("failed to load routes from %s: %w", bundle, err)
+		}
+		if !ok {
+			return fmt.Errorf("no routes to render: pass --routes, set prerender.routes in %s, or export routes from %s", config.FileName, entry)
+		}
+	}
+
+	routes, dynamic := prerender.Normalize(routes)
+	for _, route := range dynamic {
+		yellow.Printf("⚠️  Skipping dynamic route %s\n", route)
+	}
+	if len(routes) == 0 {
+		return fmt.Errorf("no static routes to render")
+	}
+
+	cyan.Printf("\n📄 Pre-rendering %d route(s)...\n\n", len(routes))
+	start := time.Now()
+
+	var failed []prerender.Result
+	prerender.Render(cmd.Context(), opts, routes, func(res prerender.Result) {
+		if res.Err != nil {
+			red.Printf("  ✗ %s\n", res.Route)
+			failed = append(failed, res)
+			return
+		}
+		fmt.Printf("  ✓ %s %s\n", res.Route, faint.Sprintf("→ %s (%s)", res.File, res.Duration.Round(time.Millisecond)))
+	})
+	if err := cmd.Context().Err(); err != nil {
+		return fmt.Errorf("interrupted: %w", err)
+	}
+
+	siteURL := prerenderSiteURL
+	if siteURL == "" {
+		siteURL = settings.SiteURL
+	}
+	if siteURL == "" {
+		if pkg, err := workspace.ReadPackage("package.json"); err == nil {
+			siteURL = pkg.Homepage
+		}
+	}
+	if siteURL != "" {
+		sitemap := filepath.Join(prerenderOutDir, "sitemap.xml")
+		if err := prerender.WriteSitemap(sitemap, siteURL, renderedRoutes(routes, failed)); err != nil {
+			return err
+		}
+		fmt.Printf("  ✓ %s\n", sitemap)
+	} else {
+		yellow.Println("\n⚠️  Skipping sitemap.xml: set --site-url, prerender.siteUrl or the package.json homepage")
+	}
+
+	if len(failed) > 0 {
+		red.Printf("\n❌ %d of %d route(s) failed:\n", len(failed), len(routes))
+		for _, res := range failed {
+			red.Printf("\n%s\n", res.Route)
+			fmt.Println(indent(res.Err.Error(), "  "))
+		}
+		fmt.Println()
+		return fmt.Errorf("%d route(s) failed to render", len(failed))
+	}
+
+	green.Printf("\n✅ Pre-rendered %d route(s) in %s\n\n", len(routes), time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// findSSRBundle returns the built module for entry, e.g. src/entry-server.ts →
+// .solidum/ssr/entry-server.js
+func findSSRBundle(entry string) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(entry), filepath.Ext(entry))
+	for _, ext := range []string{".js", ".mjs"} {
+		bundle := filepath.Join(prerender.BuildDir, name+ext)
+		if fileExists(bundle) {
+			return filepath.Abs(bundle)
+		}
+	}
+	return "", fmt.Errorf("SSR bundle for %s not found in %s", entry, prerender.BuildDir)
+}
+
+// renderedRoutes returns routes without the failed ones
+func renderedRoutes(routes []string, failed []prerender.Result) []string {
+	bad := make(map[string]bool)
+	for _, res := range failed {
+		bad[res.Route] = true
+	}
+	var ok []string
+	for _, route := range routes {
+		if !bad[route] {
+			ok = append(ok, route)
+		}
+	}
+	return ok
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
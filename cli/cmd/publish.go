@@ -2,20 +2,28 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/proc"
+	"github.com/kluth/solidum-cli/internal/registry"
+	"github.com/kluth/solidum-cli/internal/release"
+	"github.com/kluth/solidum-cli/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 var (
-	publishDryRun bool
-	publishTag    string
-	publishAccess string
-	publishForce  bool
+	publishDryRun   bool
+	publishTag      string
+	publishAccess   string
+	publishForce    bool
+	publishResume   bool
+	publishRegistry string
 )
 
 var publishCmd = &cobra.Command{
@@ -23,8 +31,14 @@ var publishCmd = &cobra.Command{
 	Short: "Publish packages to npm",
 	Long: `Build, test, and publish packages to npm registry.
 
-For monorepos, publishes all packages in the workspace.
-Includes safety checks and version validation.`,
+Every public package of the workspace is published on its own, dependencies
+first. Versions already on the registry are skipped, so running publish again
+after a release only publishes what is new. Before anything is published,
+every tarball must pass the checks of solidum pack --check.
+
+Progress is recorded in .solidum/release-journal.json. When a package fails
+to publish, the release stops there; fix the problem and run
+solidum publish --resume to publish the rest.`,
 	RunE: runPublish,
 }
 
@@ -33,6 +47,8 @@ func init() {
 	publishCmd.Flags().StringVarP(&publishTag, "tag", "t", "latest", "npm dist-tag (latest, next, beta, etc.)")
 	publishCmd.Flags().StringVar(&publishAccess, "access", "public", "Package access (public or restricted)")
 	publishCmd.Flags().BoolVarP(&publishForce, "force", "f", false, "Skip confirmation prompts")
+	publishCmd.Flags().BoolVar(&publishResume, "resume", false, "Finish the release recorded in the journal, skipping build and tests")
+	publishCmd.Flags().StringVar(&publishRegistry, "registry", "", "Registry to publish to (default from .npmrc)")
 }
 
 func runPublish(cmd *cobra.Command, args []string) error {
@@ -40,56 +56,180 @@ func runPublish(cmd *cobra.Command, args []string) error {
 	green := color.New(color.FgGreen, color.Bold)
 	yellow := color.New(color.FgYellow, color.Bold)
 	red := color.New(color.FgRed, color.Bold)
+	faint := color.New(color.Faint)
 
-	isMonorepo := fileExists("pnpm-workspace.yaml")
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	journalPath := ws.Path(release.JournalFile)
+	journal, err := release.LoadJournal(journalPath)
+	if err != nil {
+		return err
+	}
 
 	if publishDryRun {
-		yellow.Println("\n🔍 Running in dry-run mode - no packages will be published\n")
+		yellow.Print("\n🔍 Running in dry-run mode - no packages will be published\n")
 	}
 
-	cyan.Println("\n📦 Preparing to publish packages...\n")
+	if publishResume {
+		if journal == nil || journal.Done() {
+			return fmt.Errorf("no unfinished release in %s to resume", release.JournalFile)
+		}
+		cyan.Printf("\n📦 Resuming the release started %s...\n\n", journal.Started.Local().Format("2006-01-02 15:04"))
+		if err := checkJournal(ws, journal); err != nil {
+			return err
+		}
+	} else {
+		if journal != nil && !journal.Done() && !publishDryRun {
+			return fmt.Errorf("%s records an unfinished release; finish it with `solidum publish --resume`, or delete the file to start over", release.JournalFile)
+		}
 
-	// Safety check: ensure git is clean
-	if !publishForce && !publishDryRun {
-		if !isGitClean() {
-			red.Println("❌ Git working directory is not clean!")
-			fmt.Println("Please commit or stash your changes before publishing.")
-			fmt.Println("Use --force to skip this check.")
-			return fmt.Errorf("git working directory not clean")
+		cyan.Print("\n📦 Preparing to publish packages...\n\n")
+
+		// Safety check: ensure git is clean
+		if !publishForce && !publishDryRun {
+			if !isGitClean() {
+				red.Println("❌ Git working directory is not clean!")
+				fmt.Println("Please commit or stash your changes before publishing.")
+				fmt.Println("Use --force to skip this check.")
+				return fmt.Errorf("git working directory not clean")
+			}
+			green.Println("✓ Git working directory is clean")
+		}
+
+		// Step 1: Build
+		cyan.Print("\n🔨 Step 1/4: Building packages...\n")
+		if err := runCommandInteractive(cmd.Context(), "pnpm", "run", "build"); err != nil {
+			return fmt.Errorf("build failed: %w", err)
+		}
+		green.Println("✓ Build completed")
+
+		// Step 2: Test
+		cyan.Print("\n🧪 Step 2/4: Running tests...\n")
+		if err := runCommandInteractive(cmd.Context(), "pnpm", "test"); err != nil {
+			return fmt.Errorf("tests failed: %w", err)
 		}
-		green.Println("✓ Git working directory is clean")
+		green.Println("✓ All tests passed")
+
+		// Step 3: Prepare publish (if script exists)
+		cyan.Print("\n📝 Step 3/4: Preparing packages...\n")
+		if scriptExists("publish:prepare") {
+			if err := runCommandInteractive(cmd.Context(), "pnpm", "run", "publish:prepare"); err != nil {
+				yellow.Printf("⚠️  publish:prepare script failed (continuing anyway): %v\n", err)
+			} else {
+				green.Println("✓ Packages prepared")
+			}
+		} else {
+			yellow.Println("ℹ️  No publish:prepare script found (skipping)")
+		}
+
+		journal, err = release.NewJournal(ws, publishTag, publishAccess, func(pkg *workspace.Package) string {
+			if publishRegistry != "" {
+				return publishRegistry
+			}
+			return registry.Configured(ws.Path(pkg.Dir), pkg.Name)
+		})
+		if err != nil {
+			return err
+		}
+		cyan.Print("\n📤 Step 4/4: Publishing packages...\n\n")
 	}
 
-	// Step 1: Build
-	cyan.Println("\n🔨 Step 1/4: Building packages...")
-	if err := runCommandInteractive("pnpm", "run", "build"); err != nil {
-		return fmt.Errorf("build failed: %w", err)
+	// Versions already on the registry are not published again, including
+	// those an interrupted run published before it could record them
+	recorded := make(map[*release.JournalEntry]bool)
+	for _, e := range journal.Packages {
+		recorded[e] = e.Status == release.Published || e.Status == release.Skipped
+	}
+	attempted := 0
+	publisher := &release.Publisher{
+		Journal: journal,
+		DryRun:  publishDryRun,
+		Token:   func(reg string) string { return registry.ConfiguredToken(ws.Root, reg) },
+		Publish: func(ctx context.Context, e *release.JournalEntry) error {
+			attempted++
+			cyan.Printf("📤 Publishing %s@%s...\n", e.Name, e.Version)
+			publishArgs := []string{"publish", "--access", journal.Access, "--tag", journal.Tag, "--registry", e.Registry, "--no-git-checks"}
+			if publishDryRun {
+				publishArgs = append(publishArgs, "--dry-run")
+			}
+			if err := runCommandInDir(ctx, ws.Path(e.Dir), "pnpm", publishArgs...); err != nil {
+				return err
+			}
+			fmt.Println()
+			return nil
+		},
+	}
+	if !publishDryRun {
+		publisher.Path = journalPath
 	}
-	green.Println("✓ Build completed")
+	pending, err := publisher.Pending(cmd.Context(), publishResume)
+	if err != nil {
+		return err
+	}
+	toPublish := make(map[*release.JournalEntry]bool)
+	for _, e := range pending {
+		toPublish[e] = true
+	}
+	for _, e := range journal.Packages {
+		fmt.Printf("  📦 %-36s", e.Name+"@"+e.Version)
+		switch {
+		case recorded[e]:
+			faint.Printf(" %s\n", e.Status)
+		case toPublish[e]:
+			green.Print(" to publish\n")
+		default:
+			faint.Printf(" already on %s\n", e.Registry)
+		}
+	}
+	fmt.Println()
 
-	// Step 2: Test
-	cyan.Println("\n🧪 Step 2/4: Running tests...")
-	if err := runCommandInteractive("pnpm", "test"); err != nil {
-		return fmt.Errorf("tests failed: %w", err)
+	if len(pending) == 0 {
+		if !publishDryRun {
+			if err := journal.Save(journalPath); err != nil {
+				return err
+			}
+		}
+		green.Print("✅ Nothing to publish - every version is already on the registry\n\n")
+		return nil
 	}
-	green.Println("✓ All tests passed")
 
-	// Step 3: Prepare publish (if script exists)
-	cyan.Println("\n📝 Step 3/4: Preparing packages...")
-	if scriptExists("publish:prepare") {
-		if err := runCommandInteractive("pnpm", "run", "publish:prepare"); err != nil {
-			yellow.Printf("⚠️  publish:prepare script failed (continuing anyway): %v\n", err)
-		} else {
-			green.Println("✓ Packages prepared")
+	// Nothing goes out unless every tarball passes the pack checks
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return err
+	}
+	limit, err := maxFileSize("", cfg)
+	if err != nil {
+		return err
+	}
+	var pkgs []*workspace.Package
+	for _, e := range pending {
+		pkg, err := workspace.ReadPackage(ws.Path(e.Dir, "package.json"))
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Name, err)
 		}
-	} else {
-		yellow.Println("ℹ️  No publish:prepare script found (skipping)")
+		pkg.Dir = e.Dir
+		pkgs = append(pkgs, pkg)
+	}
+	dest, err := os.MkdirTemp("", "solidum-pack-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dest)
+	cyan.Print("🔎 Checking package tarballs...\n\n")
+	problems, err := packPackages(ws, pkgs, dest, true, limit)
+	if err != nil {
+		return err
+	}
+	if problems > 0 {
+		red.Printf("❌ %d problem(s) in the tarballs - nothing was published\n\n", problems)
+		return fmt.Errorf("pack check found %d problem(s)", problems)
 	}
 
-	// Step 4: Publish
 	if !publishForce && !publishDryRun {
-		fmt.Println()
-		yellow.Println("⚠️  You are about to publish packages to npm!")
+		yellow.Printf("⚠️  You are about to publish %d package(s) to npm!\n", len(pending))
 		fmt.Print("Continue? (y/N): ")
 
 		reader := bufio.NewReader(os.Stdin)
@@ -100,39 +240,51 @@ func runPublish(cmd *cobra.Command, args []string) error {
 			fmt.Println("\nPublish cancelled.")
 			return nil
 		}
+		fmt.Println()
 	}
 
-	cyan.Println("\n📤 Step 4/4: Publishing packages...")
-
-	var publishArgs []string
-	if isMonorepo {
-		publishArgs = []string{"-r", "--filter", "./packages/*", "publish"}
-	} else {
-		publishArgs = []string{"publish"}
-	}
-
-	publishArgs = append(publishArgs, "--access", publishAccess)
-	publishArgs = append(publishArgs, "--tag", publishTag)
-	publishArgs = append(publishArgs, "--no-git-checks")
-
-	if publishDryRun {
-		publishArgs = append(publishArgs, "--dry-run")
-	}
-
-	if err := runCommandInteractive("pnpm", publishArgs...); err != nil {
+	if err := publisher.Run(cmd.Context(), pending); err != nil {
+		if attempted > 0 && pending[attempted-1].Status == release.Failed {
+			e := pending[attempted-1]
+			red.Printf("\n❌ %s@%s failed to publish\n", e.Name, e.Version)
+			if rest := len(pending) - attempted; rest > 0 {
+				fmt.Printf("%d package(s) after it were not published.\n", rest)
+			}
+			if !publishDryRun {
+				fmt.Print("Fix the problem, then run `solidum publish --resume`.\n\n")
+			}
+		}
 		return fmt.Errorf("publish failed: %w", err)
 	}
 
 	if publishDryRun {
-		green.Println("\n✅ Dry-run completed successfully!")
+		green.Print("✅ Dry-run completed successfully!\n\n")
 	} else {
-		green.Println("\n✅ Packages published successfully!")
-		fmt.Println("\n🎉 Your packages are now available on npm!")
+		green.Printf("✅ Published %d package(s)!\n", len(pending))
+		fmt.Print("\n🎉 Your packages are now available on npm!\n\n")
 	}
 
 	return nil
 }
 
+// checkJournal makes sure the packages left to publish still have the
+// versions the release started with
+func checkJournal(ws *workspace.Workspace, journal *release.Journal) error {
+	for _, e := range journal.Packages {
+		if e.Status == release.Published || e.Status == release.Skipped {
+			continue
+		}
+		pkg, err := workspace.ReadPackage(ws.Path(e.Dir, "package.json"))
+		if err != nil {
+			return fmt.Errorf("%s: %w", e.Name, err)
+		}
+		if pkg.Name != e.Name || pkg.Version != e.Version {
+			return fmt.Errorf("%s is now %s@%s but the release was of %s@%s; delete %s to start over", e.Dir, pkg.Name, pkg.Version, e.Name, e.Version, release.JournalFile)
+		}
+	}
+	return nil
+}
+
 func isGitClean() bool {
 	cmd := exec.Command("git", "status", "--porcelain")
 	output, err := cmd.Output()
@@ -148,10 +300,16 @@ func scriptExists(scriptName string) bool {
 	return err == nil
 }
 
-func runCommandInteractive(name string, args ...string) error {
+func runCommandInteractive(ctx context.Context, name string, args ...string) error {
+	return runCommandInDir(ctx, "", name, args...)
+}
+
+// runCommandInDir runs a command attached to the terminal in dir
+func runCommandInDir(ctx context.Context, dir, name string, args ...string) error {
 	cmd := exec.Command(name, args...)
+	cmd.Dir = dir
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
-	return cmd.Run()
+	return proc.Run(ctx, name, cmd)
 }
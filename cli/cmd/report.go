@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/coverage"
+	"github.com/kluth/solidum-cli/internal/report"
+	"github.com/kluth/solidum-cli/internal/size"
+	"github.com/kluth/solidum-cli/internal/vitest"
+	"github.com/kluth/solidum-cli/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportPackages         []string
+	reportOutDir           string
+	reportTrend            int
+	reportNoHistory        bool
+	reportFailOnRegression bool
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate the dev report from test, coverage and size data",
+	Long: `Generate reports/dev-report.html, .md and .json from real data.
+
+For each package, the report reads what the last runs left behind:
+
+  .solidum/test-results.json     vitest's JSON reporter output
+  coverage/coverage-final.json   v8 coverage (or coverage-summary.json)
+
+and merges it with the bundle sizes in .solidum/size.json written by
+'solidum size'. The JSON report has the DevReport shape of @sldm/dev-reports.
+
+A summary of every run is kept in reports/history/, one file per commit.
+The report shows the trend and the regressions since the previous commit:
+more failing tests, lower coverage or gzipped bundles more than 1% bigger.`,
+	Example: `  solidum report
+  solidum report -f @sldm/core --no-history
+  solidum report --fail-on-regression`,
+	RunE: runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringSliceVarP(&reportPackages, "package", "f", nil, "Only report matching packages")
+	reportCmd.Flags().StringVarP(&reportOutDir, "out-dir", "o", "reports", "Directory to write the reports to")
+	reportCmd.Flags().IntVar(&reportTrend, "trend", 10, "Number of runs shown in the trend")
+	reportCmd.Flags().BoolVar(&reportNoHistory, "no-history", false, "Don't record this run in the history")
+	reportCmd.Flags().BoolVar(&reportFailOnRegression, "fail-on-regression", false, "Exit with an error when a metric regressed")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+	red := color.New(color.FgRed, color.Bold)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	pkgs := ws.Filter(reportPackages...)
+	if len(pkgs) == 0 {
+		return fmt.Errorf("no packages match %s", strings.Join(reportPackages, ", "))
+	}
+
+	cyan.Print("\n📊 Generating dev report...\n\n")
+
+	bundle, err := size.Load(ws.Path(size.ReportFile))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if bundle != nil && len(reportPackages) > 0 {
+		bundle = filterBundle(bundle, pkgs)
+	}
+
+	r, err := report.Collect(ws, pkgs, bundle)
+	if err != nil {
+		return err
+	}
+	if len(r.Packages) == 0 {
+		return fmt.Errorf("no test results, coverage or bundle sizes found; run the tests with coverage and 'solidum size' first")
+	}
+
+	outDir := ws.Path(reportOutDir)
+	historyDir := filepath.Join(outDir, report.HistoryDir)
+	history, err := report.LoadHistory(historyDir)
+	if err != nil {
+		return err
+	}
+	r.SetHistory(history, reportTrend)
+
+	data, err := r.JSON()
+	if err != nil {
+		return err
+	}
+	html, err := r.HTML()
+	if err != nil {
+		return err
+	}
+	files := map[string][]byte{
+		"dev-report.html": []byte(html),
+		"dev-report.md":   []byte(r.Markdown()),
+		"dev-report.json": data,
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return err
+	}
+	for _, name := range []string{"dev-report.html", "dev-report.md", "dev-report.json"} {
+		if err := os.WriteFile(filepath.Join(outDir, name), files[name], 0644); err != nil {
+			return err
+		}
+	}
+
+	printReportSummary(r)
+
+	if r.Previous != nil {
+		if len(r.Regressions) > 0 {
+			yellow.Printf("⚠️  Regressions since %s:\n", r.Previous.Commit)
+			for _, reg := range r.Regressions {
+				yellow.Printf("  • %s\n", reg)
+			}
+			fmt.Println()
+		} else {
+			green.Printf("✅ No regressions since %s\n\n", r.Previous.Commit)
+		}
+	}
+
+	if !reportNoHistory {
+		if _, err := report.SaveEntry(historyDir, r.Entry(), history); err != nil {
+			return err
+		}
+	}
+
+	green.Printf("✅ Reports written to %s/\n", reportOutDir)
+	for _, name := range []string{"dev-report.html", "dev-report.md", "dev-report.json"} {
+		fmt.Printf("  %s\n", filepath.Join(reportOutDir, name))
+	}
+	fmt.Println()
+
+	if reportFailOnRegression && len(r.Regressions) > 0 {
+		red.Printf("❌ %d regression(s) found\n\n", len(r.Regressions))
+		return fmt.Errorf("%d regression(s) since %s", len(r.Regressions), r.Previous.Commit)
+	}
+	return nil
+}
+
+// filterBundle keeps the bundles of the selected packages
+func filterBundle(bundle *size.Report, pkgs []*workspace.Package) *size.Report {
+	selected := make(map[string]bool)
+	for _, pkg := range pkgs {
+		selected[pkg.Name] = true
+	}
+	filtered := &size.Report{Name: bundle.Name, Bundles: []*size.Bundle{}, Timestamp: bundle.Timestamp}
+	for _, b := range bundle.Bundles {
+		if selected[b.Package] {
+			filtered.Bundles = append(filtered.Bundles, b)
+			filtered.TotalSize += b.Size
+			filtered.TotalGzipSize += b.GzipSize
+			filtered.TotalBrotliSize += b.BrotliSize
+		}
+	}
+	return filtered
+}
+
+func printReportSummary(r *report.Report) {
+	faint := color.New(color.Faint)
+	bold := color.New(color.Bold)
+
+	width := len("Package")
+	for _, p := range r.Packages {
+		if len(p.Name) > width {
+			width = len(p.Name)
+		}
+	}
+
+	row := func(p *report.Package) string {
+		tests, failed, lines, gzip := "-", "", "-", "-"
+		if p.Tests != nil {
+			tests = fmt.Sprintf("%d/%d", p.Tests.Passed, p.Tests.Total)
+			if p.Tests.Failed > 0 {
+				failed = color.New(color.FgRed).Sprintf("%d failed", p.Tests.Failed)
+			}
+		}
+		if p.Coverage != nil {
+			lines = coverage.FormatPercent(p.Coverage.Lines.Pct)
+		}
+		if p.BundleSize != nil {
+			gzip = size.Format(p.BundleSize.GzipSize)
+		}
+		return strings.TrimRight(fmt.Sprintf("  %-*s  %9s  %8s  %10s  %s", width, p.Name, tests, lines, gzip, failed), " ")
+	}
+
+	faint.Printf("  %-*s  %9s  %8s  %10s\n", width, "Package", "Tests", "Lines", "Gzip")
+	for _, p := range r.Packages {
+		fmt.Println(row(p))
+	}
+	bold.Println(row(r.Total))
+	fmt.Println()
+
+	missing := 0
+	for _, p := range r.Packages {
+		if p.Tests == nil {
+			missing++
+		}
+	}
+	if missing > 0 {
+		faint.Printf("💡 %d package(s) have no %s; run vitest with --reporter=json --outputFile=%s\n\n",
+			missing, vitest.ResultsFile, vitest.ResultsFile)
+	}
+}
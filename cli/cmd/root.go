@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+
+	"github.com/kluth/solidum-cli/internal/proc"
 	"github.com/spf13/cobra"
 )
 
@@ -14,8 +18,38 @@ This CLI helps you quickly create new projects, generate components, and manage
 	Version: "0.1.0",
 }
 
+// Execute runs the CLI. Ctrl+C and SIGTERM cancel the command's context and
+// are forwarded to every child process it started.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := proc.HandleSignals(context.Background())
+	defer stop()
+	return rootCmd.ExecuteContext(ctx)
+}
+
+// ExitError is returned by commands that need a specific exit code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	if errors.Is(err, context.Canceled) {
+		return 130
+	}
+	return 1
 }
 
 func init() {
@@ -28,13 +62,26 @@ func init() {
 	rootCmd.AddCommand(devCmd)
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(prerenderCmd)
+	rootCmd.AddCommand(webmlCmd)
 
 	// Code quality
 	rootCmd.AddCommand(typecheckCmd)
 	rootCmd.AddCommand(lintCmd)
 	rootCmd.AddCommand(formatCmd)
+	rootCmd.AddCommand(ciCmd)
+	rootCmd.AddCommand(sizeCmd)
+	rootCmd.AddCommand(reportCmd)
 
 	// Maintenance
 	rootCmd.AddCommand(cleanCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(changesetCmd)
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(packCmd)
 	rootCmd.AddCommand(publishCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(hooksCmd)
 }
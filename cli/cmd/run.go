@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/tasks"
+	"github.com/kluth/solidum-cli/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	runFilter      []string
+	runConcurrency int
+	runNoCache     bool
+	runParallel    bool
+	runDryRun      bool
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run <task...> [-- args]",
+	Short: "Run tasks across workspace packages",
+	Long: `Run one or more tasks across the workspace in dependency order.
+
+Tasks are declared in solidum.json with their command, dependsOn (e.g.
+"^build" runs build in every dependency first), inputs, outputs, env and
+whether they are cacheable. Tasks that are not declared run the package
+script of the same name. Arguments after -- are passed to every task.`,
+	Example: `  solidum run lint typecheck test
+  solidum run build --filter @sldm/ui
+  solidum run test -- --coverage`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runRun,
+}
+
+func init() {
+	runCmd.Flags().StringSliceVarP(&runFilter, "filter", "f", nil, "Only run for matching packages (e.g., @sldm/core, ./packages/*)")
+	runCmd.Flags().IntVarP(&runConcurrency, "concurrency", "c", 0, "Maximum number of tasks to run at once (default: CPU count)")
+	runCmd.Flags().BoolVar(&runNoCache, "no-cache", false, "Run cacheable tasks even when inputs are unchanged")
+	runCmd.Flags().BoolVar(&runParallel, "parallel", false, "Start all tasks at once, ignoring dependsOn (for watch tasks)")
+	runCmd.Flags().BoolVar(&runDryRun, "dry-run", false, "Print the task graph without running it")
+}
+
+func runRun(cmd *cobra.Command, args []string) error {
+	taskNames, extra := args, []string(nil)
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		taskNames, extra = args[:dash], args[dash:]
+	}
+	if len(taskNames) == 0 {
+		return fmt.Errorf("no tasks given")
+	}
+
+	if runDryRun {
+		return printTaskPlan(taskNames, runFilter)
+	}
+
+	return runTasks(cmd.Context(), taskNames, taskRunOptions{
+		filter:      runFilter,
+		args:        extra,
+		concurrency: runConcurrency,
+		parallel:    runParallel,
+		noCache:     runNoCache,
+	})
+}
+
+// taskRunOptions are shared by `solidum run` and the commands built on it
+type taskRunOptions struct {
+	filter      []string
+	args        []string
+	nodeArgs    map[string][]string
+	concurrency int
+	parallel    bool
+	noCache     bool
+}
+
+// loadTaskPlan reads the workspace and config and plans the given tasks
+func loadTaskPlan(taskNames []string, filter []string) (*workspace.Workspace, *config.Config, *tasks.Plan, error) {
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load workspace: %w", err)
+	}
+
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pkgs := ws.Filter(filter...)
+	if len(pkgs) == 0 {
+		return nil, nil, nil, fmt.Errorf("no packages match %s", strings.Join(filter, ", "))
+	}
+
+	plan, err := tasks.NewPlan(ws, cfg, taskNames, pkgs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ws, cfg, plan, nil
+}
+
+// runTasks plans and runs tasks, streaming their output, and prints a
+// summary. It returns an error when any task failed.
+func runTasks(ctx context.Context, taskNames []string, opts taskRunOptions) error {
+	_, err := runTasksSummary(ctx, taskNames, opts)
+	return err
+}
+
+// runTasksSummary is runTasks, also returning each task's result. The
+// summary is nil when the tasks could not be planned.
+func runTasksSummary(ctx context.Context, taskNames []string, opts taskRunOptions) (*tasks.Summary, error) {
+	ws, cfg, plan, err := loadTaskPlan(taskNames, opts.filter)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := opts.concurrency
+	if concurrency == 0 {
+		concurrency = cfg.Concurrency
+	}
+
+	summary := tasks.Run(ctx, ws, plan, tasks.Options{
+		Concurrency: concurrency,
+		Args:        opts.args,
+		NodeArgs:    opts.nodeArgs,
+		Parallel:    opts.parallel,
+		NoCache:     opts.noCache,
+		Stream:      true,
+	})
+
+	printTaskSummary(summary)
+
+	if ctx.Err() != nil {
+		return summary, fmt.Errorf("interrupted: %w", ctx.Err())
+	}
+	if failed := summary.Failed(); len(failed) > 0 {
+		ids := make([]string, len(failed))
+		for i, r := range failed {
+			ids[i] = r.Node.ID()
+		}
+		return summary, fmt.Errorf("%s failed", strings.Join(ids, ", "))
+	}
+	return summary, nil
+}
+
+func printTaskSummary(summary *tasks.Summary) {
+	green := color.New(color.FgGreen)
+	red := color.New(color.FgRed, color.Bold)
+	yellow := color.New(color.FgYellow)
+	faint := color.New(color.Faint)
+
+	fmt.Println()
+	for _, r := range summary.Results {
+		if !r.Node.Requested && r.Status == tasks.Skipped {
+			continue
+		}
+		switch r.Status {
+		case tasks.Success:
+			green.Printf("  ✓ %s", r.Node.ID())
+			faint.Printf("  %s\n", r.Duration.Round(10*time.Millisecond))
+		case tasks.Cached:
+			green.Printf("  ✓ %s", r.Node.ID())
+			faint.Println("  cached")
+		case tasks.Skipped:
+			faint.Printf("  - %s  no %s script\n", r.Node.ID(), r.Node.Task)
+		case tasks.Blocked:
+			yellow.Printf("  ⚠ %s  %v\n", r.Node.ID(), r.Err)
+		case tasks.Failed:
+			red.Printf("  ✗ %s  %v\n", r.Node.ID(), r.Err)
+		}
+	}
+
+	fmt.Printf("\n%d succeeded, %d cached, %d failed (%s)\n\n",
+		summary.Count(tasks.Success), summary.Count(tasks.Cached), summary.Count(tasks.Failed),
+		summary.Duration.Round(10*time.Millisecond))
+}
+
+func printTaskPlan(taskNames []string, filter []string) error {
+	_, _, plan, err := loadTaskPlan(taskNames, filter)
+	if err != nil {
+		return err
+	}
+
+	cyan := color.New(color.FgCyan, color.Bold)
+	faint := color.New(color.Faint)
+
+	cyan.Print("\n📋 Task graph:\n\n")
+	for _, n := range plan.Nodes {
+		fmt.Printf("  %s", n.ID())
+		if len(n.Deps) > 0 {
+			deps := make([]string, len(n.Deps))
+			for i, d := range n.Deps {
+				deps[i] = d.ID()
+			}
+			faint.Printf("  ← %s", strings.Join(deps, ", "))
+		}
+		if !n.HasWork() {
+			faint.Print("  (no script)")
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+	return nil
+}
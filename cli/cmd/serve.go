@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/server"
+	"github.com/kluth/solidum-cli/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	servePort    int
+	serveHost    string
+	serveBase    string
+	serveProxies []string
+	serveNoSPA   bool
+	serveQuiet   bool
+	serveLive    bool
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve [dir]",
+	Short: "Serve a built app locally",
+	Long: `Serve a production build with a native static file server, no Node needed.
+
+Serves dist/ by default (or the current directory when there is no dist/)
+with correct MIME types, precompressed .br/.gz files when the browser
+accepts them, ETags, and a history fallback to index.html so client-side
+routes from @sldm/router survive a reload. Requests for missing files with
+an extension (e.g. /app.js) still return 404.
+
+With --live, the directory is watched and pages reload when files change;
+stylesheet changes are swapped in place without a reload. This works for
+any static example, with no Node tooling.`,
+	Example: `  solidum serve
+  solidum serve docs/dist --base /docs
+  solidum serve --proxy /api=http://localhost:8080
+  solidum serve examples/todo-app --live`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().IntVarP(&servePort, "port", "p", 4173, "Port to listen on")
+	serveCmd.Flags().StringVar(&serveHost, "host", "localhost", "Host to listen on (use 0.0.0.0 to expose on the network)")
+	serveCmd.Flags().StringVar(&serveBase, "base", "", "Public base path, matching the router's basePath (e.g., /docs)")
+	serveCmd.Flags().StringArrayVar(&serveProxies, "proxy", nil, "Proxy a path prefix to a backend (e.g., /api=http://localhost:8080)")
+	serveCmd.Flags().BoolVar(&serveNoSPA, "no-spa", false, "Return 404 for unknown routes instead of index.html")
+	serveCmd.Flags().BoolVarP(&serveQuiet, "quiet", "q", false, "Don't log requests")
+	serveCmd.Flags().BoolVar(&serveLive, "live", false, "Reload the browser when files change")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	faint := color.New(color.Faint)
+
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	} else if fileExists("dist") {
+		dir = "dist"
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	opts := server.Options{
+		Dir:  absDir,
+		Base: serveBase,
+		SPA:  !serveNoSPA,
+	}
+	for _, value := range serveProxies {
+		p, err := server.ParseProxy(value)
+		if err != nil {
+			return err
+		}
+		opts.Proxies = append(opts.Proxies, p)
+	}
+
+	if serveLive {
+		opts.Live = server.NewLiveReload()
+	}
+
+	handler, err := server.New(opts)
+	if err != nil {
+		return err
+	}
+	if !serveQuiet {
+		handler = server.Logger(handler, logServeRequest)
+	}
+
+	addr := net.JoinHostPort(serveHost, strconv.Itoa(servePort))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", addr, err)
+	}
+
+	srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	if opts.Live != nil {
+		srv.RegisterOnShutdown(opts.Live.Close)
+		go watchServeDir(cmd.Context(), absDir, opts.Live)
+	}
+
+	cyan.Printf("\n📁 Serving %s\n", dir)
+	green.Printf("\n  ➜  http://%s%s/\n", addr, server.NormalizeBase(serveBase))
+	for _, p := range opts.Proxies {
+		faint.Printf("  ➜  %s → %s\n", p.Prefix, p.Target)
+	}
+	if opts.SPA {
+		faint.Println("  ➜  unknown routes fall back to index.html")
+	}
+	if opts.Live != nil {
+		faint.Println("  ➜  live reload enabled")
+	}
+	fmt.Print("\nPress Ctrl+C to stop\n\n")
+
+	errs := make(chan error, 1)
+	go func() { errs <- srv.Serve(listener) }()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-cmd.Context().Done():
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return nil
+}
+
+func logServeRequest(method, path string, status int, elapsed time.Duration) {
+	faint := color.New(color.Faint)
+	statusColor := color.New(color.FgGreen)
+	switch {
+	case status >= 500:
+		statusColor = color.New(color.FgRed)
+	case status >= 400:
+		statusColor = color.New(color.FgYellow)
+	case status >= 300:
+		statusColor = color.New(color.FgCyan)
+	}
+	fmt.Printf("%s %s %s\n", statusColor.Sprint(status), method+" "+path, faint.Sprint(elapsed.Round(time.Microsecond)))
+}
+
+// watchServeDir pushes a reload to browsers whenever files in dir change
+func watchServeDir(ctx context.Context, dir string, live *server.LiveReload) {
+	cyan := color.New(color.FgCyan)
+
+	w := &watch.Watcher{
+		Root: dir,
+		Skip: func(rel string, isDir bool) bool {
+			name := filepath.Base(rel)
+			return name == "node_modules" || strings.HasPrefix(name, ".")
+		},
+	}
+	w.Run(ctx, func(changed []string) {
+		what := changed[0]
+		if len(changed) > 1 {
+			what = fmt.Sprintf("%s and %d more", what, len(changed)-1)
+		}
+		action := "reload"
+		if allCSS(changed) {
+			action = "css update"
+		}
+		cyan.Printf("🔄 %s: %s (%d client(s))\n", action, what, live.Clients())
+		live.Notify(changed)
+	})
+}
+
+func allCSS(files []string) bool {
+	for _, f := range files {
+		if filepath.Ext(f) != ".css" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/coverage"
+	"github.com/kluth/solidum-cli/internal/shard"
+	"github.com/kluth/solidum-cli/internal/tasks"
+	"github.com/kluth/solidum-cli/internal/vitest"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// planShard splits the tests of pkgs across the shards and returns the
+// packages this shard runs, with the test files to pass to the test task
+// of each package that only runs some of its files
+func planShard(ws *workspace.Workspace, pkgs []*workspace.Package, spec shard.Spec, by string) ([]string, map[string][]string, error) {
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return nil, nil, err
+	}
+	timings, err := shard.LoadTimings(ws.Path(shard.TimingsFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	plan, err := tasks.NewPlan(ws, cfg, []string{"test"}, pkgs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []shard.Item
+	testFiles := make(map[string][]string)
+	for _, n := range plan.Requested() {
+		if !n.HasWork() {
+			continue
+		}
+		pkg := n.Package
+		// Only vitest can be told which files to run
+		if by == "file" && vitest.Runs(n.Script()) {
+			files, err := shard.FindTests(ws.Path(pkg.Dir))
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(files) > 0 {
+				testFiles[pkg.Name] = files
+				for _, f := range files {
+					items = append(items, shard.Item{Package: pkg.Name, File: f, Duration: timings.File(ws.Rel(ws.Path(pkg.Dir, f)))})
+				}
+				continue
+			}
+		}
+		items = append(items, shard.Item{Package: pkg.Name, Duration: timings.Package(pkg.Name)})
+	}
+
+	whole := make(map[string]bool)
+	assigned := make(map[string][]string)
+	for _, it := range shard.Split(items, spec.Total)[spec.Index-1] {
+		if it.File == "" {
+			whole[it.Package] = true
+		} else {
+			assigned[it.Package] = append(assigned[it.Package], it.File)
+		}
+	}
+
+	var names []string
+	nodeArgs := make(map[string][]string)
+	for name := range whole {
+		names = append(names, name)
+	}
+	for name, files := range assigned {
+		names = append(names, name)
+		// A package whose files all landed here runs as usual
+		if len(files) < len(testFiles[name]) {
+			sort.Strings(files)
+			nodeArgs[name+"#test"] = files
+		}
+	}
+	sort.Strings(names)
+	return names, nodeArgs, nil
+}
+
+// recordTimings saves how long each test file and package took, for
+// balancing later shards
+func recordTimings(ws *workspace.Workspace, results map[string]*vitest.Results) error {
+	if len(results) == 0 {
+		return nil
+	}
+	path := ws.Path(shard.TimingsFile)
+	timings, err := shard.LoadTimings(path)
+	if err != nil {
+		return err
+	}
+	for name, res := range results {
+		if pkg, ok := ws.Lookup(name); ok {
+			timings.Record(name, filepath.ToSlash(pkg.Dir), res, ws.Rel)
+		}
+	}
+	return timings.Save(path)
+}
+
+// writeShardReport saves what the shard's packages left behind, test
+// results and coverage written since the run started, with paths relative
+// to the workspace root, and the tasks that failed without results
+func writeShardReport(ws *workspace.Workspace, spec shard.Spec, names []string, summary *tasks.Summary, since time.Time) error {
+	report := shard.NewReport(spec)
+	since = since.Add(-time.Second)
+	for _, name := range names {
+		pkg, ok := ws.Lookup(name)
+		if !ok {
+			continue
+		}
+		res, err := vitest.Load(ws.Path(pkg.Dir, vitest.ResultsFile))
+		if err == nil {
+			res.Rebase(ws.Rel)
+			report.Packages[name] = res
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+
+		if !testCoverage {
+			continue
+		}
+		cov, ok, err := coverage.LoadPackage(ws.Path(pkg.Dir))
+		if err != nil {
+			return err
+		}
+		if !ok || cov.Final == nil || cov.Modified.Before(since) {
+			continue
+		}
+		files := make(map[string]*coverage.File)
+		for _, f := range cov.Final {
+			f.Path = ws.Rel(f.Path)
+			files[f.Path] = f
+		}
+		if report.Coverage == nil {
+			report.Coverage = make(map[string]map[string]*coverage.File)
+		}
+		report.Coverage[name] = files
+	}
+
+	if summary != nil {
+		for _, r := range summary.Failed() {
+			if _, ok := report.Packages[r.Node.Package.Name]; ok && r.Node.Task == "test" {
+				continue
+			}
+			if report.Failures == nil {
+				report.Failures = make(map[string]string)
+			}
+			report.Failures[r.Node.ID()] = r.Err.Error()
+		}
+	}
+
+	path, err := report.Save(ws.Path(shard.Dir))
+	if err != nil {
+		return err
+	}
+	color.New(color.Faint).Printf("🧩 Shard %s report: %s\n\n", spec, ws.Rel(path))
+	return nil
+}
+
+// runMergeReports combines the shard reports in .solidum/shards as if the
+// tests had run on one machine: it writes each package's results and
+// coverage, records the timings and reports like a full run
+func runMergeReports() error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	reports, err := shard.LoadReports(ws.Path(shard.Dir))
+	if err != nil {
+		return err
+	}
+	if len(reports) == 0 {
+		return fmt.Errorf("no shard reports in %s", shard.Dir)
+	}
+	total := reports[0].Shard.Total
+	for _, r := range reports {
+		if r.Shard.Total != total {
+			return fmt.Errorf("shard reports of different runs in %s: %s and %s", shard.Dir, reports[0].Shard, r.Shard)
+		}
+	}
+	cyan.Printf("\n🧩 Merging %d of %d shard report(s)...\n\n", len(reports), total)
+
+	abs := func(p string) string {
+		if filepath.IsAbs(p) {
+			return p
+		}
+		return ws.Path(filepath.FromSlash(p))
+	}
+
+	results := make(map[string]*vitest.Results)
+	covered := make(map[string]map[string]*coverage.File)
+	failures := &tasks.Summary{}
+	for _, r := range reports {
+		for _, id := range sortedKeys(r.Failures) {
+			name, task, _ := strings.Cut(id, "#")
+			pkg, ok := ws.Lookup(name)
+			if !ok {
+				pkg = &workspace.Package{Name: name}
+			}
+			failures.Results = append(failures.Results, &tasks.Result{
+				Node:   &tasks.Node{Package: pkg, Task: task},
+				Status: tasks.Failed,
+				Err:    fmt.Errorf("%s (shard %s)", r.Failures[id], r.Shard),
+			})
+		}
+		for name, res := range r.Packages {
+			res.Rebase(abs)
+			if existing, ok := results[name]; ok {
+				existing.Files = append(existing.Files, res.Files...)
+			} else {
+				results[name] = res
+			}
+		}
+		for name, files := range r.Coverage {
+			if covered[name] == nil {
+				covered[name] = make(map[string]*coverage.File)
+			}
+			for _, f := range files {
+				f.Path = abs(f.Path)
+				if existing, ok := covered[name][f.Path]; ok {
+					existing.Merge(f)
+				} else {
+					covered[name][f.Path] = f
+				}
+			}
+		}
+	}
+
+	start := time.Now()
+	for _, name := range sortedKeys(results) {
+		pkg, ok := ws.Lookup(name)
+		if !ok {
+			yellow.Printf("⚠️  Skipping %s: not a package of this workspace\n", name)
+			delete(results, name)
+			continue
+		}
+		if err := results[name].Write(ws.Path(pkg.Dir, vitest.ResultsFile)); err != nil {
+			return err
+		}
+	}
+	var coveredNames []string
+	for _, name := range sortedKeys(covered) {
+		pkg, ok := ws.Lookup(name)
+		if !ok {
+			continue
+		}
+		if err := coverage.WriteFinal(ws.Path(pkg.Dir, coverage.FinalFile), covered[name]); err != nil {
+			return err
+		}
+		coveredNames = append(coveredNames, name)
+	}
+
+	for _, r := range failures.Results {
+		color.New(color.FgRed, color.Bold).Printf("  ✗ %s: %v\n", r.Node.ID(), r.Err)
+	}
+	failed := printTestResults(ws, results, failures)
+	if err := recordTimings(ws, results); err != nil {
+		return err
+	}
+	if len(testReport) > 0 {
+		if err := writeTestFindings(ws, results, failures); err != nil {
+			return err
+		}
+	}
+	var coverageErr error
+	if len(coveredNames) > 0 {
+		coverageErr = reportCoverage(coveredNames, start)
+	}
+
+	if missing := shard.Missing(reports, total); len(missing) > 0 {
+		specs := make([]string, len(missing))
+		for i, s := range missing {
+			specs[i] = s.String()
+		}
+		return fmt.Errorf("missing shard reports for %s", strings.Join(specs, ", "))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("tests failed in %s", strings.Join(failed, ", "))
+	}
+	if coverageErr != nil {
+		return coverageErr
+	}
+	green.Print("✅ All tests passed!\n\n")
+	return nil
+}
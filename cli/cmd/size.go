@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/size"
+	"github.com/kluth/solidum-cli/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sizePackages       []string
+	sizeOutput         string
+	sizeJSON           bool
+	sizeUpdateBaseline bool
+)
+
+var sizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "Measure bundle sizes and enforce budgets",
+	Long: `Measure the built size of each package's entry files.
+
+Every entry named by main, module and exports in package.json (or every
+file in dist/ when there are none) is measured together with the files it
+imports from its own package, raw and compressed with gzip and brotli.
+
+Sizes are compared with .solidum/size-baseline.json and checked against the
+budgets in solidum.json:
+
+  {
+    "size": {
+      "compression": "gzip",
+      "budgets": {
+        "@sldm/core": "10 KB",
+        "@sldm/router/dist/index.js": "1.5 KB"
+      }
+    }
+  }
+
+The report is written to .solidum/size.json in the BundleReport format of
+@sldm/dev-reports. Run 'solidum build' first.`,
+	Example: `  solidum size
+  solidum size --update-baseline
+  solidum size -f @sldm/core --json`,
+	RunE: runSize,
+}
+
+func init() {
+	sizeCmd.Flags().StringSliceVarP(&sizePackages, "package", "f", nil, "Only measure matching packages")
+	sizeCmd.Flags().StringVarP(&sizeOutput, "output", "o", size.ReportFile, "Where to write the JSON report")
+	sizeCmd.Flags().BoolVar(&sizeJSON, "json", false, "Print the JSON report instead of a table")
+	sizeCmd.Flags().BoolVar(&sizeUpdateBaseline, "update-baseline", false, "Save the sizes as the new baseline")
+}
+
+func runSize(cmd *cobra.Command, args []string) error {
+	green := color.New(color.FgGreen, color.Bold)
+	red := color.New(color.FgRed, color.Bold)
+	yellow := color.New(color.FgYellow)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return err
+	}
+	settings := cfg.Size
+	if settings == nil {
+		settings = &config.Size{}
+	}
+
+	pkgs := ws.Filter(sizePackages...)
+	if len(pkgs) == 0 {
+		return fmt.Errorf("no packages match %s", strings.Join(sizePackages, ", "))
+	}
+
+	name := "workspace"
+	if ws.RootPackage != nil && ws.RootPackage.Name != "" {
+		name = ws.RootPackage.Name
+	}
+	report, err := size.Measure(ws.Root, name, pkgs)
+	if err != nil {
+		return err
+	}
+	if len(report.Bundles) == 0 {
+		return fmt.Errorf("no built entry files found; run 'solidum build' first")
+	}
+
+	baselinePath := ws.Path(size.BaselineFile)
+	baseline, err := size.Load(baselinePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if baseline != nil {
+		report.Compare(baseline)
+	}
+
+	budgets, err := size.CheckBudgets(report, settings.Budgets, settings.Compression)
+	if err != nil {
+		return err
+	}
+
+	if err := report.Write(ws.Path(sizeOutput)); err != nil {
+		return err
+	}
+
+	if sizeJSON {
+		data, err := os.ReadFile(ws.Path(sizeOutput))
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(data)
+	} else {
+		printSizeReport(report, baseline, settings.Compression)
+		printBudgets(budgets, settings.Compression)
+	}
+
+	if sizeUpdateBaseline {
+		if err := report.Write(baselinePath); err != nil {
+			return err
+		}
+		if !sizeJSON {
+			green.Printf("📌 Baseline saved to %s\n\n", size.BaselineFile)
+		}
+	} else if baseline == nil && !sizeJSON {
+		yellow.Printf("💡 No baseline yet; run 'solidum size --update-baseline' to record one\n\n")
+	}
+
+	var exceeded []string
+	for _, b := range budgets {
+		if b.Exceeded() {
+			exceeded = append(exceeded, b.Key)
+		}
+	}
+	if len(exceeded) > 0 {
+		if !sizeJSON {
+			red.Printf("❌ Size budget exceeded: %s\n\n", strings.Join(exceeded, ", "))
+		}
+		return fmt.Errorf("size budget exceeded for %s", strings.Join(exceeded, ", "))
+	}
+	return nil
+}
+
+func printSizeReport(report *size.Report, baseline *size.Report, compression string) {
+	cyan := color.New(color.FgCyan, color.Bold)
+	faint := color.New(color.Faint)
+	bold := color.New(color.Bold)
+
+	width := len("Total")
+	for _, b := range report.Bundles {
+		if len(b.File) > width {
+			width = len(b.File)
+		}
+	}
+
+	cyan.Print("\n📦 Bundle sizes\n\n")
+	faint.Printf("  %-*s  %10s  %10s  %10s  %s\n", width, "Entry", "Raw", "Gzip", "Brotli", "Change")
+	for _, b := range report.Bundles {
+		change := ""
+		if baseline != nil {
+			change = sizeChange(b, baseline.Lookup(b.File), compression)
+		}
+		fmt.Printf("  %-*s  %10s  %10s  %10s  %s\n", width, b.File,
+			size.Format(b.Size), size.Format(b.GzipSize), size.Format(b.BrotliSize), change)
+	}
+
+	total := &size.Bundle{Size: report.TotalSize, GzipSize: report.TotalGzipSize, BrotliSize: report.TotalBrotliSize}
+	change := ""
+	if baseline != nil {
+		change = sizeChange(total, &size.Bundle{Size: baseline.TotalSize, GzipSize: baseline.TotalGzipSize, BrotliSize: baseline.TotalBrotliSize}, compression)
+	}
+	bold.Printf("  %-*s  %10s  %10s  %10s  ", width, "Total", size.Format(total.Size), size.Format(total.GzipSize), size.Format(total.BrotliSize))
+	fmt.Println(change)
+	fmt.Println()
+}
+
+// sizeChange describes the difference from the baseline in the budgeted
+// compression
+func sizeChange(current, previous *size.Bundle, compression string) string {
+	if previous == nil {
+		return color.New(color.FgCyan).Sprint("new")
+	}
+	diff := size.Of(current, compression) - size.Of(previous, compression)
+	switch {
+	case diff > 0:
+		return color.New(color.FgRed).Sprintf("+%s", size.Format(diff))
+	case diff < 0:
+		return color.New(color.FgGreen).Sprintf("-%s", size.Format(-diff))
+	default:
+		return color.New(color.Faint).Sprint("=")
+	}
+}
+
+func printBudgets(budgets []size.Budget, compression string) {
+	if len(budgets) == 0 {
+		return
+	}
+	if compression == "" {
+		compression = size.Gzip
+	}
+
+	color.New(color.FgCyan, color.Bold).Printf("💰 Budgets (%s)\n\n", compression)
+	for _, b := range budgets {
+		switch {
+		case b.Missing:
+			color.New(color.FgYellow).Printf("  ⚠️  %s: not found in the build\n", b.Key)
+		case b.Exceeded():
+			color.New(color.FgRed).Printf("  ✗ %s: %s > %s\n", b.Key, size.Format(b.Actual), size.Format(b.Limit))
+		default:
+			fmt.Printf("  ✓ %s: %s ≤ %s\n", b.Key, size.Format(b.Actual), size.Format(b.Limit))
+		}
+	}
+	fmt.Println()
+}
@@ -1,11 +1,24 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/coverage"
+	"github.com/kluth/solidum-cli/internal/findings"
+	"github.com/kluth/solidum-cli/internal/proc"
+	"github.com/kluth/solidum-cli/internal/shard"
+	"github.com/kluth/solidum-cli/internal/tasks"
+	"github.com/kluth/solidum-cli/internal/vitest"
+	"github.com/kluth/solidum-cli/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +29,11 @@ var (
 	testPackage  string
 	testCI       bool
 	testParallel bool
+	testRetries  int
+	testReport   []string
+	testShard    string
+	testShardBy  string
+	testMerge    bool
 )
 
 var testCmd = &cobra.Command{
@@ -34,14 +52,35 @@ func init() {
 	testCmd.Flags().StringVarP(&testPackage, "package", "f", "", "Test specific package")
 	testCmd.Flags().BoolVar(&testCI, "ci", false, "Run in CI mode with verbose output")
 	testCmd.Flags().BoolVarP(&testParallel, "parallel", "p", false, "Run tests in parallel (monorepo)")
+	testCmd.Flags().IntVar(&testRetries, "retries", 0, "Rerun failed test files up to N times and flag tests that pass as flaky")
+	testCmd.Flags().StringSliceVar(&testReport, "report", nil, "Also write results as junit, sarif and/or annotations")
+	testCmd.Flags().StringVar(&testShard, "shard", "", "Run only shard i/n of the tests, e.g. 2/4")
+	testCmd.Flags().StringVar(&testShardBy, "shard-by", "file", "Split shards by test file or by package")
+	testCmd.Flags().BoolVar(&testMerge, "merge-reports", false, "Merge the shard reports in .solidum/shards instead of running tests")
 }
 
 func runTest(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
 	cyan := color.New(color.FgCyan, color.Bold)
 	green := color.New(color.FgGreen, color.Bold)
 	yellow := color.New(color.FgYellow)
 
-	isMonorepo := fileExists("pnpm-workspace.yaml")
+	if err := checkReportFormats(testReport); err != nil {
+		return err
+	}
+	if testMerge {
+		return runMergeReports()
+	}
+	var spec shard.Spec
+	if testShard != "" {
+		var err error
+		if spec, err = shard.ParseSpec(testShard); err != nil {
+			return err
+		}
+		if testShardBy != "file" && testShardBy != "package" {
+			return fmt.Errorf("unknown --shard-by %q, expected file or package", testShardBy)
+		}
+	}
 
 	if testWatch {
 		yellow.Println("\n👀 Running in watch mode...")
@@ -49,65 +88,491 @@ func runTest(cmd *cobra.Command, args []string) error {
 
 	cyan.Println("\n🧪 Running tests...")
 
-	var testCommand string
-	var testArgs []string
-
-	if isMonorepo {
-		if testPackage != "" {
-			// Test specific package
-			cyan.Printf("\n📦 Testing package: %s\n\n", testPackage)
-			testCommand = "pnpm"
-			testArgs = []string{"--filter", testPackage, "test"}
-		} else if testParallel {
-			// Run tests in parallel across packages
-			cyan.Println("\n⚡ Running tests in parallel across all packages...\n")
-			testCommand = "pnpm"
-			testArgs = []string{"-r", "--parallel", "test"}
-		} else if testCI {
-			// CI mode - sequential with verbose output
-			cyan.Println("\n🤖 Running tests in CI mode...\n")
-			testCommand = "pnpm"
-			testArgs = []string{"-r", "--workspace-concurrency=1", "test", "--reporter=verbose"}
-		} else {
-			// Regular sequential test
-			testCommand = "pnpm"
-			testArgs = []string{"-r", "--workspace-concurrency=1", "test"}
-		}
-	} else {
-		// Single project
-		testCommand = "pnpm"
-		testArgs = []string{"test"}
+	opts := taskRunOptions{concurrency: 1}
+	if testPackage != "" {
+		cyan.Printf("\n📦 Testing package: %s\n", testPackage)
+		opts.filter = []string{testPackage}
+	}
+	if testParallel {
+		cyan.Println("\n⚡ Running tests in parallel across all packages...")
+		opts.concurrency = 0
+	}
+	if testCI {
+		cyan.Println("\n🤖 Running tests in CI mode...")
 	}
 
-	// Add flags
-	if testWatch && !testCI {
-		testArgs = append(testArgs, "--watch")
+	// A single run that ends, as opposed to watch mode or the UI. Its
+	// results are collected from vitest's JSON reporter.
+	batch := !(testWatch && !testCI) && !testUI
+	if (len(testReport) > 0 || testShard != "") && !batch {
+		return fmt.Errorf("--report and --shard need a single run, not watch mode or the UI")
+	}
+	console := "default"
+	if testCI {
+		console = "verbose"
+	}
+	if !batch && testCI {
+		opts.args = append(opts.args, "--reporter=verbose")
 	}
 
+	if testWatch && !testCI {
+		opts.args = append(opts.args, "--watch")
+		opts.parallel = true
+	}
 	if testCoverage {
-		testArgs = append(testArgs, "--coverage")
+		opts.args = append(opts.args, "--coverage")
 	}
-
 	if testUI {
-		testArgs = append(testArgs, "--ui")
+		opts.args = append(opts.args, "--ui")
 	}
 
 	// Add any additional args from command line
-	if len(args) > 0 {
-		testArgs = append(testArgs, args...)
+	opts.args = append(opts.args, args...)
+
+	var ws *workspace.Workspace
+	if batch {
+		var err error
+		if ws, err = workspace.Load("."); err != nil {
+			return fmt.Errorf("failed to load workspace: %w", err)
+		}
+		if testShard != "" {
+			names, nodeArgs, err := planShard(ws, ws.Filter(opts.filter...), spec, testShardBy)
+			if err != nil {
+				return err
+			}
+			cyan.Printf("\n🧩 Shard %s: %d package(s)\n", spec, len(names))
+			if len(names) == 0 {
+				yellow.Print("\n⚠️  Nothing to run in this shard\n\n")
+				return writeShardReport(ws, spec, nil, nil, time.Now())
+			}
+			opts.filter, opts.nodeArgs = names, nodeArgs
+		}
+		if opts.nodeArgs == nil {
+			opts.nodeArgs = make(map[string][]string)
+		}
+		// Only vitest takes the reporter options; other test scripts would
+		// choke on them or ignore them
+		runners, err := testScripts(ws, ws.Filter(opts.filter...))
+		if err != nil {
+			return err
+		}
+		for _, id := range sortedKeys(runners) {
+			if !vitest.Runs(runners[id]) {
+				yellow.Printf("\nℹ️  No structured results for %s: its test script doesn't run vitest\n", strings.TrimSuffix(id, "#test"))
+				continue
+			}
+			opts.nodeArgs[id] = append(vitest.ReporterArgs(console, vitest.ResultsFile), opts.nodeArgs[id]...)
+		}
+		// Results left by an earlier run must not pass for this one's
+		for _, pkg := range ws.Filter(opts.filter...) {
+			os.Remove(ws.Path(pkg.Dir, vitest.ResultsFile))
+		}
+	}
+
+	start := time.Now()
+	summary, err := runTasksSummary(ctx, []string{"test"}, opts)
+	if err != nil && (summary == nil || ctx.Err() != nil) {
+		return err
 	}
 
-	// Execute tests
-	cmdExec := exec.Command(testCommand, testArgs...)
-	cmdExec.Stdout = os.Stdout
-	cmdExec.Stderr = os.Stderr
-	cmdExec.Stdin = os.Stdin
-	cmdExec.Env = os.Environ()
+	var coverageErr error
+	if testCoverage && batch {
+		coverageErr = reportCoverage(opts.filter, start)
+	}
 
-	if err := cmdExec.Run(); err != nil {
+	if batch {
+		results, err := loadTestResults(ws, opts.filter)
+		if err != nil {
+			return err
+		}
+		var flaky []*vitest.Flaky
+		if testRetries > 0 {
+			if flaky, err = retryFailedTests(ctx, ws, results, console, args); err != nil {
+				return err
+			}
+		}
+		failed := printTestResults(ws, results, summary)
+		if err := printFlaky(ws, flaky); err != nil {
+			return err
+		}
+		if err := recordTimings(ws, results); err != nil {
+			return err
+		}
+		if len(testReport) > 0 {
+			if err := writeTestFindings(ws, results, summary); err != nil {
+				return err
+			}
+		}
+		if testShard != "" {
+			if err := writeShardReport(ws, spec, opts.filter, summary, start); err != nil {
+				return err
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("tests failed in %s", strings.Join(failed, ", "))
+		}
+	} else if err != nil {
 		return fmt.Errorf("tests failed: %w", err)
 	}
+	if coverageErr != nil {
+		return coverageErr
+	}
 
-	green.Println("\n✅ All tests passed!\n")
+	green.Print("✅ All tests passed!\n\n")
 	return nil
 }
+
+// testScripts returns the command the test task of each of pkgs that has one
+// runs, by task ID
+func testScripts(ws *workspace.Workspace, pkgs []*workspace.Package) (map[string]string, error) {
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := tasks.NewPlan(ws, cfg, []string{"test"}, pkgs)
+	if err != nil {
+		return nil, err
+	}
+	scripts := make(map[string]string)
+	for _, n := range plan.Requested() {
+		if n.HasWork() {
+			scripts[n.ID()] = n.Script()
+		}
+	}
+	return scripts, nil
+}
+
+// loadTestResults reads the JSON results each package's test run wrote, by
+// package name. Packages whose tests don't run vitest have none.
+func loadTestResults(ws *workspace.Workspace, filter []string) (map[string]*vitest.Results, error) {
+	results := make(map[string]*vitest.Results)
+	for _, pkg := range ws.Filter(filter...) {
+		r, err := vitest.Load(ws.Path(pkg.Dir, vitest.ResultsFile))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		results[pkg.Name] = r
+	}
+	return results, nil
+}
+
+// retryFailedTests reruns only the failed test files of each package, up to
+// testRetries times, and returns the tests that passed on a retry. The merged
+// results are written back.
+func retryFailedTests(ctx context.Context, ws *workspace.Workspace, results map[string]*vitest.Results, console string, extra []string) ([]*vitest.Flaky, error) {
+	yellow := color.New(color.FgYellow, color.Bold)
+
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return nil, err
+	}
+
+	var flaky []*vitest.Flaky
+	for attempt := 1; attempt <= testRetries; attempt++ {
+		for _, name := range sortedKeys(results) {
+			res := results[name]
+			failed := res.Failed()
+			if len(failed) == 0 {
+				continue
+			}
+			pkg, _ := ws.Lookup(name)
+			dir := ws.Path(pkg.Dir)
+
+			var files []string
+			for _, f := range failed {
+				if rel, err := filepath.Rel(dir, f.Name); err == nil {
+					files = append(files, filepath.ToSlash(rel))
+				}
+			}
+			yellow.Printf("\n🔁 Retrying %d failed file(s) in %s (attempt %d/%d)\n\n", len(files), name, attempt, testRetries)
+
+			plan, err := tasks.NewPlan(ws, cfg, []string{"test"}, []*workspace.Package{pkg})
+			if err != nil {
+				return nil, err
+			}
+			node := plan.Requested()[0]
+			resultsFile := filepath.Join(dir, vitest.ResultsFile)
+			os.Remove(resultsFile)
+
+			args := append(vitest.ReporterArgs(console, vitest.ResultsFile), extra...)
+			c := tasks.Command(ws, node, append(args, files...))
+			c.Stdout = os.Stdout
+			c.Stderr = os.Stderr
+			// Still failing is not an error here; the results tell
+			proc.Run(ctx, node.ID(), c)
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("interrupted: %w", ctx.Err())
+			}
+
+			rerun, err := vitest.Load(resultsFile)
+			if err != nil {
+				color.New(color.FgYellow).Printf("⚠️  No results from the retry in %s: %v\n", name, err)
+				continue
+			}
+			for _, t := range res.Replace(rerun) {
+				flaky = append(flaky, &vitest.Flaky{Package: name, File: ws.Rel(t.File), Test: t.FullName()})
+			}
+			if err := res.Write(resultsFile); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return flaky, nil
+}
+
+// printFlaky lists the tests that passed only on retry and records them in
+// .solidum/flaky.json
+func printFlaky(ws *workspace.Workspace, flaky []*vitest.Flaky) error {
+	if len(flaky) == 0 {
+		return nil
+	}
+	yellow := color.New(color.FgYellow, color.Bold)
+	yellow.Print("⚠️  Flaky tests (failed, then passed on retry):\n")
+	for _, f := range flaky {
+		color.New(color.FgYellow).Printf("  ~ %s › %s › %s\n", f.Package, f.File, f.Test)
+	}
+	if err := vitest.RecordFlaky(ws.Path(vitest.FlakyFile), flaky); err != nil {
+		return err
+	}
+	color.New(color.Faint).Printf("  Recorded in %s\n\n", vitest.FlakyFile)
+	return nil
+}
+
+// writeTestFindings writes the test results in the --report formats
+func writeTestFindings(ws *workspace.Workspace, results map[string]*vitest.Results, summary *tasks.Summary) error {
+	r := findings.New("vitest")
+	parsed := make(map[string]bool)
+	for _, name := range sortedKeys(results) {
+		r.AddVitest(name, results[name], ws.Rel)
+		parsed[name+"#test"] = true
+	}
+	addTaskFailures(r, summary, parsed)
+	return writeFindings(ws, "test", r, testReport)
+}
+
+// printTestResults prints the totals and every failure across packages and
+// returns the packages that failed. A package without JSON results failed
+// when its test task did.
+func printTestResults(ws *workspace.Workspace, results map[string]*vitest.Results, summary *tasks.Summary) []string {
+	red := color.New(color.FgRed, color.Bold)
+	faint := color.New(color.Faint)
+	cyan := color.New(color.FgCyan, color.Bold)
+
+	failedPkgs := make(map[string]bool)
+	for _, r := range summary.Failed() {
+		if _, ok := results[r.Node.Package.Name]; !ok || r.Node.Task != "test" {
+			failedPkgs[r.Node.Package.Name] = true
+		}
+	}
+
+	var passed, failed, skipped int
+	var duration time.Duration
+	var failures []string
+	for _, name := range sortedKeys(results) {
+		res := results[name]
+		p, f, s := res.Counts()
+		passed, failed, skipped = passed+p, failed+f, skipped+s
+		duration += res.Duration()
+		for _, file := range res.Failed() {
+			failedPkgs[name] = true
+			failures = append(failures, name)
+			red.Printf("\n  ✗ %s › %s\n", name, ws.Rel(file.Name))
+			if file.Error != "" {
+				printTestError(file.Error)
+			}
+			for _, t := range file.Tests {
+				if t.Status == vitest.Failed {
+					color.New(color.FgRed).Printf("    ✗ %s\n", t.FullName())
+					printTestError(t.Error)
+				}
+			}
+		}
+	}
+
+	if len(results) > 0 {
+		if len(failures) > 0 {
+			fmt.Println()
+		}
+		cyan.Print("🧪 Tests: ")
+		fmt.Printf("%d passed", passed)
+		if failed > 0 {
+			red.Printf(", %d failed", failed)
+		}
+		if skipped > 0 {
+			faint.Printf(", %d skipped", skipped)
+		}
+		faint.Printf("  (%d package(s), %s)\n\n", len(results), duration.Round(time.Millisecond))
+	}
+
+	var names []string
+	for name := range failedPkgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printTestError prints the first lines of a failure message, indented
+func printTestError(msg string) {
+	lines := strings.Split(strings.TrimSpace(msg), "\n")
+	if len(lines) > 6 {
+		lines = append(lines[:6], "…")
+	}
+	for _, line := range lines {
+		color.New(color.Faint).Printf("      %s\n", strings.TrimSpace(line))
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// reportCoverage merges the coverage each package's test run wrote after
+// since into workspace-wide lcov and Cobertura reports, prints it per
+// package and checks the thresholds in solidum.json
+func reportCoverage(filter []string, since time.Time) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	yellow := color.New(color.FgYellow)
+	red := color.New(color.FgRed, color.Bold)
+	green := color.New(color.FgGreen)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return err
+	}
+	settings := cfg.Coverage
+	if settings == nil {
+		settings = &config.Coverage{}
+	}
+
+	type row struct {
+		name    string
+		summary coverage.Summary
+	}
+	var rows []row
+	var summaryOnly []string
+	merged := coverage.NewMerged()
+	var total coverage.Summary
+
+	// Filesystem timestamps may be coarser than the clock
+	since = since.Add(-time.Second)
+	for _, pkg := range ws.Filter(filter...) {
+		cov, ok, err := coverage.LoadPackage(ws.Path(pkg.Dir))
+		if err != nil {
+			return err
+		}
+		if !ok || cov.Modified.Before(since) {
+			continue
+		}
+		rows = append(rows, row{pkg.Name, cov.Total})
+		if cov.Final != nil {
+			merged.Add(pkg.Name, cov.Final)
+		} else {
+			summaryOnly = append(summaryOnly, pkg.Name)
+			total = total.Add(cov.Total)
+		}
+	}
+	if len(rows) == 0 {
+		yellow.Printf("⚠️  No coverage was written; make sure the test scripts run vitest with the json or lcov coverage reporter\n\n")
+		return nil
+	}
+	total = total.Add(merged.Summary())
+
+	width := len("Package")
+	for _, r := range rows {
+		if len(r.name) > width {
+			width = len(r.name)
+		}
+	}
+	cyan.Print("🎯 Coverage\n\n")
+	color.New(color.Faint).Printf("  %-*s  %8s  %10s  %9s  %8s\n", width, "Package", "Lines", "Statements", "Functions", "Branches")
+	line := func(name string, s coverage.Summary) string {
+		return fmt.Sprintf("  %-*s  %8s  %10s  %9s  %8s", width, name, coverage.FormatPercent(s.Lines.Pct),
+			coverage.FormatPercent(s.Statements.Pct), coverage.FormatPercent(s.Functions.Pct), coverage.FormatPercent(s.Branches.Pct))
+	}
+	for _, r := range rows {
+		fmt.Println(line(r.name, r.summary))
+	}
+	color.New(color.Bold).Println(line("Total", total))
+	fmt.Println()
+
+	if len(merged.Files) > 0 {
+		dir := settings.Dir
+		if dir == "" {
+			dir = "coverage"
+		}
+		lcov, cobertura, err := writeMergedCoverage(ws.Root, ws.Path(dir), merged)
+		if err != nil {
+			return err
+		}
+		green.Printf("📄 Merged coverage written to %s and %s\n", coverage.Rel(ws.Root, lcov), coverage.Rel(ws.Root, cobertura))
+		if len(summaryOnly) > 0 {
+			yellow.Printf("   %s only wrote a coverage summary and are not included\n", strings.Join(summaryOnly, ", "))
+		}
+		fmt.Println()
+	}
+
+	breaches, err := coverage.CheckThresholds("", total, settings.Thresholds)
+	if err != nil {
+		return err
+	}
+	for _, r := range rows {
+		b, err := coverage.CheckThresholds(r.name, r.summary, settings.Packages[r.name])
+		if err != nil {
+			return err
+		}
+		breaches = append(breaches, b...)
+	}
+	if len(breaches) == 0 {
+		return nil
+	}
+
+	red.Print("❌ Coverage below threshold:\n")
+	for _, b := range breaches {
+		scope := "workspace"
+		if b.Scope != "" {
+			scope = b.Scope
+		}
+		red.Printf("  ✗ %s %s: %s < %s\n", scope, b.Metric, coverage.FormatPercent(b.Actual), coverage.FormatPercent(b.Min))
+	}
+	fmt.Println()
+	return fmt.Errorf("coverage below threshold in %d case(s)", len(breaches))
+}
+
+// writeMergedCoverage writes lcov.info and cobertura-coverage.xml into dir
+// with paths relative to root
+func writeMergedCoverage(root, dir string, merged *coverage.Merged) (lcov, cobertura string, err error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+
+	var buf bytes.Buffer
+	rel := func(path string) string { return coverage.Rel(root, path) }
+	if err := coverage.WriteLcov(&buf, merged.Sorted(), rel); err != nil {
+		return "", "", err
+	}
+	lcov = filepath.Join(dir, "lcov.info")
+	if err := os.WriteFile(lcov, buf.Bytes(), 0644); err != nil {
+		return "", "", err
+	}
+
+	buf.Reset()
+	if err := coverage.WriteCobertura(&buf, merged, root); err != nil {
+		return "", "", err
+	}
+	cobertura = filepath.Join(dir, "cobertura-coverage.xml")
+	return lcov, cobertura, os.WriteFile(cobertura, buf.Bytes(), 0644)
+}
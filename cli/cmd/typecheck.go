@@ -1,17 +1,31 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/codemod"
+	"github.com/kluth/solidum-cli/internal/findings"
+	"github.com/kluth/solidum-cli/internal/proc"
+	"github.com/kluth/solidum-cli/internal/tasks"
+	"github.com/kluth/solidum-cli/internal/tsproject"
+	"github.com/kluth/solidum-cli/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
 var (
-	typecheckWatch   bool
-	typecheckPackage string
+	typecheckWatch     bool
+	typecheckPackage   string
+	typecheckReport    []string
+	typecheckBuild     bool
+	typecheckCheckRefs bool
+	typecheckForce     bool
 )
 
 var typecheckCmd = &cobra.Command{
@@ -19,13 +33,21 @@ var typecheckCmd = &cobra.Command{
 	Short: "Run TypeScript type checking",
 	Long: `Run TypeScript type checking across the project.
 
-Validates types without emitting files for fast feedback.`,
+Validates types without emitting files for fast feedback.
+
+With --build, the references in each package's tsconfig.json are derived
+from the workspace graph and everything is checked in a single incremental
+tsc -b run.`,
 	RunE: runTypecheck,
 }
 
 func init() {
 	typecheckCmd.Flags().BoolVarP(&typecheckWatch, "watch", "w", false, "Watch mode - recheck on changes")
 	typecheckCmd.Flags().StringVarP(&typecheckPackage, "package", "f", "", "Typecheck specific package")
+	typecheckCmd.Flags().StringSliceVar(&typecheckReport, "report", nil, "Also write type errors as junit, sarif and/or annotations")
+	typecheckCmd.Flags().BoolVarP(&typecheckBuild, "build", "b", false, "Sync project references and check everything in one tsc -b run")
+	typecheckCmd.Flags().BoolVar(&typecheckCheckRefs, "check-references", false, "Only verify that project references match the workspace graph")
+	typecheckCmd.Flags().BoolVar(&typecheckForce, "force", false, "Rebuild every project, ignoring .tsbuildinfo (with --build)")
 }
 
 func runTypecheck(cmd *cobra.Command, args []string) error {
@@ -33,7 +55,15 @@ func runTypecheck(cmd *cobra.Command, args []string) error {
 	green := color.New(color.FgGreen, color.Bold)
 	yellow := color.New(color.FgYellow)
 
-	isMonorepo := fileExists("pnpm-workspace.yaml")
+	if err := checkReportFormats(typecheckReport); err != nil {
+		return err
+	}
+	if len(typecheckReport) > 0 && typecheckWatch {
+		return fmt.Errorf("--report cannot be combined with --watch")
+	}
+	if typecheckCheckRefs {
+		return runCheckReferences()
+	}
 
 	if typecheckWatch {
 		yellow.Println("\n👀 Running in watch mode...")
@@ -41,40 +71,289 @@ func runTypecheck(cmd *cobra.Command, args []string) error {
 
 	cyan.Println("\n🔍 Type checking...")
 
-	var checkCommand string
-	var checkArgs []string
+	opts := taskRunOptions{}
+	if typecheckPackage != "" {
+		cyan.Printf("\n📦 Type checking package: %s\n", typecheckPackage)
+		opts.filter = []string{typecheckPackage}
+	}
+	if typecheckWatch {
+		opts.args = []string{"--watch"}
+		opts.parallel = true
+	}
+
+	if typecheckBuild {
+		return runTypecheckBuild(cmd, opts.filter)
+	}
+	if len(typecheckReport) > 0 {
+		return runTypecheckReport(cmd, opts)
+	}
+
+	if err := runTasks(cmd.Context(), []string{"typecheck"}, opts); err != nil {
+		return fmt.Errorf("type checking failed: %w", err)
+	}
+
+	green.Print("✅ No type errors found!\n\n")
+	return nil
+}
+
+// runTypecheckReport parses the diagnostics tsc prints and writes them in
+// the --report formats
+func runTypecheckReport(cmd *cobra.Command, opts taskRunOptions) error {
+	green := color.New(color.FgGreen, color.Bold)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+
+	// Plain file(line,col) lines, even on a terminal
+	opts.args = append(opts.args, "--pretty", "false")
+	summary, runErr := runTasksSummary(cmd.Context(), []string{"typecheck"}, opts)
+	if summary == nil || cmd.Context().Err() != nil {
+		return fmt.Errorf("type checking failed: %w", runErr)
+	}
+
+	r := findings.New("tsc")
+	parsed := make(map[string]bool)
+	for _, res := range summary.Results {
+		if !res.Node.Requested || res.Node.Task != "typecheck" || res.Status == tasks.Skipped || res.Status == tasks.Blocked {
+			continue
+		}
+		r.AddTsc(res.Node.Package.Name, ws.Path(res.Node.Package.Dir), res.Output, res.Status == tasks.Failed, ws.Rel)
+		parsed[res.Node.ID()] = true
+	}
+	addTaskFailures(r, summary, parsed)
+
+	if err := writeFindings(ws, "typecheck", r, typecheckReport); err != nil {
+		return err
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("type checking failed: %w", runErr)
+	}
+	green.Print("✅ No type errors found!\n\n")
+	return nil
+}
+
+// runCheckReferences shows how the project references differ from the
+// workspace graph and fails when they do
+func runCheckReferences() error {
+	green := color.New(color.FgGreen, color.Bold)
+	red := color.New(color.FgRed)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	projects, err := tsproject.Load(ws)
+	if err != nil {
+		return err
+	}
 
-	if isMonorepo {
-		if typecheckPackage != "" {
-			// Typecheck specific package
-			cyan.Printf("\n📦 Type checking package: %s\n\n", typecheckPackage)
-			checkCommand = "pnpm"
-			checkArgs = []string{"--filter", typecheckPackage, "typecheck"}
-		} else {
-			// Typecheck all packages
-			checkCommand = "pnpm"
-			checkArgs = []string{"-r", "typecheck"}
+	fmt.Println()
+	for _, p := range projects {
+		data, changed, err := p.Synced()
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", ws.Rel(p.Path), err)
+		}
+		if changed {
+			fmt.Print(codemod.UnifiedDiff(ws.Rel(p.Path), string(p.Original()), string(data)))
 		}
-	} else {
-		// Single project typecheck
-		checkCommand = "pnpm"
-		checkArgs = []string{"run", "typecheck"}
 	}
 
+	problems := tsproject.Check(projects)
+	for _, problem := range problems {
+		red.Printf("  ✗ %s %s\n", problem.Project.Package.Name, problem.Message)
+	}
+	if len(problems) > 0 {
+		fmt.Println()
+		return fmt.Errorf("%d problem(s) with project references; `solidum typecheck --build` updates the references", len(problems))
+	}
+
+	green.Printf("✅ Project references of %d package(s) are up to date\n\n", len(projects))
+	return nil
+}
+
+// runTypecheckBuild syncs the project references and checks the selected
+// packages, and what they reference, in a single tsc -b run. Diagnostics
+// are de-duplicated and grouped by package.
+func runTypecheckBuild(cmd *cobra.Command, filter []string) error {
+	green := color.New(color.FgGreen, color.Bold)
+	yellow := color.New(color.FgYellow)
+	red := color.New(color.FgRed, color.Bold)
+	faint := color.New(color.Faint)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	projects, err := tsproject.Load(ws)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	for _, p := range projects {
+		changed, err := p.Sync()
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", ws.Rel(p.Path), err)
+		}
+		if changed {
+			faint.Printf("🔗 Updated references in %s\n", ws.Rel(p.Path))
+		}
+	}
+	for _, problem := range tsproject.Check(projects) {
+		yellow.Printf("⚠️  %s %s\n", problem.Project.Package.Name, problem.Message)
+	}
+
+	selected := make(map[string]bool)
+	for _, pkg := range ws.Filter(filter...) {
+		selected[pkg.Name] = true
+	}
+	var build []*tsproject.Project
+	for _, p := range projects {
+		if selected[p.Package.Name] {
+			build = append(build, p)
+		}
+	}
+	if len(build) == 0 {
+		return fmt.Errorf("no package with a %s to check", tsproject.ConfigFile)
+	}
+
+	// tsc -b refuses to build a reference that isn't composite (TS6306),
+	// so don't start it when one of the selected packages needs one
+	edges := ws.DependencyEdges()
+	referenced := make(map[string]bool)
+	var queue []string
+	for _, p := range build {
+		queue = append(queue, p.Package.Name)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dep := range edges[name] {
+			if !referenced[dep] {
+				referenced[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+	var notComposite []string
+	for _, p := range projects {
+		if referenced[p.Package.Name] && !p.Composite {
+			notComposite = append(notComposite, ws.Rel(p.Path))
+		}
+	}
+	if len(notComposite) > 0 {
+		fmt.Println()
+		for _, path := range notComposite {
+			red.Printf("  ✗ %s is referenced but does not set compilerOptions.composite\n", path)
+		}
+		fmt.Println()
+		return fmt.Errorf("set \"composite\": true in the compilerOptions of %d referenced project(s) before running tsc -b", len(notComposite))
+	}
+	if err := tsproject.WriteSolution(ws.Path(tsproject.SolutionFile), build); err != nil {
+		return err
+	}
+
+	args := []string{"exec", "tsc", "-b", tsproject.SolutionFile}
+	if typecheckForce {
+		args = append(args, "--force")
+	}
+	c := exec.Command("pnpm", args...)
+	c.Dir = ws.Root
+	c.Stdin = os.Stdin
 	if typecheckWatch {
-		checkArgs = append(checkArgs, "--watch")
+		c.Args = append(c.Args, "--watch")
+		c.Stdout, c.Stderr = os.Stdout, os.Stderr
+		return proc.Run(cmd.Context(), "tsc", c)
 	}
 
-	// Execute typecheck
-	cmdExec := exec.Command(checkCommand, checkArgs...)
-	cmdExec.Stdout = os.Stdout
-	cmdExec.Stderr = os.Stderr
-	cmdExec.Stdin = os.Stdin
+	// Plain file(line,col) lines to group by package
+	c.Args = append(c.Args, "--pretty", "false")
+	var out bytes.Buffer
+	c.Stdout, c.Stderr = &out, &out
+	faint.Printf("\n$ tsc -b %s (%d project(s))\n\n", tsproject.SolutionFile, len(build))
+	start := time.Now()
+	runErr := proc.Run(cmd.Context(), "tsc", c)
+	if cmd.Context().Err() != nil {
+		return fmt.Errorf("interrupted: %w", cmd.Context().Err())
+	}
 
-	if err := cmdExec.Run(); err != nil {
-		return fmt.Errorf("type checking failed: %w", err)
+	diags := findings.Dedupe(findings.ParseTsc(out.String(), ws.Root, ws.Rel))
+	groups := make(map[string][]findings.Finding)
+	for _, f := range diags {
+		name := "workspace"
+		if f.File != "" {
+			if pkg := ws.Owner(f.File); pkg != nil && pkg.Name != "" {
+				name = pkg.Name
+			}
+		}
+		groups[name] = append(groups[name], f)
 	}
 
-	green.Println("\n✅ No type errors found!\n")
+	errors := 0
+	for _, name := range sortedKeys(groups) {
+		red.Printf("📦 %s (%d error(s))\n", name, len(groups[name]))
+		for _, f := range groups[name] {
+			if f.Level == findings.Error {
+				errors++
+			}
+			msg, more, _ := strings.Cut(f.Message, "\n")
+			fmt.Print("  ")
+			if f.File != "" {
+				fmt.Printf("%s:%d:%d  ", f.File, f.Line, f.Col)
+			}
+			faint.Printf("%s  ", f.Rule)
+			fmt.Println(msg)
+			if more != "" {
+				for _, line := range strings.Split(more, "\n") {
+					faint.Printf("      %s\n", line)
+				}
+			}
+		}
+		fmt.Println()
+	}
+	if runErr != nil && len(diags) == 0 {
+		fmt.Print(out.String())
+	}
+
+	if len(typecheckReport) > 0 {
+		r := findings.New("tsc")
+		names := make(map[string]bool)
+		for name := range groups {
+			names[name] = true
+		}
+		for _, p := range build {
+			names[p.Package.Name] = true
+		}
+		sorted := make([]string, 0, len(names))
+		for name := range names {
+			sorted = append(sorted, name)
+		}
+		sort.Strings(sorted)
+		for _, name := range sorted {
+			r.AddTscDiagnostics(name, groups[name], false, "")
+		}
+		if runErr != nil && len(diags) == 0 {
+			r.AddFailure("tsc", runErr.Error(), out.String())
+		}
+		if err := writeFindings(ws, "typecheck", r, typecheckReport); err != nil {
+			return err
+		}
+	}
+
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if runErr != nil {
+		if errors == 0 {
+			return fmt.Errorf("type checking failed: %w", runErr)
+		}
+		var failed []string
+		for _, name := range sortedKeys(groups) {
+			failed = append(failed, name)
+		}
+		return fmt.Errorf("type checking failed with %d error(s) in %s (%s)", errors, strings.Join(failed, ", "), elapsed)
+	}
+	green.Printf("✅ No type errors found in %d project(s) (%s)\n\n", len(build), elapsed)
 	return nil
 }
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/codemod"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/git"
+	"github.com/kluth/solidum-cli/internal/release"
+	"github.com/kluth/solidum-cli/internal/semver"
+	"github.com/kluth/solidum-cli/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+var (
+	versionPackages []string
+	versionMode     string
+	versionPreid    string
+	versionDryRun   bool
+	versionNoCommit bool
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version [major|minor|patch|prerelease]",
+	Short: "Bump package versions, update changelogs and tag the release",
+	Long: `Bump the versions of the workspace packages, rewrite internal
+workspace: ranges that pin a version, add changelog entries from the
+Conventional Commits since each package's last release, then commit and
+create a <package>@<version> tag per package.
+
+Without a bump, it is inferred from the commits: breaking changes bump major
+(minor before 1.0.0), features minor, and fixes and performance improvements
+patch.
+
+In fixed mode (the default) every public package gets the same version and
+the entry goes to CHANGELOG.md at the root. In independent mode each changed
+package is bumped on its own, packages depending on it get a patch release,
+and each package keeps its own CHANGELOG.md. Set the mode in solidum.json:
+{"release": {"mode": "independent"}}.`,
+	Example: `  solidum version --dry-run
+  solidum version minor
+  solidum version prerelease --preid beta --packages @sldm/router`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{semver.Major, semver.Minor, semver.Patch, semver.Prerelease},
+	RunE:      runVersion,
+}
+
+func init() {
+	versionCmd.Flags().StringSliceVarP(&versionPackages, "packages", "p", nil, "Only version these packages (names, prefix* or ./dir)")
+	versionCmd.Flags().StringVar(&versionMode, "mode", "", "Versioning mode: fixed or independent (default from solidum.json, else fixed)")
+	versionCmd.Flags().StringVar(&versionPreid, "preid", "", "Prerelease identifier, e.g. beta for 1.0.0-beta.0")
+	versionCmd.Flags().BoolVar(&versionDryRun, "dry-run", false, "Show the new versions and file changes without writing anything")
+	versionCmd.Flags().BoolVar(&versionNoCommit, "no-commit", false, "Write the files but don't commit or tag")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+
+	ws, err := workspace.Load(".")
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	cfg, err := config.Load(ws.Root)
+	if err != nil {
+		return err
+	}
+	repo, err := git.Root(ws.Root)
+	if err != nil {
+		return err
+	}
+
+	opts := release.Options{Mode: releaseMode(versionMode, cfg), Preid: versionPreid, Date: time.Now()}
+	if len(args) > 0 {
+		opts.Bump = args[0]
+	}
+	if len(versionPackages) > 0 {
+		opts.Packages = ws.Filter(versionPackages...)
+		if len(opts.Packages) == 0 {
+			return fmt.Errorf("no package matches %s", strings.Join(versionPackages, ", "))
+		}
+	}
+
+	if versionDryRun {
+		yellow.Print("\n🔍 Running in dry-run mode - nothing will be written\n")
+	}
+	cyan.Printf("\n🔖 Versioning packages (%s)...\n\n", opts.Mode)
+
+	plan, err := release.NewPlan(ws, repo, opts)
+	if err != nil {
+		return err
+	}
+	return finishRelease(ws, repo, plan, nil, versionDryRun, versionNoCommit)
+}
+
+// releaseMode returns the versioning mode from a --mode flag, solidum.json
+// or the fixed default
+func releaseMode(flag string, cfg *config.Config) string {
+	if flag != "" {
+		return flag
+	}
+	if cfg.Release != nil && cfg.Release.Mode != "" {
+		return cfg.Release.Mode
+	}
+	return release.Fixed
+}
+
+// finishRelease prints the plan, then writes it, deletes the workspace-relative
+// files in remove, and commits and tags the release unless noCommit is set
+func finishRelease(ws *workspace.Workspace, repo string, plan *release.Plan, remove []string, dryRun, noCommit bool) error {
+	green := color.New(color.FgGreen, color.Bold)
+	faint := color.New(color.Faint)
+
+	for _, r := range plan.Releases {
+		why := "first release"
+		switch {
+		case len(r.Dependencies) > 0:
+			why = "depends on " + strings.Join(r.Dependencies, ", ")
+		case len(r.Changesets) > 0:
+			why = fmt.Sprintf("%d changeset(s)", len(r.Changesets))
+		case r.Since != "" && len(r.Commits) == 0:
+			why = "no changes since " + r.Since
+		case r.Since != "":
+			why = fmt.Sprintf("%d conventional commit(s) since %s", len(r.Commits), r.Since)
+		}
+		fmt.Printf("  %-28s %s → %s  ", r.Package.Name, r.From, green.Sprint(r.To))
+		faint.Println(why)
+	}
+	fmt.Println()
+
+	message := releaseMessage(plan)
+	if dryRun {
+		for _, rel := range plan.Paths {
+			fmt.Print(codemod.UnifiedDiff(rel, plan.Original(rel), string(plan.Files[rel])))
+			fmt.Println()
+		}
+		for _, rel := range remove {
+			faint.Printf("Would delete %s\n", rel)
+		}
+		if !noCommit {
+			faint.Printf("Would commit %q and tag:\n", strings.SplitN(message, "\n", 2)[0])
+			for _, r := range plan.Releases {
+				faint.Printf("  %s\n", r.Tag())
+			}
+		}
+		green.Print("\n✅ Dry-run completed - no files were changed\n\n")
+		return nil
+	}
+
+	if err := plan.Apply(); err != nil {
+		return err
+	}
+	for _, rel := range plan.Paths {
+		fmt.Printf("  📝 %s\n", rel)
+	}
+	paths := make([]string, 0, len(plan.Paths))
+	for _, rel := range plan.Paths {
+		paths = append(paths, ws.Path(rel))
+	}
+	if len(remove) > 0 {
+		removed := make([]string, len(remove))
+		for i, rel := range remove {
+			removed[i] = ws.Path(rel)
+		}
+		// Deleting a changeset that was never committed leaves nothing to commit
+		tracked, err := git.Tracked(repo, removed...)
+		if err != nil {
+			return err
+		}
+		for i, path := range removed {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			fmt.Printf("  🗑️  %s\n", remove[i])
+		}
+		paths = append(paths, tracked...)
+	}
+	if noCommit {
+		green.Printf("\n✅ Versioned %d package(s); commit and tag when ready\n\n", len(plan.Releases))
+		return nil
+	}
+
+	if err := git.Commit(repo, message, paths...); err != nil {
+		return err
+	}
+	fmt.Println()
+	for _, r := range plan.Releases {
+		if err := git.Tag(repo, r.Tag(), r.Tag()); err != nil {
+			return err
+		}
+		fmt.Printf("  🏷️  %s\n", r.Tag())
+	}
+
+	green.Printf("\n✅ Versioned %d package(s)\n", len(plan.Releases))
+	fmt.Print("Push with `git push --follow-tags`, then run `solidum publish`.\n\n")
+	return nil
+}
+
+// releaseMessage is the commit message of a release, listing its tags
+func releaseMessage(plan *release.Plan) string {
+	title := "chore(release): publish"
+	if plan.Mode == release.Fixed {
+		title = "chore(release): " + plan.Releases[0].To.String()
+	}
+	var b strings.Builder
+	b.WriteString(title + "\n")
+	for _, r := range plan.Releases {
+		fmt.Fprintf(&b, "\n- %s", r.Tag())
+	}
+	return b.String()
+}
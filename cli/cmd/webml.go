@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kluth/solidum-cli/internal/sarif"
+	"github.com/kluth/solidum-cli/internal/watch"
+	"github.com/kluth/solidum-cli/internal/webml"
+	"github.com/spf13/cobra"
+)
+
+var (
+	webmlWatch bool
+
+	webmlCheckFormat string
+	webmlCheckOutput string
+	webmlCheckStrict bool
+)
+
+var webmlCmd = &cobra.Command{
+	Use:   "webml",
+	Short: "Work with .webml templates",
+	Long: `Work with .webml templates, the HTML-with-mustaches files compiled to
+webml tagged-template modules (Button.webml → Button.webml.ts).`,
+}
+
+var webmlCompileCmd = &cobra.Command{
+	Use:   "compile [paths...]",
+	Short: "Compile .webml templates to .webml.ts modules",
+	Long: `Compile .webml templates to their .webml.ts companion modules.
+
+Paths may be files or directories; directories are searched recursively,
+skipping node_modules and dist. Defaults to the current directory.
+
+Supported syntax:
+  {{name}}, {{user.name}}              interpolate a prop
+  {{#if cond}}…{{else}}…{{/if}}        conditional, may be nested
+  {{#each items}}{{item}}{{/each}}     loop, with {{@index}}
+  {{#each todos as todo, i}}…{{/each}} loop with named variables
+  {{! comment }}                       dropped from the output
+  \{{                                  a literal "{{"
+
+Blocks must open and close in the same HTML context, e.g. an {{#if}} inside
+an attribute value is closed inside that value. Errors are reported as
+file:line:col. Modules are only rewritten when their content changes.`,
+	Example: `  solidum webml compile
+  solidum webml compile packages/ui/src/components
+  solidum webml compile Button.webml --watch`,
+	RunE: runWebMLCompile,
+}
+
+var webmlCheckCmd = &cobra.Command{
+	Use:   "check [paths...]",
+	Short: "Check .webml templates against their component props",
+	Long: `Check that the variables a .webml template references match the props
+its component declares.
+
+For Button.webml, the props are read from the ButtonTemplateProps or
+ButtonProps interface in Button.webml.ts or Button.ts (the first found).
+Interfaces extending other interfaces in the same file are merged, and
+{{#each}} loop variables are checked against the list's element type when
+it is declared in that file.
+
+Reports:
+  unknown-variable     not declared in the props (a warning when the
+                       interface has an index signature)
+  misspelled-variable  close to a declared prop, with a suggestion
+  unused-prop          declared but never used by the template (warning)
+  missing-props        no props interface found (warning)
+  syntax-error         the template doesn't parse
+
+Exits with status 1 when there are errors, or warnings with --strict.`,
+	Example: `  solidum webml check
+  solidum webml check packages/ui --format sarif -o webml.sarif`,
+	RunE: runWebMLCheck,
+}
+
+func init() {
+	webmlCompileCmd.Flags().BoolVarP(&webmlWatch, "watch", "w", false, "Recompile templates when they change")
+	webmlCheckCmd.Flags().StringVar(&webmlCheckFormat, "format", "text", "Output format: text, json or sarif")
+	webmlCheckCmd.Flags().StringVarP(&webmlCheckOutput, "output", "o", "", "Write the report to a file instead of stdout")
+	webmlCheckCmd.Flags().BoolVar(&webmlCheckStrict, "strict", false, "Fail on warnings too")
+	webmlCmd.AddCommand(webmlCompileCmd)
+	webmlCmd.AddCommand(webmlCheckCmd)
+}
+
+func runWebMLCompile(cmd *cobra.Command, args []string) error {
+	cyan := color.New(color.FgCyan, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := webml.FindFiles(paths...)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 && !webmlWatch {
+		return fmt.Errorf("no .webml files found in %s", strings.Join(paths, ", "))
+	}
+
+	cyan.Printf("\n🧩 Compiling %d template(s)...\n\n", len(files))
+	written, failed := compileWebMLFiles(files)
+
+	if !webmlWatch {
+		if failed > 0 {
+			return fmt.Errorf("%d template(s) failed to compile", failed)
+		}
+		green.Printf("\n✅ Compiled %d template(s), %d updated\n\n", len(files), written)
+		return nil
+	}
+
+	fmt.Print("\n👀 Watching for changes... (Ctrl+C to stop)\n\n")
+	for _, root := range paths {
+		go watchWebML(cmd, root)
+	}
+	<-cmd.Context().Done()
+	return nil
+}
+
+// compileWebMLFiles compiles each file, printing what changed and any errors
+func compileWebMLFiles(files []string) (written, failed int) {
+	red := color.New(color.FgRed)
+	faint := color.New(color.Faint)
+
+	for _, file := range files {
+		changed, err := webml.CompileFile(file)
+		switch {
+		case err != nil:
+			red.Printf("  ❌ %v\n", err)
+			failed++
+		case changed:
+			fmt.Printf("  ✓ %s\n", webml.OutputPath(file))
+			written++
+		default:
+			faint.Printf("  · %s (unchanged)\n", webml.OutputPath(file))
+		}
+	}
+	return written, failed
+}
+
+// watchWebML recompiles templates under root as they change
+func watchWebML(cmd *cobra.Command, root string) {
+	dir, single := root, ""
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		dir, single = filepath.Dir(root), filepath.Base(root)
+	}
+
+	w := &watch.Watcher{
+		Root: dir,
+		Skip: func(rel string, isDir bool) bool {
+			if isDir {
+				return webml.SkipDir(filepath.Base(rel))
+			}
+			if single != "" {
+				return rel != single
+			}
+			return !strings.HasSuffix(rel, webml.Ext)
+		},
+	}
+	w.Run(cmd.Context(), func(changed []string) {
+		var files []string
+		for _, rel := range changed {
+			file := filepath.Join(dir, filepath.FromSlash(rel))
+			if fileExists(file) {
+				files = append(files, file)
+			}
+		}
+		compileWebMLFiles(files)
+	})
+}
+
+func runWebMLCheck(cmd *cobra.Command, args []string) error {
+	switch webmlCheckFormat {
+	case "text", "json", "sarif":
+	default:
+		return fmt.Errorf("unknown format %q, expected text, json or sarif", webmlCheckFormat)
+	}
+
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := webml.FindFiles(paths...)
+	if err != nil {
+		return err
+	}
+
+	var diags []webml.Diagnostic
+	for _, file := range files {
+		d, err := webml.Check(file)
+		if err != nil {
+			return err
+		}
+		diags = append(diags, d...)
+	}
+	webml.SortDiagnostics(diags)
+
+	errs, warnings := 0, 0
+	for _, d := range diags {
+		if d.Severity == webml.SeverityError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+
+	var out []byte
+	switch webmlCheckFormat {
+	case "json":
+		out, err = json.MarshalIndent(struct {
+			Files       int                `json:"files"`
+			Errors      int                `json:"errors"`
+			Warnings    int                `json:"warnings"`
+			Diagnostics []webml.Diagnostic `json:"diagnostics"`
+		}{len(files), errs, warnings, append([]webml.Diagnostic{}, diags...)}, "", "  ")
+		out = append(out, '\n')
+	case "sarif":
+		out, err = webMLSarif(diags).Marshal()
+	default:
+		if webmlCheckOutput == "" {
+			printWebMLDiagnostics(diags, len(files), errs, warnings)
+		} else {
+			out = []byte(formatWebMLDiagnostics(diags))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if webmlCheckOutput != "" {
+		if err := os.MkdirAll(filepath.Dir(webmlCheckOutput), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(webmlCheckOutput, out, 0644); err != nil {
+			return err
+		}
+		color.New(color.Faint).Printf("📝 Report written to %s\n", webmlCheckOutput)
+	} else {
+		os.Stdout.Write(out)
+	}
+
+	if errs > 0 || (webmlCheckStrict && warnings > 0) {
+		return &ExitError{Code: 1, Err: fmt.Errorf("webml check found %d error(s) and %d warning(s)", errs, warnings)}
+	}
+	return nil
+}
+
+func printWebMLDiagnostics(diags []webml.Diagnostic, files, errs, warnings int) {
+	red := color.New(color.FgRed, color.Bold)
+	yellow := color.New(color.FgYellow, color.Bold)
+	green := color.New(color.FgGreen, color.Bold)
+	faint := color.New(color.Faint)
+
+	fmt.Println()
+	for _, d := range diags {
+		level := yellow.Sprint("warning")
+		if d.Severity == webml.SeverityError {
+			level = red.Sprint("error")
+		}
+		fmt.Printf("%s:%d:%d  %s  %s  %s\n", d.File, d.Line, d.Col, level, d.Message, faint.Sprint(d.Rule))
+	}
+
+	switch {
+	case errs > 0:
+		red.Printf("\n❌ %d error(s), %d warning(s) in %d template(s)\n\n", errs, warnings, files)
+	case warnings > 0:
+		yellow.Printf("\n⚠️  %d warning(s) in %d template(s)\n\n", warnings, files)
+	default:
+		green.Printf("✅ %d template(s) match their props\n\n", files)
+	}
+}
+
+// formatWebMLDiagnostics renders diagnostics as plain file:line:col lines
+func formatWebMLDiagnostics(diags []webml.Diagnostic) string {
+	var b strings.Builder
+	for _, d := range diags {
+		fmt.Fprintf(&b, "%s:%d:%d: %s: %s [%s]\n", d.File, d.Line, d.Col, d.Severity, d.Message, d.Rule)
+	}
+	return b.String()
+}
+
+func webMLSarif(diags []webml.Diagnostic) *sarif.Log {
+	log := sarif.New("solidum webml check", rootCmd.Version)
+	run := log.Run()
+	rules := make([]string, 0, len(webml.Rules))
+	for id := range webml.Rules {
+		rules = append(rules, id)
+	}
+	sort.Strings(rules)
+	for _, id := range rules {
+		run.AddRule(id, webml.Rules[id])
+	}
+	for _, d := range diags {
+		run.Add(d.Rule, string(d.Severity), d.Message, d.File, d.Line, d.Col)
+	}
+	return log
+}
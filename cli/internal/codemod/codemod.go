@@ -0,0 +1,159 @@
+package codemod
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// File is a source file prepared for transforms
+type File struct {
+	Path   string
+	Source string
+	Tokens []Token
+
+	// sig holds the indices of tokens that are not whitespace or comments
+	sig []int
+}
+
+// NewFile tokenizes source for the given path
+func NewFile(path, source string) *File {
+	f := &File{Path: path, Source: source, Tokens: Tokenize(source)}
+	for i, tok := range f.Tokens {
+		if tok.Kind != TokenWhitespace && tok.Kind != TokenComment {
+			f.sig = append(f.sig, i)
+		}
+	}
+	return f
+}
+
+// Significant returns the tokens that are not whitespace or comments
+func (f *File) Significant() []Token {
+	out := make([]Token, len(f.sig))
+	for i, idx := range f.sig {
+		out[i] = f.Tokens[idx]
+	}
+	return out
+}
+
+// Edit replaces the source range [Start, End) with Text
+type Edit struct {
+	Start  int
+	End    int
+	Text   string
+	Reason string
+}
+
+// Change describes an applied edit for reporting
+type Change struct {
+	File   string
+	Line   int
+	Col    int
+	Old    string
+	New    string
+	Reason string
+}
+
+// Transform rewrites a single source file
+type Transform interface {
+	Name() string
+	Apply(f *File) []Edit
+}
+
+// Result is the outcome of running transforms over a file
+type Result struct {
+	Path     string
+	Original string
+	Updated  string
+	Changes  []Change
+}
+
+// Changed reports whether any transform modified the file
+func (r *Result) Changed() bool {
+	return r.Original != r.Updated
+}
+
+// Run applies the transforms in order. Each transform sees the output of the
+// previous one so edits never overlap across transforms.
+func Run(path, source string, transforms []Transform) (*Result, error) {
+	result := &Result{Path: path, Original: source, Updated: source}
+
+	for _, tr := range transforms {
+		f := NewFile(path, result.Updated)
+		edits := tr.Apply(f)
+		if len(edits) == 0 {
+			continue
+		}
+
+		updated, err := ApplyEdits(f.Source, edits)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, tr.Name(), err)
+		}
+
+		for _, e := range edits {
+			line, col := position(f.Source, e.Start)
+			result.Changes = append(result.Changes, Change{
+				File:   path,
+				Line:   line,
+				Col:    col,
+				Old:    f.Source[e.Start:e.End],
+				New:    e.Text,
+				Reason: e.Reason,
+			})
+		}
+		result.Updated = updated
+	}
+
+	sort.SliceStable(result.Changes, func(i, j int) bool {
+		a, b := result.Changes[i], result.Changes[j]
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+
+	return result, nil
+}
+
+// ApplyEdits applies non-overlapping edits to src
+func ApplyEdits(src string, edits []Edit) (string, error) {
+	sorted := make([]Edit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	var b strings.Builder
+	last := 0
+	for _, e := range sorted {
+		if e.Start < last || e.End < e.Start || e.End > len(src) {
+			return "", fmt.Errorf("overlapping or invalid edit at offset %d", e.Start)
+		}
+		b.WriteString(src[last:e.Start])
+		b.WriteString(e.Text)
+		last = e.End
+	}
+	b.WriteString(src[last:])
+	return b.String(), nil
+}
+
+func position(src string, offset int) (int, int) {
+	line, col := 1, 1
+	for _, r := range src[:offset] {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+// unquote strips the quotes from a string literal token
+func unquote(s string) string {
+	if len(s) >= 2 {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
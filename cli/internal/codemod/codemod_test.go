@@ -0,0 +1,132 @@
+package codemod
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrations(t *testing.T) {
+	for _, m := range Migrations() {
+		t.Run(m.ID, func(t *testing.T) {
+			dir := filepath.Join("testdata", m.ID)
+			input := readFixture(t, filepath.Join(dir, "input.ts"))
+			expected := readFixture(t, filepath.Join(dir, "expected.ts"))
+
+			result, err := Run("src/app.ts", input, m.Transforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Updated != expected {
+				t.Errorf("unexpected output:\n%s", UnifiedDiff("src/app.ts", expected, result.Updated))
+			}
+			if !result.Changed() || len(result.Changes) == 0 {
+				t.Error("Run reported no changes")
+			}
+
+			// Running again over migrated code must not touch it
+			again, err := Run("src/app.ts", result.Updated, m.Transforms)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again.Changed() {
+				t.Errorf("migration is not idempotent:\n%s", UnifiedDiff("src/app.ts", result.Updated, again.Updated))
+			}
+		})
+	}
+}
+
+func TestIdentifierRename(t *testing.T) {
+	rename := IdentifierRename{Module: "@sldm/core", From: "createSignal", To: "atom"}
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			name: "target already imported",
+			src:  "import { atom, createSignal } from '@sldm/core';\nconst a = createSignal(0), b = atom(1);\n",
+			want: "import { atom } from '@sldm/core';\nconst a = atom(0), b = atom(1);\n",
+		},
+		{
+			name: "aliased import keeps its local name",
+			src:  "import { createSignal as signal } from '@sldm/core';\nconst a = signal(0);\n",
+			want: "import { atom as signal } from '@sldm/core';\nconst a = signal(0);\n",
+		},
+		{
+			name: "other modules are left alone",
+			src:  "import { createSignal } from 'solid-js';\nconst a = createSignal(0);\n",
+			want: "import { createSignal } from 'solid-js';\nconst a = createSignal(0);\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Run("src/app.ts", tt.src, []Transform{rename})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Updated != tt.want {
+				t.Errorf("unexpected output:\n%s", UnifiedDiff("src/app.ts", tt.want, result.Updated))
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "unchanged",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "single line",
+			a:    "import { x } from '@solidum/core';\nx();\n",
+			b:    "import { x } from '@sldm/core';\nx();\n",
+			want: "--- a/src/app.ts\n+++ b/src/app.ts\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				"-import { x } from '@solidum/core';\n" +
+				"+import { x } from '@sldm/core';\n" +
+				" x();\n",
+		},
+		{
+			name: "separate hunks",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			b:    "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			want: "--- a/src/app.ts\n+++ b/src/app.ts\n" +
+				"@@ -1,4 +1,4 @@\n" +
+				"-1\n+one\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n" +
+				" 9\n 10\n 11\n-12\n+twelve\n",
+		},
+		{
+			name: "nearby changes share a hunk",
+			a:    "1\n2\n3\n4\n5\n",
+			b:    "1\ntwo\n3\nfour\n5\n",
+			want: "--- a/src/app.ts\n+++ b/src/app.ts\n" +
+				"@@ -1,5 +1,5 @@\n" +
+				" 1\n-2\n+two\n 3\n-4\n+four\n 5\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnifiedDiff("/src/app.ts", tt.a, tt.b); got != tt.want {
+				t.Errorf("UnifiedDiff =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func readFixture(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
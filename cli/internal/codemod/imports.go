@@ -0,0 +1,175 @@
+package codemod
+
+// importSpec is one `{ name }` or `{ name as alias }` entry of an import
+type importSpec struct {
+	Imported Token
+	Local    *Token
+
+	// Start and End cover the specifier text itself
+	Start int
+	End   int
+
+	// RemoveStart and RemoveEnd also cover the neighboring comma, so the
+	// specifier can be deleted without leaving `{ a, , b }` behind
+	RemoveStart int
+	RemoveEnd   int
+}
+
+// importDecl is a static `import ... from '...'` declaration
+type importDecl struct {
+	Module string
+	Start  int
+	End    int
+	Named  []importSpec
+}
+
+func (d importDecl) has(name string) bool {
+	for _, spec := range d.Named {
+		local := spec.Imported.Text
+		if spec.Local != nil {
+			local = spec.Local.Text
+		}
+		if local == name {
+			return true
+		}
+	}
+	return false
+}
+
+func removeSpecifier(spec importSpec, reason string) Edit {
+	return Edit{Start: spec.RemoveStart, End: spec.RemoveEnd, Reason: reason}
+}
+
+// parseImports finds the static import declarations of a file
+func parseImports(f *File) []importDecl {
+	sig := f.Significant()
+	var decls []importDecl
+
+	for i := 0; i < len(sig); i++ {
+		if sig[i].Text != "import" || (i > 0 && sig[i-1].Text == ".") {
+			continue
+		}
+		if i+1 < len(sig) && sig[i+1].Text == "(" {
+			continue
+		}
+
+		decl := importDecl{Start: sig[i].Offset}
+		j := i + 1
+		for ; j < len(sig); j++ {
+			tok := sig[j]
+			if tok.Kind == TokenString {
+				decl.Module = unquote(tok.Text)
+				decl.End = tok.Offset + len(tok.Text)
+				break
+			}
+			if tok.Text == "{" {
+				var end int
+				decl.Named, end = parseNamedSpecifiers(sig, j)
+				j = end
+			}
+			if tok.Text == ";" {
+				break
+			}
+		}
+		if decl.Module != "" {
+			decls = append(decls, decl)
+		}
+		i = j
+	}
+
+	return decls
+}
+
+// parseNamedSpecifiers parses `{ a, b as c }` starting at the open brace and
+// returns the specifiers with the index of the closing brace
+func parseNamedSpecifiers(sig []Token, open int) ([]importSpec, int) {
+	var specs []importSpec
+	i := open + 1
+	for i < len(sig) && sig[i].Text != "}" {
+		if sig[i].Text == "," {
+			i++
+			continue
+		}
+		if sig[i].Text == "type" && i+1 < len(sig) && sig[i+1].Text != "," && sig[i+1].Text != "}" && sig[i+1].Text != "as" {
+			i++
+		}
+
+		spec := importSpec{Imported: sig[i], Start: sig[i].Offset}
+		last := sig[i]
+		if i+2 < len(sig) && sig[i+1].Text == "as" {
+			local := sig[i+2]
+			spec.Local = &local
+			last = local
+			i += 2
+		}
+		spec.End = last.Offset + len(last.Text)
+		i++
+
+		spec.RemoveStart, spec.RemoveEnd = spec.Start, spec.End
+		if i < len(sig) && sig[i].Text == "," && i+1 < len(sig) && sig[i+1].Text != "}" {
+			spec.RemoveEnd = sig[i+1].Offset
+		} else if len(specs) > 0 {
+			prev := specs[len(specs)-1]
+			spec.RemoveStart = prev.End
+		}
+
+		specs = append(specs, spec)
+	}
+	return specs, i
+}
+
+// ModuleSpecifiers returns the string tokens that name a module: static and
+// dynamic imports, re-exports, require() calls and vi.mock() style mocks
+func ModuleSpecifiers(f *File) []Token {
+	sig := f.Significant()
+	var specs []Token
+
+	for i, tok := range sig {
+		if tok.Kind != TokenString || i == 0 {
+			continue
+		}
+		prev := sig[i-1]
+		switch {
+		case prev.Text == "from" || prev.Text == "import":
+			specs = append(specs, tok)
+		case prev.Text == "(" && i >= 2:
+			callee := sig[i-2].Text
+			if callee == "import" || callee == "require" || callee == "mock" || callee == "doMock" {
+				specs = append(specs, tok)
+			}
+		case prev.Text == "module" && i >= 2 && sig[i-2].Text == "declare":
+			specs = append(specs, tok)
+		}
+	}
+
+	return specs
+}
+
+// ImportedNames maps the local names bound by the named imports from module
+// to the names they import, e.g. `import { atom as a }` → a: atom
+func ImportedNames(f *File, module string) map[string]string {
+	names := make(map[string]string)
+	for _, decl := range parseImports(f) {
+		if decl.Module != module {
+			continue
+		}
+		for _, spec := range decl.Named {
+			local := spec.Imported.Text
+			if spec.Local != nil {
+				local = spec.Local.Text
+			}
+			names[local] = spec.Imported.Text
+		}
+	}
+	return names
+}
+
+// inImportClause reports whether offset lies inside one of the imports
+func inImportClause(imports []importDecl, offset int) bool {
+	for _, decl := range imports {
+		if offset >= decl.Start && offset < decl.End {
+			return true
+		}
+	}
+	return false
+}
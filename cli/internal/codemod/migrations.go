@@ -0,0 +1,109 @@
+package codemod
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Migration is a named set of transforms that moves user code from one
+// Solidum version to another
+type Migration struct {
+	ID          string
+	From        string
+	To          string
+	Description string
+	Transforms  []Transform
+}
+
+var migrations = []*Migration{
+	{
+		ID:          "sldm-scope",
+		From:        "0.1",
+		To:          "0.2",
+		Description: "Rename @solidum/* imports to the @sldm/* npm organization and createSignal to atom",
+		Transforms: []Transform{
+			ImportPathRename{From: "@solidum/", To: "@sldm/"},
+			IdentifierRename{Module: "@sldm/core", From: "createSignal", To: "atom"},
+		},
+	},
+	{
+		ID:          "module-state-to-atom",
+		From:        "0.2",
+		To:          "0.3",
+		Description: "Replace useState calls at module scope with atom",
+		Transforms: []Transform{
+			ModuleStateToAtom{Module: "@sldm/core"},
+		},
+	},
+}
+
+// Migrations returns every registered migration in release order
+func Migrations() []*Migration {
+	return migrations
+}
+
+// Lookup finds a migration by ID
+func Lookup(id string) (*Migration, error) {
+	for _, m := range migrations {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown migration: %s", id)
+}
+
+// Between returns the migrations needed to move from one version to another,
+// e.g. Between("0.1", "0.3"). Empty bounds are open-ended.
+func Between(from, to string) []*Migration {
+	var selected []*Migration
+	active := from == ""
+	for _, m := range migrations {
+		if m.From == from {
+			active = true
+		}
+		if active {
+			selected = append(selected, m)
+		}
+		if m.To == to {
+			break
+		}
+	}
+	return selected
+}
+
+// Transforms flattens the transforms of the given migrations
+func Transforms(ms []*Migration) []Transform {
+	var all []Transform
+	for _, m := range ms {
+		all = append(all, m.Transforms...)
+	}
+	return all
+}
+
+// FindSources lists the TypeScript files under dir, skipping declaration
+// files, node_modules and build output
+func FindSources(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if name == "node_modules" || name == "dist" || (strings.HasPrefix(name, ".") && path != dir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".d.ts") {
+			return nil
+		}
+		if strings.HasSuffix(path, ".ts") || strings.HasSuffix(path, ".tsx") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,12 @@
+import { component, useState, atom } from '@sldm/core';
+
+const count = atom(0);
+const settings = atom((() => loadSettings())());
+const label = `total: ${atom('items')}`;
+const defaults = [atom(1)];
+const makeState = () => useState(2);
+
+export const Counter = component(() => {
+  const [open, setOpen] = useState(false);
+  return { count, open, setOpen };
+});
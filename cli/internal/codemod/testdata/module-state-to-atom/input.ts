@@ -0,0 +1,12 @@
+import { component, useState } from '@sldm/core';
+
+const count = useState(0);
+const settings = useState(() => loadSettings());
+const label = `total: ${useState('items')}`;
+const defaults = [useState(1)];
+const makeState = () => useState(2);
+
+export const Counter = component(() => {
+  const [open, setOpen] = useState(false);
+  return { count, open, setOpen };
+});
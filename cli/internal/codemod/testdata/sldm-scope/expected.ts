@@ -0,0 +1,21 @@
+import { component, atom, useState } from '@sldm/core';
+import { Router } from "@sldm/router";
+import type { Store } from '@sldm/store/types';
+import '@sldm/ui/styles.css';
+import { format } from 'solidum-utils';
+
+export { Button } from '@sldm/ui';
+export * from '@sldm/store';
+
+// createSignal is atom since 0.2; keys and members keep their name
+export const count = atom(0);
+const counters = { createSignal: count, total: atom(1) };
+counters.createSignal.subscribe(console.log);
+
+// Old docs still say to install @solidum/core
+const docs = 'https://example.com/@solidum/core';
+
+export async function loadCharts() {
+  const { Chart } = await import('@sldm/charts');
+  return Chart;
+}
@@ -0,0 +1,21 @@
+import { component, createSignal, useState } from '@solidum/core';
+import { Router } from "@solidum/router";
+import type { Store } from '@solidum/store/types';
+import '@solidum/ui/styles.css';
+import { format } from 'solidum-utils';
+
+export { Button } from '@solidum/ui';
+export * from '@solidum/store';
+
+// createSignal is atom since 0.2; keys and members keep their name
+export const count = createSignal(0);
+const counters = { createSignal: count, total: createSignal(1) };
+counters.createSignal.subscribe(console.log);
+
+// Old docs still say to install @solidum/core
+const docs = 'https://example.com/@solidum/core';
+
+export async function loadCharts() {
+  const { Chart } = await import('@solidum/charts');
+  return Chart;
+}
@@ -0,0 +1,287 @@
+package codemod
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenKind classifies a TypeScript source token
+type TokenKind int
+
+const (
+	TokenIdent TokenKind = iota
+	TokenKeyword
+	TokenString
+	TokenTemplate
+	TokenNumber
+	TokenRegex
+	TokenPunct
+	TokenComment
+	TokenWhitespace
+)
+
+// Token is a slice of the source with its position
+type Token struct {
+	Kind   TokenKind
+	Text   string
+	Offset int
+	Line   int
+	Col    int
+}
+
+var keywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "case": true,
+	"catch": true, "class": true, "const": true, "continue": true, "default": true,
+	"delete": true, "do": true, "else": true, "enum": true, "export": true,
+	"extends": true, "false": true, "finally": true, "for": true, "from": true,
+	"function": true, "if": true, "import": true, "in": true, "instanceof": true,
+	"interface": true, "let": true, "new": true, "null": true, "return": true,
+	"super": true, "switch": true, "this": true, "throw": true, "true": true,
+	"try": true, "type": true, "typeof": true, "var": true, "void": true,
+	"while": true, "with": true, "yield": true,
+}
+
+// keywords after which a slash starts a regular expression literal
+var regexPrefixKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true,
+}
+
+// Tokenize splits TypeScript source into tokens. Whitespace and comments are
+// kept so that concatenating every token's text reproduces the input.
+func Tokenize(src string) []Token {
+	t := &tokenizer{src: src, line: 1, col: 1}
+	return t.run()
+}
+
+type tokenizer struct {
+	src    string
+	pos    int
+	line   int
+	col    int
+	tokens []Token
+
+	// braceStack tracks template literal nesting: true entries are `${`
+	// substitutions, false entries are ordinary braces.
+	braceStack []bool
+}
+
+func (t *tokenizer) run() []Token {
+	for t.pos < len(t.src) {
+		start := t.pos
+		c := t.src[t.pos]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			for t.pos < len(t.src) && strings.IndexByte(" \t\n\r", t.src[t.pos]) >= 0 {
+				t.pos++
+			}
+			t.emit(TokenWhitespace, start)
+		case c == '/' && t.peek(1) == '/':
+			for t.pos < len(t.src) && t.src[t.pos] != '\n' {
+				t.pos++
+			}
+			t.emit(TokenComment, start)
+		case c == '/' && t.peek(1) == '*':
+			end := strings.Index(t.src[t.pos+2:], "*/")
+			if end < 0 {
+				t.pos = len(t.src)
+			} else {
+				t.pos += end + 4
+			}
+			t.emit(TokenComment, start)
+		case c == '"' || c == '\'':
+			t.scanQuoted(c)
+			t.emit(TokenString, start)
+		case c == '`':
+			t.pos++
+			t.scanTemplate(start)
+		case c == '}' && len(t.braceStack) > 0 && t.braceStack[len(t.braceStack)-1]:
+			t.braceStack = t.braceStack[:len(t.braceStack)-1]
+			t.pos++
+			t.scanTemplate(start)
+		case c == '/' && t.regexAllowed():
+			t.scanRegex()
+			t.emit(TokenRegex, start)
+		case isDigit(c) || (c == '.' && isDigit(t.peek(1))):
+			for t.pos < len(t.src) && (isIdentPart(rune(t.src[t.pos])) || t.src[t.pos] == '.') {
+				t.pos++
+			}
+			t.emit(TokenNumber, start)
+		default:
+			r, size := utf8.DecodeRuneInString(t.src[t.pos:])
+			if isIdentStart(r) {
+				t.pos += size
+				for t.pos < len(t.src) {
+					r, size = utf8.DecodeRuneInString(t.src[t.pos:])
+					if !isIdentPart(r) {
+						break
+					}
+					t.pos += size
+				}
+				if keywords[t.src[start:t.pos]] {
+					t.emit(TokenKeyword, start)
+				} else {
+					t.emit(TokenIdent, start)
+				}
+				continue
+			}
+			switch c {
+			case '{':
+				t.braceStack = append(t.braceStack, false)
+			case '}':
+				if len(t.braceStack) > 0 {
+					t.braceStack = t.braceStack[:len(t.braceStack)-1]
+				}
+			}
+			t.pos += t.punctLen()
+			t.emit(TokenPunct, start)
+		}
+	}
+	return t.tokens
+}
+
+func (t *tokenizer) peek(n int) byte {
+	if t.pos+n < len(t.src) {
+		return t.src[t.pos+n]
+	}
+	return 0
+}
+
+func (t *tokenizer) emit(kind TokenKind, start int) {
+	text := t.src[start:t.pos]
+	t.tokens = append(t.tokens, Token{Kind: kind, Text: text, Offset: start, Line: t.line, Col: t.col})
+	for _, r := range text {
+		if r == '\n' {
+			t.line++
+			t.col = 1
+		} else {
+			t.col++
+		}
+	}
+}
+
+func (t *tokenizer) scanQuoted(quote byte) {
+	t.pos++
+	for t.pos < len(t.src) {
+		c := t.src[t.pos]
+		if c == '\\' {
+			t.pos += 2
+			continue
+		}
+		t.pos++
+		if c == quote || c == '\n' {
+			return
+		}
+	}
+	t.pos = len(t.src)
+}
+
+// scanTemplate scans a template literal chunk up to the closing backtick or
+// the next `${`, which pushes a substitution onto the brace stack.
+func (t *tokenizer) scanTemplate(start int) {
+	for t.pos < len(t.src) {
+		c := t.src[t.pos]
+		switch {
+		case c == '\\':
+			t.pos += 2
+			continue
+		case c == '`':
+			t.pos++
+			t.emit(TokenTemplate, start)
+			return
+		case c == '$' && t.peek(1) == '{':
+			t.pos += 2
+			t.braceStack = append(t.braceStack, true)
+			t.emit(TokenTemplate, start)
+			return
+		}
+		t.pos++
+	}
+	t.pos = len(t.src)
+	t.emit(TokenTemplate, start)
+}
+
+func (t *tokenizer) scanRegex() {
+	t.pos++
+	inClass := false
+	for t.pos < len(t.src) {
+		c := t.src[t.pos]
+		switch {
+		case c == '\\':
+			t.pos += 2
+			continue
+		case c == '\n':
+			return
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			t.pos++
+			for t.pos < len(t.src) && isIdentPart(rune(t.src[t.pos])) {
+				t.pos++
+			}
+			return
+		}
+		t.pos++
+	}
+}
+
+// regexAllowed reports whether a slash at the current position starts a
+// regular expression rather than a division operator.
+func (t *tokenizer) regexAllowed() bool {
+	prev := lastSignificant(t.tokens)
+	if prev == nil {
+		return true
+	}
+	switch prev.Kind {
+	case TokenIdent, TokenNumber, TokenString, TokenTemplate, TokenRegex:
+		return false
+	case TokenKeyword:
+		return regexPrefixKeywords[prev.Text]
+	case TokenPunct:
+		return prev.Text != ")" && prev.Text != "]" && prev.Text != "}"
+	}
+	return true
+}
+
+var multiCharPuncts = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "**", "<<", ">>",
+}
+
+func (t *tokenizer) punctLen() int {
+	rest := t.src[t.pos:]
+	for _, p := range multiCharPuncts {
+		if strings.HasPrefix(rest, p) {
+			return len(p)
+		}
+	}
+	_, size := utf8.DecodeRuneInString(rest)
+	return size
+}
+
+func lastSignificant(tokens []Token) *Token {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i].Kind != TokenWhitespace && tokens[i].Kind != TokenComment {
+			return &tokens[i]
+		}
+	}
+	return nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || unicode.IsDigit(r)
+}
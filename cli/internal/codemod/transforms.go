@@ -0,0 +1,264 @@
+package codemod
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImportPathRename rewrites module specifiers that start with From so they
+// start with To instead, e.g. "@solidum/" → "@sldm/"
+type ImportPathRename struct {
+	From string
+	To   string
+}
+
+func (r ImportPathRename) Name() string {
+	return fmt.Sprintf("import-path %s → %s", r.From, r.To)
+}
+
+func (r ImportPathRename) Apply(f *File) []Edit {
+	var edits []Edit
+	for _, tok := range ModuleSpecifiers(f) {
+		spec := unquote(tok.Text)
+		if !matchesModule(spec, r.From) {
+			continue
+		}
+		quote := tok.Text[:1]
+		renamed := r.To + strings.TrimPrefix(spec, r.From)
+		edits = append(edits, Edit{
+			Start:  tok.Offset,
+			End:    tok.Offset + len(tok.Text),
+			Text:   quote + renamed + quote,
+			Reason: fmt.Sprintf("import path '%s' renamed to '%s'", spec, renamed),
+		})
+	}
+	return edits
+}
+
+// IdentifierRename renames a named export imported from Module. Unaliased
+// imports also have every reference in the file renamed.
+type IdentifierRename struct {
+	Module string
+	From   string
+	To     string
+}
+
+func (r IdentifierRename) Name() string {
+	return fmt.Sprintf("identifier %s → %s", r.From, r.To)
+}
+
+func (r IdentifierRename) Apply(f *File) []Edit {
+	var edits []Edit
+	renameRefs := false
+	imports := parseImports(f)
+
+	for _, imp := range imports {
+		if !matchesModule(imp.Module, r.Module) {
+			continue
+		}
+		for _, spec := range imp.Named {
+			if spec.Imported.Text != r.From {
+				continue
+			}
+			if spec.Local == nil && imp.has(r.To) {
+				// The target is already imported: drop the old specifier
+				edits = append(edits, removeSpecifier(spec, fmt.Sprintf("removed duplicate import of '%s'", r.From)))
+				renameRefs = true
+				continue
+			}
+			edits = append(edits, renameToken(spec.Imported, r.To))
+			if spec.Local == nil {
+				renameRefs = true
+			}
+		}
+	}
+
+	if !renameRefs {
+		return edits
+	}
+
+	sig := f.Significant()
+	for i, tok := range sig {
+		if tok.Kind != TokenIdent || tok.Text != r.From || inImportClause(imports, tok.Offset) {
+			continue
+		}
+		if isPropertyName(sig, i) {
+			continue
+		}
+		edits = append(edits, renameToken(tok, r.To))
+	}
+	return edits
+}
+
+// ModuleStateToAtom rewrites useState calls made at module scope to atom.
+// useState only persists inside a component render, so module-level calls
+// were always plain atoms that additionally logged a warning.
+type ModuleStateToAtom struct {
+	Module string
+}
+
+func (r ModuleStateToAtom) Name() string {
+	return "module-state-to-atom"
+}
+
+func (r ModuleStateToAtom) Apply(f *File) []Edit {
+	var imp *importDecl
+	imports := parseImports(f)
+	for i := range imports {
+		if matchesModule(imports[i].Module, r.Module) && imports[i].has("useState") {
+			imp = &imports[i]
+			break
+		}
+	}
+	if imp == nil {
+		return nil
+	}
+
+	var edits []Edit
+	sig := f.Significant()
+	depth := 0
+	converted, remaining := 0, 0
+	// arrows holds the depths at which arrow function expression bodies
+	// started; they end at a comma or semicolon or with their brackets
+	var arrows []int
+
+	// Template substitutions and array literals don't leave module scope,
+	// so only braces, parentheses and arrow function bodies count
+	for i, tok := range sig {
+		if tok.Kind == TokenTemplate {
+			continue
+		}
+		switch tok.Text {
+		case "{", "(":
+			depth++
+			continue
+		case "}", ")":
+			depth--
+			for len(arrows) > 0 && arrows[len(arrows)-1] > depth {
+				arrows = arrows[:len(arrows)-1]
+			}
+			continue
+		case ",", ";":
+			for len(arrows) > 0 && arrows[len(arrows)-1] == depth {
+				arrows = arrows[:len(arrows)-1]
+			}
+			continue
+		case "=>":
+			if i+1 < len(sig) && sig[i+1].Text != "{" {
+				arrows = append(arrows, depth)
+			}
+			continue
+		}
+
+		if tok.Kind != TokenIdent || tok.Text != "useState" || inImportClause(imports, tok.Offset) {
+			continue
+		}
+		if depth != 0 || len(arrows) > 0 || i+1 >= len(sig) || sig[i+1].Text != "(" {
+			remaining++
+			continue
+		}
+
+		edits = append(edits, renameToken(tok, "atom"))
+		converted++
+
+		// atom() has no lazy initializer, so invoke function arguments
+		if isFunctionArg(sig, i+2) {
+			closeIdx := matchingParen(sig, i+1)
+			if closeIdx > 0 {
+				open, closing := sig[i+1], sig[closeIdx]
+				edits = append(edits,
+					Edit{Start: open.Offset + 1, End: open.Offset + 1, Text: "(", Reason: "invoke lazy initializer"},
+					Edit{Start: closing.Offset, End: closing.Offset, Text: ")()", Reason: "invoke lazy initializer"},
+				)
+			}
+		}
+	}
+
+	if converted == 0 {
+		return nil
+	}
+
+	for _, spec := range imp.Named {
+		if spec.Imported.Text != "useState" {
+			continue
+		}
+		switch {
+		case remaining > 0 && !imp.has("atom"):
+			edits = append(edits, Edit{
+				Start:  spec.End,
+				End:    spec.End,
+				Text:   ", atom",
+				Reason: "import atom",
+			})
+		case remaining == 0 && imp.has("atom"):
+			edits = append(edits, removeSpecifier(spec, "removed unused import of 'useState'"))
+		case remaining == 0:
+			edits = append(edits, renameToken(spec.Imported, "atom"))
+		}
+	}
+
+	return edits
+}
+
+func renameToken(tok Token, to string) Edit {
+	return Edit{
+		Start:  tok.Offset,
+		End:    tok.Offset + len(tok.Text),
+		Text:   to,
+		Reason: fmt.Sprintf("renamed '%s' to '%s'", tok.Text, to),
+	}
+}
+
+// matchesModule reports whether spec equals pattern, or starts with it when
+// the pattern ends in a slash
+func matchesModule(spec, pattern string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(spec, pattern)
+	}
+	return spec == pattern
+}
+
+// isPropertyName reports whether the identifier at sig[i] is a member access
+// or an object literal key rather than a variable reference
+func isPropertyName(sig []Token, i int) bool {
+	if i > 0 && (sig[i-1].Text == "." || sig[i-1].Text == "?.") {
+		return true
+	}
+	if i+1 < len(sig) && sig[i+1].Text == ":" && i > 0 && (sig[i-1].Text == "{" || sig[i-1].Text == ",") {
+		return true
+	}
+	return false
+}
+
+func isFunctionArg(sig []Token, i int) bool {
+	if i >= len(sig) {
+		return false
+	}
+	if sig[i].Text == "function" || sig[i].Text == "async" {
+		return true
+	}
+	if sig[i].Kind == TokenIdent && i+1 < len(sig) && sig[i+1].Text == "=>" {
+		return true
+	}
+	if sig[i].Text == "(" {
+		closeIdx := matchingParen(sig, i)
+		return closeIdx > 0 && closeIdx+1 < len(sig) && sig[closeIdx+1].Text == "=>"
+	}
+	return false
+}
+
+func matchingParen(sig []Token, open int) int {
+	depth := 0
+	for i := open; i < len(sig); i++ {
+		switch sig[i].Text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
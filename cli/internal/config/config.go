@@ -0,0 +1,183 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the CLI configuration file at the workspace root
+const FileName = "solidum.json"
+
+// Config is the contents of solidum.json
+type Config struct {
+	// Concurrency is the default number of tasks run at once
+	Concurrency int `json:"concurrency,omitempty"`
+
+	Tasks map[string]*Task `json:"tasks,omitempty"`
+
+	Prerender *Prerender `json:"prerender,omitempty"`
+
+	Size *Size `json:"size,omitempty"`
+
+	Coverage *Coverage `json:"coverage,omitempty"`
+
+	Lint *Lint `json:"lint,omitempty"`
+
+	Release *Release `json:"release,omitempty"`
+
+	Pack *Pack `json:"pack,omitempty"`
+}
+
+// Pack configures the tarball checks of solidum pack --check and publish
+type Pack struct {
+	// MaxFileSize is the largest file a tarball may hold, e.g. "1 MB"
+	// (the default)
+	MaxFileSize string `json:"maxFileSize,omitempty"`
+}
+
+// Release configures solidum version
+type Release struct {
+	// Mode is "fixed" (default), giving every package the same version, or
+	// "independent"
+	Mode string `json:"mode,omitempty"`
+}
+
+// Lint configures the Solidum rules of solidum lint
+type Lint struct {
+	// Rules maps a rule name to "off", "warn" or "error", overriding its
+	// default severity
+	Rules map[string]string `json:"rules,omitempty"`
+
+	// Ignore lists globs of files, relative to the workspace root, that are
+	// not checked
+	Ignore []string `json:"ignore,omitempty"`
+}
+
+// Coverage configures the merged coverage of solidum test --coverage
+type Coverage struct {
+	// Thresholds are the minimum percentages of the whole workspace, keyed
+	// by "lines", "statements", "functions" or "branches"
+	Thresholds map[string]float64 `json:"thresholds,omitempty"`
+
+	// Packages maps a package name to its own minimum percentages
+	Packages map[string]map[string]float64 `json:"packages,omitempty"`
+
+	// Dir is where the merged lcov and Cobertura reports are written,
+	// relative to the workspace root (default "coverage")
+	Dir string `json:"dir,omitempty"`
+}
+
+// Size configures solidum size
+type Size struct {
+	// Budgets map a package name ("@sldm/core") or an entry file
+	// ("@sldm/core/dist/index.js") to its maximum size, e.g. "10 KB"
+	Budgets map[string]string `json:"budgets,omitempty"`
+
+	// Compression is the size budgets apply to: "raw", "gzip" (default) or
+	// "brotli"
+	Compression string `json:"compression,omitempty"`
+}
+
+// Prerender configures solidum prerender
+type Prerender struct {
+	// Entry is the SSR entry module exporting render(url)
+	Entry string `json:"entry,omitempty"`
+
+	// Routes to render. When empty, the entry's routes or getRoutes export
+	// is used.
+	Routes []string `json:"routes,omitempty"`
+
+	// SiteURL is the public origin used for sitemap.xml, e.g.
+	// https://example.com
+	SiteURL string `json:"siteUrl,omitempty"`
+}
+
+// Task declares how a task runs in each workspace package
+type Task struct {
+	// Command runs through the shell in the package directory. When empty,
+	// the package script with the task's name is run instead.
+	Command string `json:"command,omitempty"`
+
+	// DependsOn lists tasks that must finish first. "^build" means the build
+	// task of every workspace dependency; "build" means the same package.
+	DependsOn []string `json:"dependsOn,omitempty"`
+
+	// Inputs and Outputs are globs relative to the package directory
+	Inputs  []string `json:"inputs,omitempty"`
+	Outputs []string `json:"outputs,omitempty"`
+
+	Env map[string]string `json:"env,omitempty"`
+
+	// Cacheable tasks are skipped when their inputs are unchanged
+	Cacheable bool `json:"cacheable,omitempty"`
+
+	// Root tasks run once at the workspace root instead of per package
+	Root bool `json:"root,omitempty"`
+}
+
+// Default returns the built-in task pipeline matching the root package.json
+// scripts
+func Default() *Config {
+	return &Config{
+		Tasks: map[string]*Task{
+			"build": {
+				DependsOn: []string{"^build"},
+				Inputs:    []string{"src/**", "package.json", "tsconfig.json"},
+				Outputs:   []string{"dist/**"},
+				Cacheable: true,
+			},
+			"test": {},
+			"lint": {
+				Inputs:    []string{"src/**", "package.json"},
+				Cacheable: true,
+			},
+			"typecheck": {
+				DependsOn: []string{"^build"},
+				Inputs:    []string{"src/**", "package.json", "tsconfig.json"},
+				Cacheable: true,
+			},
+			"format": {
+				Root: true,
+			},
+			"format:check": {
+				Root: true,
+			},
+		},
+	}
+}
+
+// Load reads solidum.json from root and merges it over the defaults.
+// A missing file yields the defaults.
+func Load(root string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(filepath.Join(root, FileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, err
+	}
+
+	var file Config
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", FileName, err)
+	}
+
+	if file.Concurrency > 0 {
+		cfg.Concurrency = file.Concurrency
+	}
+	for name, task := range file.Tasks {
+		cfg.Tasks[name] = task
+	}
+	cfg.Prerender = file.Prerender
+	cfg.Size = file.Size
+	cfg.Coverage = file.Coverage
+	cfg.Lint = file.Lint
+	cfg.Release = file.Release
+	cfg.Pack = file.Pack
+
+	return cfg, nil
+}
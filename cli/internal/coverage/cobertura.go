@@ -0,0 +1,187 @@
+package coverage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type cobertura struct {
+	XMLName         xml.Name       `xml:"coverage"`
+	LineRate        string         `xml:"line-rate,attr"`
+	BranchRate      string         `xml:"branch-rate,attr"`
+	LinesCovered    int            `xml:"lines-covered,attr"`
+	LinesValid      int            `xml:"lines-valid,attr"`
+	BranchesCovered int            `xml:"branches-covered,attr"`
+	BranchesValid   int            `xml:"branches-valid,attr"`
+	Complexity      int            `xml:"complexity,attr"`
+	Version         string         `xml:"version,attr"`
+	Timestamp       int64          `xml:"timestamp,attr"`
+	Sources         []string       `xml:"sources>source"`
+	Packages        []coberturaPkg `xml:"packages>package"`
+}
+
+type coberturaPkg struct {
+	Name       string           `xml:"name,attr"`
+	LineRate   string           `xml:"line-rate,attr"`
+	BranchRate string           `xml:"branch-rate,attr"`
+	Complexity int              `xml:"complexity,attr"`
+	Classes    []coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string            `xml:"name,attr"`
+	Filename   string            `xml:"filename,attr"`
+	LineRate   string            `xml:"line-rate,attr"`
+	BranchRate string            `xml:"branch-rate,attr"`
+	Complexity int               `xml:"complexity,attr"`
+	Methods    []coberturaMethod `xml:"methods>method"`
+	Lines      []coberturaLine   `xml:"lines>line"`
+}
+
+type coberturaMethod struct {
+	Name       string          `xml:"name,attr"`
+	Hits       int             `xml:"hits,attr"`
+	Signature  string          `xml:"signature,attr"`
+	LineRate   string          `xml:"line-rate,attr"`
+	BranchRate string          `xml:"branch-rate,attr"`
+	Complexity int             `xml:"complexity,attr"`
+	Lines      []coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              int    `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+}
+
+// WriteCobertura writes m as a Cobertura XML report with one package per
+// workspace package and paths relative to root
+func WriteCobertura(w io.Writer, m *Merged, root string) error {
+	total := m.Summary()
+	report := cobertura{
+		LineRate:        rate(total.Lines),
+		BranchRate:      rate(total.Branches),
+		LinesCovered:    total.Lines.Covered,
+		LinesValid:      total.Lines.Total,
+		BranchesCovered: total.Branches.Covered,
+		BranchesValid:   total.Branches.Total,
+		Version:         "0.1",
+		Timestamp:       time.Now().UnixMilli(),
+		Sources:         []string{root},
+	}
+
+	byPackage := make(map[string][]*File)
+	for _, f := range m.Sorted() {
+		byPackage[m.Owner[f.Path]] = append(byPackage[m.Owner[f.Path]], f)
+	}
+	for _, name := range m.Packages() {
+		var sum Summary
+		pkg := coberturaPkg{Name: name}
+		for _, f := range byPackage[name] {
+			s := f.Summary()
+			sum = sum.Add(s)
+			pkg.Classes = append(pkg.Classes, coberturaFile(f, s, Rel(root, f.Path)))
+		}
+		pkg.LineRate = rate(sum.Lines)
+		pkg.BranchRate = rate(sum.Branches)
+		report.Packages = append(report.Packages, pkg)
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func coberturaFile(f *File, s Summary, rel string) coberturaClass {
+	class := coberturaClass{
+		Name:       strings.TrimSuffix(path.Base(rel), path.Ext(rel)),
+		Filename:   rel,
+		LineRate:   rate(s.Lines),
+		BranchRate: rate(s.Branches),
+		Methods:    []coberturaMethod{},
+	}
+
+	// Branch outcomes per line
+	type outcomes struct{ covered, total int }
+	branches := make(map[int]*outcomes)
+	for id, b := range f.BranchMap {
+		o := branches[b.line()]
+		if o == nil {
+			o = &outcomes{}
+			branches[b.line()] = o
+		}
+		for _, hits := range f.B[id] {
+			o.total++
+			if hits > 0 {
+				o.covered++
+			}
+		}
+	}
+
+	hits := f.LineHits()
+	for _, id := range sortedIDs(f.FnMap) {
+		fn := f.FnMap[id]
+		line := fn.line()
+		class.Methods = append(class.Methods, coberturaMethod{
+			Name:       fn.Name,
+			Hits:       f.F[id],
+			Signature:  "()V",
+			LineRate:   fmt.Sprintf("%.4f", boolRate(f.F[id] > 0)),
+			BranchRate: fmt.Sprintf("%.4f", boolRate(f.F[id] > 0)),
+			Lines:      []coberturaLine{{Number: line, Hits: f.F[id]}},
+		})
+	}
+
+	lines := make([]int, 0, len(hits))
+	for line := range hits {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	for _, n := range lines {
+		line := coberturaLine{Number: n, Hits: hits[n]}
+		if o := branches[n]; o != nil && o.total > 0 {
+			line.Branch = true
+			line.ConditionCoverage = fmt.Sprintf("%d%% (%d/%d)", o.covered*100/o.total, o.covered, o.total)
+		}
+		class.Lines = append(class.Lines, line)
+	}
+	return class
+}
+
+// rate is a Cobertura rate between 0 and 1
+func rate(m Metrics) string {
+	if m.Total == 0 {
+		return "1"
+	}
+	return fmt.Sprintf("%.4f", float64(m.Covered)/float64(m.Total))
+}
+
+func boolRate(covered bool) float64 {
+	if covered {
+		return 1
+	}
+	return 0
+}
+
+// Rel returns path relative to root with forward slashes, or path itself
+// when it lies outside root
+func Rel(root, p string) string {
+	rel, err := filepath.Rel(root, p)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(rel)
+}
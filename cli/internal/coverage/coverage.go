@@ -0,0 +1,267 @@
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Files written by vitest's v8 provider, relative to the package directory
+const (
+	SummaryFile = "coverage/coverage-summary.json"
+	FinalFile   = "coverage/coverage-final.json"
+)
+
+// Metrics counts covered items, as in istanbul's json-summary
+type Metrics struct {
+	Total   int     `json:"total"`
+	Covered int     `json:"covered"`
+	Skipped int     `json:"skipped"`
+	Pct     float64 `json:"pct"`
+}
+
+// Add returns the sum of m and o with the percentage recomputed
+func (m Metrics) Add(o Metrics) Metrics {
+	sum := Metrics{Total: m.Total + o.Total, Covered: m.Covered + o.Covered, Skipped: m.Skipped + o.Skipped}
+	sum.Pct = percent(sum.Covered, sum.Total)
+	return sum
+}
+
+// percent rounds like istanbul; nothing to cover counts as fully covered
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return math.Floor(float64(covered)/float64(total)*10000) / 100
+}
+
+// FormatPercent formats a percentage without trailing zeros
+func FormatPercent(pct float64) string {
+	s := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", pct), "0"), ".")
+	return s + "%"
+}
+
+// Summary is the coverage of a file or a set of files
+type Summary struct {
+	Lines      Metrics `json:"lines"`
+	Statements Metrics `json:"statements"`
+	Functions  Metrics `json:"functions"`
+	Branches   Metrics `json:"branches"`
+}
+
+// Add returns the sum of s and o
+func (s Summary) Add(o Summary) Summary {
+	return Summary{
+		Lines:      s.Lines.Add(o.Lines),
+		Statements: s.Statements.Add(o.Statements),
+		Functions:  s.Functions.Add(o.Functions),
+		Branches:   s.Branches.Add(o.Branches),
+	}
+}
+
+// Location is a position in a source file
+type Location struct {
+	Line   int  `json:"line"`
+	Column *int `json:"column"`
+}
+
+// Range is a span in a source file
+type Range struct {
+	Start Location `json:"start"`
+	End   Location `json:"end"`
+}
+
+// Function is an entry of an istanbul fnMap
+type Function struct {
+	Name string `json:"name"`
+	Decl Range  `json:"decl"`
+	Loc  Range  `json:"loc"`
+	Line int    `json:"line"`
+}
+
+// Branch is an entry of an istanbul branchMap
+type Branch struct {
+	Type      string  `json:"type"`
+	Loc       Range   `json:"loc"`
+	Locations []Range `json:"locations"`
+	Line      int     `json:"line"`
+}
+
+// File is the istanbul coverage of one source file, as written to
+// coverage-final.json
+type File struct {
+	Path         string              `json:"path"`
+	StatementMap map[string]Range    `json:"statementMap"`
+	FnMap        map[string]Function `json:"fnMap"`
+	BranchMap    map[string]Branch   `json:"branchMap"`
+	S            map[string]int      `json:"s"`
+	F            map[string]int      `json:"f"`
+	B            map[string][]int    `json:"b"`
+}
+
+// LineHits returns the execution count of every line with a statement
+func (f *File) LineHits() map[int]int {
+	lines := make(map[int]int)
+	for id, r := range f.StatementMap {
+		line := r.Start.Line
+		if hits, ok := lines[line]; !ok || f.S[id] > hits {
+			lines[line] = f.S[id]
+		}
+	}
+	return lines
+}
+
+// Summary computes the file's totals
+func (f *File) Summary() Summary {
+	var s Summary
+	for id := range f.StatementMap {
+		s.Statements.Total++
+		if f.S[id] > 0 {
+			s.Statements.Covered++
+		}
+	}
+	for _, hits := range f.LineHits() {
+		s.Lines.Total++
+		if hits > 0 {
+			s.Lines.Covered++
+		}
+	}
+	for id := range f.FnMap {
+		s.Functions.Total++
+		if f.F[id] > 0 {
+			s.Functions.Covered++
+		}
+	}
+	for _, counts := range f.B {
+		for _, hits := range counts {
+			s.Branches.Total++
+			if hits > 0 {
+				s.Branches.Covered++
+			}
+		}
+	}
+	s.Lines.Pct = percent(s.Lines.Covered, s.Lines.Total)
+	s.Statements.Pct = percent(s.Statements.Covered, s.Statements.Total)
+	s.Functions.Pct = percent(s.Functions.Covered, s.Functions.Total)
+	s.Branches.Pct = percent(s.Branches.Covered, s.Branches.Total)
+	return s
+}
+
+// LoadFinal reads a coverage-final.json, keyed by absolute source path
+func LoadFinal(path string) (map[string]*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var files map[string]*File
+	if err := json.Unmarshal(data, &files); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for key, f := range files {
+		if f.Path == "" {
+			f.Path = key
+		}
+	}
+	return files, nil
+}
+
+// WriteFinal writes files as a coverage-final.json keyed by their paths
+func WriteFinal(path string, files map[string]*File) error {
+	byPath := make(map[string]*File, len(files))
+	for _, f := range files {
+		byPath[f.Path] = f
+	}
+	data, err := json.Marshal(byPath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadSummary reads a coverage-summary.json: the total and each file's
+// summary by absolute path
+func LoadSummary(path string) (Summary, map[string]Summary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Summary{}, nil, err
+	}
+	var entries map[string]Summary
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return Summary{}, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	total := entries["total"]
+	delete(entries, "total")
+	return total, entries, nil
+}
+
+// Package is the coverage a package's last test run left behind
+type Package struct {
+	Total Summary
+	Files map[string]Summary
+
+	// Final holds the detailed coverage when coverage-final.json or
+	// lcov.info exists
+	Final map[string]*File
+
+	// Modified is when the coverage was written
+	Modified time.Time
+}
+
+// LoadPackage reads the coverage of the package in dir, preferring the
+// detailed coverage-final.json, then lcov.info, over coverage-summary.json.
+// ok is false when there is none of them.
+func LoadPackage(dir string) (pkg *Package, ok bool, err error) {
+	path := filepath.Join(dir, FinalFile)
+	final, err := LoadFinal(path)
+	if os.IsNotExist(err) {
+		path = filepath.Join(dir, LcovFile)
+		final, err = LoadLcov(path, dir)
+	}
+	if err == nil {
+		pkg := &Package{Files: make(map[string]Summary), Final: final, Modified: modTime(path)}
+		for _, name := range sortedKeys(final) {
+			s := final[name].Summary()
+			pkg.Files[name] = s
+			pkg.Total = pkg.Total.Add(s)
+		}
+		return pkg, true, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, false, err
+	}
+
+	path = filepath.Join(dir, SummaryFile)
+	total, files, err := LoadSummary(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &Package{Total: total, Files: files, Modified: modTime(path)}, true, nil
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+func sortedKeys(files map[string]*File) []string {
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,226 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LcovFile is written by vitest's lcov reporter, relative to the package
+// directory
+const LcovFile = "coverage/lcov.info"
+
+// LoadLcov reads an lcov tracefile into the istanbul model: one statement
+// per DA line, one function per FN record and one branch per BRDA block.
+// Relative source paths are resolved against dir.
+func LoadLcov(path, dir string) (map[string]*File, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	files := make(map[string]*File)
+	var f *File
+	var fnIDs map[string]string
+	var blocks map[string]string
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, _ := strings.Cut(line, ":")
+		fields := strings.Split(value, ",")
+
+		if key == "SF" {
+			source := value
+			if !filepath.IsAbs(source) {
+				source = filepath.Join(dir, filepath.FromSlash(source))
+			}
+			f = newFile(source)
+			fnIDs = make(map[string]string)
+			blocks = make(map[string]string)
+			continue
+		}
+		if f == nil || line == "" {
+			continue
+		}
+
+		bad := func() error { return fmt.Errorf("%s:%d: invalid %s record", path, n, key) }
+		switch key {
+		case "FN":
+			if len(fields) < 2 {
+				return nil, bad()
+			}
+			ln, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, bad()
+			}
+			name := strings.Join(fields[1:], ",")
+			id := strconv.Itoa(len(f.FnMap))
+			loc := Range{Start: Location{Line: ln}, End: Location{Line: ln}}
+			f.FnMap[id] = Function{Name: name, Decl: loc, Loc: loc, Line: ln}
+			f.F[id] = 0
+			fnIDs[name] = id
+		case "FNDA":
+			if len(fields) < 2 {
+				return nil, bad()
+			}
+			hits, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, bad()
+			}
+			if id, ok := fnIDs[strings.Join(fields[1:], ",")]; ok {
+				f.F[id] += hits
+			}
+		case "BRDA":
+			if len(fields) != 4 {
+				return nil, bad()
+			}
+			ln, err := strconv.Atoi(fields[0])
+			if err != nil {
+				return nil, bad()
+			}
+			block := fields[0] + "," + fields[1]
+			id, ok := blocks[block]
+			if !ok {
+				id = strconv.Itoa(len(f.BranchMap))
+				blocks[block] = id
+				loc := Range{Start: Location{Line: ln}, End: Location{Line: ln}}
+				f.BranchMap[id] = Branch{Type: "branch", Loc: loc, Line: ln}
+			}
+			b := f.BranchMap[id]
+			b.Locations = append(b.Locations, b.Loc)
+			f.BranchMap[id] = b
+			taken := 0
+			if fields[3] != "-" {
+				if taken, err = strconv.Atoi(fields[3]); err != nil {
+					return nil, bad()
+				}
+			}
+			f.B[id] = append(f.B[id], taken)
+		case "DA":
+			if len(fields) < 2 {
+				return nil, bad()
+			}
+			ln, err1 := strconv.Atoi(fields[0])
+			hits, err2 := strconv.Atoi(fields[1])
+			if err1 != nil || err2 != nil {
+				return nil, bad()
+			}
+			id := strconv.Itoa(len(f.StatementMap))
+			f.StatementMap[id] = Range{Start: Location{Line: ln}, End: Location{Line: ln}}
+			f.S[id] = hits
+		case "end_of_record":
+			if existing, ok := files[f.Path]; ok {
+				existing.Merge(f)
+			} else {
+				files[f.Path] = f
+			}
+			f = nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func newFile(path string) *File {
+	return &File{
+		Path:         path,
+		StatementMap: make(map[string]Range),
+		FnMap:        make(map[string]Function),
+		BranchMap:    make(map[string]Branch),
+		S:            make(map[string]int),
+		F:            make(map[string]int),
+		B:            make(map[string][]int),
+	}
+}
+
+// WriteLcov writes files as an lcov tracefile, naming each source with
+// name(path)
+func WriteLcov(w io.Writer, files []*File, name func(string) string) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range files {
+		fmt.Fprintf(bw, "TN:\nSF:%s\n", name(f.Path))
+
+		fnHit := 0
+		for _, id := range sortedIDs(f.FnMap) {
+			fn := f.FnMap[id]
+			fmt.Fprintf(bw, "FN:%d,%s\n", fn.line(), fn.Name)
+		}
+		for _, id := range sortedIDs(f.FnMap) {
+			fmt.Fprintf(bw, "FNDA:%d,%s\n", f.F[id], f.FnMap[id].Name)
+			if f.F[id] > 0 {
+				fnHit++
+			}
+		}
+		fmt.Fprintf(bw, "FNF:%d\nFNH:%d\n", len(f.FnMap), fnHit)
+
+		brFound, brHit := 0, 0
+		for _, id := range sortedIDs(f.BranchMap) {
+			line := f.BranchMap[id].line()
+			for i, taken := range f.B[id] {
+				fmt.Fprintf(bw, "BRDA:%d,%s,%d,%d\n", line, id, i, taken)
+				brFound++
+				if taken > 0 {
+					brHit++
+				}
+			}
+		}
+		fmt.Fprintf(bw, "BRF:%d\nBRH:%d\n", brFound, brHit)
+
+		hits := f.LineHits()
+		lines := make([]int, 0, len(hits))
+		for line := range hits {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+		lineHit := 0
+		for _, line := range lines {
+			fmt.Fprintf(bw, "DA:%d,%d\n", line, hits[line])
+			if hits[line] > 0 {
+				lineHit++
+			}
+		}
+		fmt.Fprintf(bw, "LF:%d\nLH:%d\nend_of_record\n", len(lines), lineHit)
+	}
+	return bw.Flush()
+}
+
+func (fn Function) line() int {
+	if fn.Line > 0 {
+		return fn.Line
+	}
+	return fn.Decl.Start.Line
+}
+
+func (b Branch) line() int {
+	if b.Line > 0 {
+		return b.Line
+	}
+	return b.Loc.Start.Line
+}
+
+// sortedIDs orders istanbul map keys, which are numbers
+func sortedIDs[T any](m map[string]T) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, errA := strconv.Atoi(ids[i])
+		b, errB := strconv.Atoi(ids[j])
+		if errA != nil || errB != nil {
+			return ids[i] < ids[j]
+		}
+		return a < b
+	})
+	return ids
+}
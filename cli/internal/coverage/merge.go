@@ -0,0 +1,99 @@
+package coverage
+
+import "sort"
+
+// Merge adds o's hit counts to f. Both must describe the same source, as
+// when several packages' tests cover a shared file.
+func (f *File) Merge(o *File) {
+	for id, r := range o.StatementMap {
+		if _, ok := f.StatementMap[id]; !ok {
+			f.StatementMap[id] = r
+		}
+		f.S[id] += o.S[id]
+	}
+	for id, fn := range o.FnMap {
+		if _, ok := f.FnMap[id]; !ok {
+			f.FnMap[id] = fn
+		}
+		f.F[id] += o.F[id]
+	}
+	for id, b := range o.BranchMap {
+		if _, ok := f.BranchMap[id]; !ok {
+			f.BranchMap[id] = b
+		}
+		counts := f.B[id]
+		for i, hits := range o.B[id] {
+			if i < len(counts) {
+				counts[i] += hits
+			} else {
+				counts = append(counts, hits)
+			}
+		}
+		f.B[id] = counts
+	}
+}
+
+// Merged combines the detailed coverage of several packages
+type Merged struct {
+	Files map[string]*File
+
+	// Owner maps each source path to the first package that reported it
+	Owner map[string]string
+}
+
+// NewMerged returns an empty Merged
+func NewMerged() *Merged {
+	return &Merged{Files: make(map[string]*File), Owner: make(map[string]string)}
+}
+
+// Add merges a package's files
+func (m *Merged) Add(pkg string, files map[string]*File) {
+	for _, path := range sortedKeys(files) {
+		f := files[path]
+		if existing, ok := m.Files[path]; ok {
+			existing.Merge(f)
+			continue
+		}
+		m.Files[path] = f.clone()
+		m.Owner[path] = pkg
+	}
+}
+
+// Sorted returns the files ordered by path
+func (m *Merged) Sorted() []*File {
+	files := make([]*File, 0, len(m.Files))
+	for _, path := range sortedKeys(m.Files) {
+		files = append(files, m.Files[path])
+	}
+	return files
+}
+
+// Summary totals the merged files
+func (m *Merged) Summary() Summary {
+	var s Summary
+	for _, f := range m.Files {
+		s = s.Add(f.Summary())
+	}
+	return s
+}
+
+// clone copies f so merging into it leaves the package's data untouched
+func (f *File) clone() *File {
+	c := newFile(f.Path)
+	c.Merge(f)
+	return c
+}
+
+// Packages returns the package names in m, sorted
+func (m *Merged) Packages() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range m.Owner {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,55 @@
+package coverage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MetricNames are the keys of a thresholds map
+var MetricNames = []string{"lines", "statements", "functions", "branches"}
+
+// Metric returns a metric by name
+func (s Summary) Metric(name string) (Metrics, bool) {
+	switch name {
+	case "lines":
+		return s.Lines, true
+	case "statements":
+		return s.Statements, true
+	case "functions":
+		return s.Functions, true
+	case "branches":
+		return s.Branches, true
+	}
+	return Metrics{}, false
+}
+
+// Breach is a coverage percentage below its threshold
+type Breach struct {
+	// Scope is a package name, or empty for the whole workspace
+	Scope  string
+	Metric string
+	Actual float64
+	Min    float64
+}
+
+// CheckThresholds compares s with minimum percentages keyed by metric name
+func CheckThresholds(scope string, s Summary, min map[string]float64) ([]Breach, error) {
+	names := make([]string, 0, len(min))
+	for name := range min {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var breaches []Breach
+	for _, name := range names {
+		m, ok := s.Metric(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown coverage metric %q, expected one of %s", name, strings.Join(MetricNames, ", "))
+		}
+		if m.Pct < min[name] {
+			breaches = append(breaches, Breach{Scope: scope, Metric: name, Actual: m.Pct, Min: min[name]})
+		}
+	}
+	return breaches, nil
+}
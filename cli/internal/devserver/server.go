@@ -0,0 +1,322 @@
+package devserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"sync"
+	"syscall"
+
+	"github.com/kluth/solidum-cli/internal/proc"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// Status is the state of a dev server
+type Status string
+
+const (
+	Starting Status = "starting"
+	Ready    Status = "ready"
+	Error    Status = "error"
+	Stopped  Status = "stopped"
+)
+
+// MaxLines is how many log lines are kept per server
+const MaxLines = 1000
+
+var (
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+	urlPattern  = regexp.MustCompile(`https?://(?:localhost|127\.0\.0\.1|0\.0\.0\.0|\[::1?\]|[\w.-]+):(\d+)[^\s]*`)
+
+	// Watch builds without a server (tsc --watch, vite build --watch) are
+	// ready once the first build is done
+	watchPattern = regexp.MustCompile(`(?i)watching for (file )?changes`)
+
+	vitePattern = regexp.MustCompile(`(^|[\s;&|/])vite(\s|$)`)
+)
+
+// Server is the dev process of one package
+type Server struct {
+	Package *workspace.Package
+
+	// Dir is the absolute package directory
+	Dir string
+
+	// Port is assigned by the CLI and passed as PORT and SOLIDUM_PORT, and
+	// to vite as --port
+	Port int
+
+	mu       sync.Mutex
+	status   Status
+	url      string
+	port     int
+	lines    []string
+	err      error
+	restarts int
+	proc     *proc.Process
+	restart  bool
+
+	// portErr is set when the server announced another port than Port
+	portErr error
+}
+
+// Name returns the package name
+func (s *Server) Name() string {
+	return s.Package.Name
+}
+
+// Snapshot is a copy of a server's state for display
+type Snapshot struct {
+	Name     string
+	Status   Status
+	URL      string
+	Port     int
+	Lines    []string
+	Err      error
+	Restarts int
+}
+
+// Snapshot returns the current state of the server. Port is the announced
+// port, or the assigned one until the server announces its URL.
+func (s *Server) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	port := s.port
+	if port == 0 {
+		port = s.Port
+	}
+	return Snapshot{
+		Name:     s.Package.Name,
+		Status:   s.status,
+		URL:      s.url,
+		Port:     port,
+		Lines:    append([]string(nil), s.lines...),
+		Err:      s.err,
+		Restarts: s.restarts,
+	}
+}
+
+// addLine records a line of output and picks up the URL the server
+// announces, e.g. "  ➜  Local:   http://localhost:5173/". It reports
+// whether the server just became ready.
+func (s *Server) addLine(raw string) bool {
+	line := ansiPattern.ReplaceAllString(raw, "")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lines = append(s.lines, line)
+	if len(s.lines) > MaxLines {
+		s.lines = s.lines[len(s.lines)-MaxLines:]
+	}
+
+	if s.url == "" {
+		if m := urlPattern.FindStringSubmatch(line); m != nil {
+			s.url = m[0]
+			s.port, _ = strconv.Atoi(m[1])
+			s.status = Ready
+			if s.Port > 0 && s.port != s.Port {
+				// Another package may have been given that port
+				s.portErr = fmt.Errorf("listens on port %d instead of its assigned port %d; make the dev script use $PORT", s.port, s.Port)
+				s.status, s.err = Error, s.portErr
+				if s.proc != nil {
+					go s.proc.Stop(syscall.SIGTERM, proc.Grace)
+				}
+			}
+			return true
+		}
+	}
+	if s.status == Starting && watchPattern.MatchString(line) {
+		s.status = Ready
+		return true
+	}
+	return false
+}
+
+// PortArgs returns the arguments that make the dev script of pkg listen on
+// port. vite is told directly and exits rather than pick another port;
+// other servers are expected to read PORT.
+func PortArgs(pkg *workspace.Package, port int) []string {
+	if port <= 0 || !vitePattern.MatchString(pkg.Scripts["dev"]) {
+		return nil
+	}
+	return []string{"--", "--port", strconv.Itoa(port), "--strictPort"}
+}
+
+// run starts the dev script and keeps restarting it on request until ctx
+// is done or the process exits on its own
+func (s *Server) run(ctx context.Context, m *Manager) {
+	for {
+		cmd := exec.Command("pnpm", append([]string{"run", "dev"}, PortArgs(s.Package, s.Port)...)...)
+		cmd.Dir = s.Dir
+		cmd.Env = os.Environ()
+		if s.Port > 0 {
+			port := strconv.Itoa(s.Port)
+			cmd.Env = append(cmd.Env, "PORT="+port, "SOLIDUM_PORT="+port)
+		}
+
+		w := &lineWriter{fn: func(line string) {
+			ready := s.addLine(line)
+			if m.Output != nil {
+				m.Output(s, line)
+			}
+			if ready && m.OnReady != nil {
+				m.OnReady(s)
+			}
+		}}
+		cmd.Stdout = w
+		cmd.Stderr = w
+
+		s.mu.Lock()
+		s.status, s.url, s.port, s.err, s.portErr = Starting, "", 0, nil, nil
+		s.mu.Unlock()
+
+		p, err := proc.Start(s.Package.Name, cmd)
+		if err != nil {
+			s.mu.Lock()
+			s.status, s.err = Error, err
+			s.mu.Unlock()
+			return
+		}
+
+		s.mu.Lock()
+		s.proc = p
+		if s.portErr != nil {
+			go p.Stop(syscall.SIGTERM, proc.Grace)
+		}
+		s.mu.Unlock()
+
+		err = p.Wait(ctx)
+		w.Flush()
+
+		s.mu.Lock()
+		restart := s.restart && ctx.Err() == nil
+		s.proc, s.restart = nil, false
+		if restart {
+			s.restarts++
+			s.lines = append(s.lines, "── restarting ──")
+			s.mu.Unlock()
+			continue
+		}
+		if s.portErr != nil {
+			s.status, s.err = Error, s.portErr
+		} else if err != nil && ctx.Err() == nil {
+			s.status, s.err = Error, err
+		} else {
+			s.status = Stopped
+		}
+		s.mu.Unlock()
+		return
+	}
+}
+
+// stopForRestart stops the running process so run starts it again. It
+// reports false when the server is not running.
+func (s *Server) stopForRestart() bool {
+	s.mu.Lock()
+	p := s.proc
+	if p != nil {
+		s.restart = true
+	}
+	s.mu.Unlock()
+
+	if p == nil {
+		return false
+	}
+	go p.Stop(syscall.SIGTERM, proc.Grace)
+	return true
+}
+
+// Manager runs the dev servers of several packages side by side
+type Manager struct {
+	Servers []*Server
+
+	// Output, when set, receives every line of output as it is produced
+	Output func(s *Server, line string)
+
+	// OnReady, when set, is called each time a server becomes ready
+	OnReady func(s *Server)
+
+	ctx context.Context
+	wg  sync.WaitGroup
+}
+
+// NewManager prepares a server for every package with a dev script
+func NewManager(ws *workspace.Workspace, pkgs []*workspace.Package) *Manager {
+	m := &Manager{}
+	for _, pkg := range pkgs {
+		if !pkg.HasScript("dev") {
+			continue
+		}
+		m.Servers = append(m.Servers, &Server{
+			Package: pkg,
+			Dir:     ws.Path(pkg.Dir),
+			status:  Starting,
+		})
+	}
+	return m
+}
+
+// Start launches every server. They stop when ctx is done.
+func (m *Manager) Start(ctx context.Context) {
+	m.ctx = ctx
+	for _, s := range m.Servers {
+		m.launch(s)
+	}
+}
+
+func (m *Manager) launch(s *Server) {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		s.run(m.ctx, m)
+	}()
+}
+
+// Restart restarts a server, or starts it again if it already exited
+func (m *Manager) Restart(s *Server) {
+	if m.ctx.Err() != nil || s.stopForRestart() {
+		return
+	}
+	s.mu.Lock()
+	s.restarts++
+	s.lines = append(s.lines, "── restarting ──")
+	s.mu.Unlock()
+	m.launch(s)
+}
+
+// Wait blocks until every server has exited
+func (m *Manager) Wait() {
+	m.wg.Wait()
+}
+
+// lineWriter splits output into lines
+type lineWriter struct {
+	fn  func(string)
+	buf []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.fn(string(bytes.TrimRight(w.buf[:i], "\r")))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush emits a trailing line that did not end in a newline
+func (w *lineWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.fn(string(w.buf))
+		w.buf = nil
+	}
+}
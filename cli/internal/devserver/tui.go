@@ -0,0 +1,258 @@
+package devserver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	tabStyle       = lipgloss.NewStyle().Padding(0, 1)
+	activeTabStyle = lipgloss.NewStyle().Padding(0, 1).Bold(true).Reverse(true)
+	faintStyle     = lipgloss.NewStyle().Faint(true)
+	urlStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Underline(true)
+	matchStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Bold(true)
+
+	statusStyles = map[Status]lipgloss.Style{
+		Starting: lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		Ready:    lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		Error:    lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Bold(true),
+		Stopped:  lipgloss.NewStyle().Faint(true),
+	}
+	statusIcons = map[Status]string{
+		Starting: "◌",
+		Ready:    "●",
+		Error:    "✗",
+		Stopped:  "○",
+	}
+)
+
+// RunTUI starts the servers and shows a dashboard with one tab per package
+// until the user quits or ctx is done. The servers are stopped on return.
+func RunTUI(ctx context.Context, m *Manager) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	m.Start(ctx)
+
+	_, err := tea.NewProgram(&dashboard{ctx: ctx, manager: m}, tea.WithAltScreen()).Run()
+
+	cancel()
+	m.Wait()
+	return err
+}
+
+type tickMsg time.Time
+
+func tick() tea.Cmd {
+	return tea.Tick(150*time.Millisecond, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// dashboard is the Bubble Tea model for `solidum dev --all`
+type dashboard struct {
+	ctx     context.Context
+	manager *Manager
+
+	selected  int
+	filter    string
+	filtering bool
+	message   string
+	width     int
+	height    int
+}
+
+func (d *dashboard) Init() tea.Cmd {
+	return tick()
+}
+
+func (d *dashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		d.width, d.height = msg.Width, msg.Height
+
+	case tickMsg:
+		if d.ctx.Err() != nil {
+			return d, tea.Quit
+		}
+		return d, tick()
+
+	case tea.KeyMsg:
+		if d.filtering {
+			d.editFilter(msg)
+			return d, nil
+		}
+		return d, d.handleKey(msg)
+	}
+	return d, nil
+}
+
+func (d *dashboard) editFilter(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		d.filtering = false
+	case tea.KeyEsc:
+		d.filter, d.filtering = "", false
+	case tea.KeyBackspace:
+		if r := []rune(d.filter); len(r) > 0 {
+			d.filter = string(r[:len(r)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		d.filter += string(msg.Runes)
+	}
+}
+
+func (d *dashboard) handleKey(msg tea.KeyMsg) tea.Cmd {
+	servers := d.manager.Servers
+	current := servers[d.selected]
+	d.message = ""
+
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "tab", "right", "l":
+		d.selected = (d.selected + 1) % len(servers)
+	case "shift+tab", "left", "h":
+		d.selected = (d.selected + len(servers) - 1) % len(servers)
+	case "r":
+		d.manager.Restart(current)
+		d.message = "Restarting " + current.Name()
+	case "R":
+		for _, s := range servers {
+			d.manager.Restart(s)
+		}
+		d.message = "Restarting all servers"
+	case "o":
+		snap := current.Snapshot()
+		if snap.URL == "" {
+			d.message = current.Name() + " has no URL yet"
+		} else if err := OpenBrowser(snap.URL); err != nil {
+			d.message = "Could not open browser: " + err.Error()
+		} else {
+			d.message = "Opened " + snap.URL
+		}
+	case "/":
+		d.filtering = true
+	case "esc":
+		d.filter = ""
+	default:
+		if len(msg.Runes) == 1 && msg.Runes[0] >= '1' && msg.Runes[0] <= '9' {
+			if i := int(msg.Runes[0] - '1'); i < len(servers) {
+				d.selected = i
+			}
+		}
+	}
+	return nil
+}
+
+func (d *dashboard) View() string {
+	if d.width == 0 {
+		return ""
+	}
+
+	snaps := make([]Snapshot, len(d.manager.Servers))
+	for i, s := range d.manager.Servers {
+		snaps[i] = s.Snapshot()
+	}
+	current := snaps[d.selected]
+
+	var b strings.Builder
+
+	// Tabs
+	var tabs []string
+	for i, s := range snaps {
+		label := statusStyles[s.Status].Render(statusIcons[s.Status]) + " " + s.Name
+		if s.Port > 0 {
+			label += fmt.Sprintf(" :%d", s.Port)
+		}
+		if i == d.selected {
+			tabs = append(tabs, activeTabStyle.Render(label))
+		} else {
+			tabs = append(tabs, tabStyle.Render(label))
+		}
+	}
+	b.WriteString(lipgloss.NewStyle().MaxWidth(d.width).Render(strings.Join(tabs, "")))
+	b.WriteString("\n")
+
+	// Details of the selected server
+	details := statusStyles[current.Status].Render(string(current.Status))
+	if current.URL != "" {
+		details += "  " + urlStyle.Render(current.URL)
+	}
+	if current.Restarts > 0 {
+		details += faintStyle.Render(fmt.Sprintf("  restarted %d×", current.Restarts))
+	}
+	if current.Err != nil {
+		details += "  " + statusStyles[Error].Render(current.Err.Error())
+	}
+	b.WriteString(" " + details + "\n")
+	b.WriteString(faintStyle.Render(strings.Repeat("─", d.width)) + "\n")
+
+	// Logs, newest at the bottom
+	lines := current.Lines
+	if d.filter != "" {
+		lines = filterLines(lines, d.filter)
+	}
+	height := d.height - 5
+	if height < 1 {
+		height = 1
+	}
+	if len(lines) > height {
+		lines = lines[len(lines)-height:]
+	}
+	lineStyle := lipgloss.NewStyle().MaxWidth(d.width)
+	for _, line := range lines {
+		if d.filter != "" {
+			line = highlight(line, d.filter)
+		}
+		b.WriteString(lineStyle.Render(line) + "\n")
+	}
+	for i := len(lines); i < height; i++ {
+		b.WriteString("\n")
+	}
+
+	// Footer
+	b.WriteString(faintStyle.Render(strings.Repeat("─", d.width)) + "\n")
+	switch {
+	case d.filtering:
+		b.WriteString(" filter: " + d.filter + "█")
+	case d.message != "":
+		b.WriteString(" " + d.message)
+	default:
+		help := "←/→ switch  1-9 jump  r restart  R restart all  o open  / filter  q quit"
+		if d.filter != "" {
+			help = "filter: " + matchStyle.Render(d.filter) + "  esc clear  " + help
+		}
+		b.WriteString(faintStyle.Render(" " + help))
+	}
+
+	return b.String()
+}
+
+// filterLines keeps the lines containing filter, ignoring case
+func filterLines(lines []string, filter string) []string {
+	needle := strings.ToLower(filter)
+	var matched []string
+	for _, line := range lines {
+		if strings.Contains(strings.ToLower(line), needle) {
+			matched = append(matched, line)
+		}
+	}
+	return matched
+}
+
+// highlight marks the first match of filter in line
+func highlight(line, filter string) string {
+	i := strings.Index(strings.ToLower(line), strings.ToLower(filter))
+	if i < 0 {
+		return line
+	}
+	end := i + len(filter)
+	if end > len(line) {
+		return line
+	}
+	return line[:i] + matchStyle.Render(line[i:end]) + line[end:]
+}
@@ -0,0 +1,323 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kluth/solidum-cli/internal/semver"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// Status is the outcome of a single check
+type Status string
+
+const (
+	Pass Status = "pass"
+	Warn Status = "warn"
+	Fail Status = "fail"
+)
+
+// Check is one line of the doctor report
+type Check struct {
+	Category string `json:"category"`
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Message  string `json:"message"`
+	Fix      string `json:"fix,omitempty"`
+}
+
+// Report collects the results of every check
+type Report struct {
+	Root   string  `json:"root"`
+	Checks []Check `json:"checks"`
+}
+
+// Count returns how many checks ended with the given status
+func (r *Report) Count(status Status) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) add(category, name string, status Status, message, fix string) {
+	r.Checks = append(r.Checks, Check{
+		Category: category,
+		Name:     name,
+		Status:   status,
+		Message:  message,
+		Fix:      fix,
+	})
+}
+
+// RequiredScripts are the package scripts the CLI commands delegate to
+var RequiredScripts = []string{"build", "test", "lint", "typecheck"}
+
+// MinGitVersion is the oldest git that supports core.hooksPath
+const MinGitVersion = "2.9.0"
+
+// Run performs every check against the workspace
+func Run(ws *workspace.Workspace) *Report {
+	r := &Report{Root: ws.Root}
+
+	checkTools(r, ws)
+	if ws.IsMonorepo() {
+		checkGlobs(r, ws)
+		checkVersionSkew(r, ws)
+	}
+	checkScripts(r, ws)
+	checkDist(r, ws)
+
+	return r
+}
+
+func checkTools(r *Report, ws *workspace.Workspace) {
+	nodeRange, pnpmRange, packageManager := "", "", ""
+	if root := ws.RootPackage; root != nil {
+		nodeRange = root.Engines["node"]
+		pnpmRange = root.Engines["pnpm"]
+		packageManager = root.PackageManager
+	}
+
+	checkTool(r, "node", []string{"--version"}, nodeRange, "Install Node.js from https://nodejs.org or switch with nvm")
+	checkTool(r, "pnpm", []string{"--version"}, pnpmRange, "Run 'corepack enable' or 'npm install -g pnpm'")
+	checkTool(r, "git", []string{"--version"}, ">="+MinGitVersion, "Install a recent git from https://git-scm.com")
+
+	if strings.HasPrefix(packageManager, "pnpm@") {
+		want := strings.TrimPrefix(packageManager, "pnpm@")
+		have, err := toolVersion("pnpm", "--version")
+		if err == nil && want != "" {
+			wantV, werr := semver.Parse(want)
+			haveV, herr := semver.Parse(have)
+			if werr == nil && herr == nil && wantV.Major != haveV.Major {
+				r.add("tools", "packageManager", Warn,
+					fmt.Sprintf("package.json pins pnpm@%s but pnpm %s is installed", want, have),
+					"Run 'corepack enable' so the pinned pnpm version is used")
+			}
+		}
+	}
+}
+
+func checkTool(r *Report, name string, args []string, rng, fix string) {
+	version, err := toolVersion(name, args...)
+	if err != nil {
+		r.add("tools", name, Fail, fmt.Sprintf("%s not found", name), fix)
+		return
+	}
+
+	if rng == "" {
+		r.add("tools", name, Pass, version, "")
+		return
+	}
+
+	v, err := semver.Parse(version)
+	if err != nil {
+		r.add("tools", name, Warn, fmt.Sprintf("could not parse version %q", version), "")
+		return
+	}
+
+	ok, err := semver.Satisfies(v, rng)
+	switch {
+	case err != nil:
+		r.add("tools", name, Warn, fmt.Sprintf("%s (invalid requirement %q)", version, rng), "")
+	case !ok:
+		r.add("tools", name, Fail, fmt.Sprintf("%s does not satisfy %s", version, rng), fix)
+	default:
+		r.add("tools", name, Pass, fmt.Sprintf("%s (requires %s)", version, rng), "")
+	}
+}
+
+var versionPattern = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+func toolVersion(name string, args ...string) (string, error) {
+	out, err := exec.Command(name, args...).Output()
+	if err != nil {
+		return "", err
+	}
+	match := versionPattern.FindString(string(out))
+	if match == "" {
+		return strings.TrimSpace(string(out)), nil
+	}
+	return match, nil
+}
+
+func checkGlobs(r *Report, ws *workspace.Workspace) {
+	for _, glob := range ws.Globs {
+		if strings.HasPrefix(glob, "!") {
+			continue
+		}
+		dirs, err := workspace.MatchGlob(ws.Root, glob)
+		if err != nil {
+			r.add("workspace", glob, Fail, fmt.Sprintf("invalid glob: %v", err), "Fix the pattern in pnpm-workspace.yaml")
+			continue
+		}
+
+		var withPackage, without []string
+		for _, dir := range dirs {
+			if _, err := os.Stat(ws.Path(dir, "package.json")); err == nil {
+				withPackage = append(withPackage, dir)
+			} else {
+				without = append(without, dir)
+			}
+		}
+
+		switch {
+		case len(withPackage) == 0:
+			r.add("workspace", glob, Warn, "matches no packages", "Remove the glob from pnpm-workspace.yaml or add a package")
+		case len(without) > 0:
+			r.add("workspace", glob, Warn,
+				fmt.Sprintf("%d package(s), but %s has no package.json", len(withPackage), strings.Join(without, ", ")),
+				"Add a package.json or exclude the directory with a '!' glob")
+		default:
+			r.add("workspace", glob, Pass, fmt.Sprintf("%d package(s)", len(withPackage)), "")
+		}
+	}
+
+	seen := make(map[string]string)
+	for _, pkg := range ws.Packages {
+		if other, ok := seen[pkg.Name]; ok {
+			r.add("workspace", pkg.Name, Fail,
+				fmt.Sprintf("name used by both %s and %s", other, pkg.Dir),
+				"Give every workspace package a unique name")
+		}
+		seen[pkg.Name] = pkg.Dir
+	}
+}
+
+func checkVersionSkew(r *Report, ws *workspace.Workspace) {
+	// Published packages are released together, so their versions should match
+	versions := make(map[string][]string)
+	for _, pkg := range ws.Packages {
+		if strings.HasPrefix(pkg.Dir, "packages/") && strings.HasPrefix(pkg.Name, "@sldm/") {
+			versions[pkg.Version] = append(versions[pkg.Version], pkg.Name)
+		}
+	}
+	if len(versions) > 1 {
+		var parts []string
+		for version, names := range versions {
+			sort.Strings(names)
+			parts = append(parts, fmt.Sprintf("%s: %s", version, strings.Join(names, ", ")))
+		}
+		sort.Strings(parts)
+		r.add("versions", "@sldm/* packages", Warn,
+			"package versions differ ("+strings.Join(parts, "; ")+")",
+			"Set the same \"version\" in every packages/*/package.json")
+	} else if len(versions) == 1 {
+		for version := range versions {
+			r.add("versions", "@sldm/* packages", Pass, "all at "+version, "")
+		}
+	}
+
+	// Dependency ranges must accept the version the workspace provides
+	skewed := 0
+	for _, pkg := range ws.Packages {
+		if !strings.HasPrefix(pkg.Dir, "packages/") && !strings.HasPrefix(pkg.Dir, "examples/") {
+			continue
+		}
+		deps := pkg.AllDependencies()
+		names := make([]string, 0, len(deps))
+		for name := range deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			spec := deps[name]
+			if !strings.HasPrefix(name, "@sldm/") || strings.HasPrefix(spec, "workspace:") {
+				continue
+			}
+			local, ok := ws.Lookup(name)
+			if !ok {
+				continue
+			}
+			v, err := semver.Parse(local.Version)
+			if err != nil {
+				continue
+			}
+			if ok, err := semver.Satisfies(v, spec); err == nil && !ok {
+				skewed++
+				r.add("versions", pkg.Name, Fail,
+					fmt.Sprintf("depends on %s@%s but the workspace has %s", name, spec, local.Version),
+					fmt.Sprintf("Use \"%s\": \"workspace:*\" in %s/package.json", name, pkg.Dir))
+			}
+		}
+	}
+	if skewed == 0 {
+		r.add("versions", "@sldm/* dependencies", Pass, "all ranges match the workspace", "")
+	}
+}
+
+func checkScripts(r *Report, ws *workspace.Workspace) {
+	for _, pkg := range ws.Packages {
+		if ws.IsMonorepo() && !strings.HasPrefix(pkg.Dir, "packages/") {
+			continue
+		}
+
+		var missing []string
+		for _, script := range RequiredScripts {
+			if !pkg.HasScript(script) {
+				missing = append(missing, script)
+			}
+		}
+		if len(missing) > 0 {
+			r.add("scripts", pkg.Name, Warn,
+				"missing "+strings.Join(missing, ", ")+" script(s)",
+				fmt.Sprintf("Add the missing script(s) to %s", filepath.Join(pkg.Dir, "package.json")))
+		}
+	}
+}
+
+func checkDist(r *Report, ws *workspace.Workspace) {
+	for _, pkg := range ws.Packages {
+		if ws.IsMonorepo() && !strings.HasPrefix(pkg.Dir, "packages/") {
+			continue
+		}
+
+		srcTime, srcFile := newest(ws.Path(pkg.Dir, "src"))
+		if srcFile == "" {
+			continue
+		}
+		distTime, distFile := newest(ws.Path(pkg.Dir, "dist"))
+
+		switch {
+		case distFile == "":
+			r.add("build", pkg.Name, Warn, "dist is missing", fmt.Sprintf("Run 'solidum build --package %s'", pkg.Name))
+		case srcTime.After(distTime):
+			rel, _ := filepath.Rel(ws.Root, srcFile)
+			r.add("build", pkg.Name, Warn,
+				fmt.Sprintf("dist is stale (%s changed after the last build)", rel),
+				fmt.Sprintf("Run 'solidum build --package %s'", pkg.Name))
+		default:
+			r.add("build", pkg.Name, Pass, "dist is up to date", "")
+		}
+	}
+}
+
+// newest returns the most recent modification time below dir
+func newest(dir string) (time.Time, string) {
+	var latest time.Time
+	var file string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() && info.Name() == "node_modules" {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && info.ModTime().After(latest) {
+			latest = info.ModTime()
+			file = path
+		}
+		return nil
+	})
+	return latest, file
+}
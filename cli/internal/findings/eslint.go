@@ -0,0 +1,84 @@
+package findings
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/junit"
+)
+
+// ESLintFile is where a package's ESLint JSON output is written, relative to
+// the package directory
+const ESLintFile = ".solidum/eslint.json"
+
+// eslintResult is one file in ESLint's json formatter output
+type eslintResult struct {
+	FilePath string `json:"filePath"`
+	Messages []struct {
+		RuleID   string `json:"ruleId"`
+		Severity int    `json:"severity"`
+		Message  string `json:"message"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+	} `json:"messages"`
+	ErrorCount   int `json:"errorCount"`
+	WarningCount int `json:"warningCount"`
+}
+
+// AddESLint adds the ESLint JSON output at path for pkg. Every linted file
+// becomes a test case that fails when it has errors. rel maps the absolute
+// paths ESLint reports to workspace-relative ones.
+func (r *Report) AddESLint(pkg, path string, rel func(string) string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var results []eslintResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	suite := &junit.Suite{Name: pkg}
+	for _, res := range results {
+		file := rel(res.FilePath)
+		c := &junit.Case{Name: file, Classname: pkg, File: file}
+
+		var lines []string
+		for _, m := range res.Messages {
+			level := Warning
+			if m.Severity >= 2 {
+				level = Error
+			}
+			rule := m.RuleID
+			if rule == "" {
+				// Parse errors have no rule
+				rule = "eslint"
+			}
+			r.Findings = append(r.Findings, Finding{
+				Package: pkg,
+				Rule:    rule,
+				Level:   level,
+				Message: m.Message,
+				File:    file,
+				Line:    m.Line,
+				Col:     m.Column,
+			})
+			lines = append(lines, fmt.Sprintf("%s:%d:%d: %s: %s [%s]", file, m.Line, m.Column, level, m.Message, rule))
+		}
+
+		if res.ErrorCount > 0 {
+			c.Failure = &junit.Failure{
+				Message: fmt.Sprintf("%d error(s), %d warning(s)", res.ErrorCount, res.WarningCount),
+				Type:    "eslint",
+				Body:    strings.Join(lines, "\n"),
+			}
+		} else if len(lines) > 0 {
+			c.SystemOut = strings.Join(lines, "\n")
+		}
+		suite.Add(c)
+	}
+	r.Suites.Add(suite)
+	return nil
+}
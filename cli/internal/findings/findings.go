@@ -0,0 +1,132 @@
+package findings
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/junit"
+	"github.com/kluth/solidum-cli/internal/sarif"
+)
+
+// Formats lists the outputs a report can be written as
+var Formats = []string{"junit", "sarif", "annotations"}
+
+// Dir is where JUnit and SARIF files are written, relative to the workspace
+// root
+const Dir = ".solidum/reports"
+
+// Levels follow SARIF
+const (
+	Error   = "error"
+	Warning = "warning"
+	Note    = "note"
+)
+
+// Finding is one problem a tool reported, usually at a place in a file
+type Finding struct {
+	Package string
+	Rule    string
+	Level   string
+	Message string
+
+	// Title heads the annotation instead of the rule, e.g. a test's name
+	Title string
+
+	// File is relative to the workspace root with forward slashes; Line and
+	// Col are 1-based and 0 when unknown
+	File string
+	Line int
+	Col  int
+}
+
+// Report is the outcome of a test, lint or typecheck run as JUnit suites
+// and findings, from which every output format is built
+type Report struct {
+	Tool     string
+	Suites   *junit.Suites
+	Findings []Finding
+}
+
+// New returns an empty report for the named tool
+func New(tool string) *Report {
+	return &Report{Tool: tool, Suites: &junit.Suites{Name: tool}}
+}
+
+// AddFailure records a package whose run failed without output the report
+// could parse
+func (r *Report) AddFailure(pkg, message, output string) {
+	suite := &junit.Suite{Name: pkg}
+	suite.Add(&junit.Case{Name: pkg, Classname: r.Tool, Error: &junit.Failure{Message: message, Body: output}})
+	r.Suites.Add(suite)
+	r.Findings = append(r.Findings, Finding{Package: pkg, Rule: r.Tool, Level: Error, Message: pkg + ": " + message})
+}
+
+// Sarif converts the findings into a SARIF log
+func (r *Report) Sarif(version string) *sarif.Log {
+	log := sarif.New(r.Tool, version)
+	run := log.Run()
+	for _, f := range r.Findings {
+		run.AddRule(f.Rule, f.Rule)
+		run.Add(f.Rule, f.Level, f.Message, f.File, f.Line, f.Col)
+	}
+	return log
+}
+
+// WriteAnnotations writes the findings as GitHub Actions workflow commands,
+// which show up inline on the changed files
+func (r *Report) WriteAnnotations(w io.Writer) error {
+	for _, f := range r.Findings {
+		command := "error"
+		switch f.Level {
+		case Warning:
+			command = "warning"
+		case Note:
+			command = "notice"
+		}
+
+		var props []string
+		if f.File != "" {
+			props = append(props, "file="+escapeProperty(f.File))
+			if f.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", f.Line))
+			}
+			if f.Col > 0 {
+				props = append(props, fmt.Sprintf("col=%d", f.Col))
+			}
+		}
+		title := f.Title
+		if title == "" {
+			title = f.Rule
+		}
+		if title != "" {
+			props = append(props, "title="+escapeProperty(title))
+		}
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n", command, strings.Join(props, ","), escapeData(f.Message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Counts totals the findings by level
+func (r *Report) Counts() (errors, warnings int) {
+	for _, f := range r.Findings {
+		switch f.Level {
+		case Error:
+			errors++
+		case Warning:
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(strings.TrimRight(s, "\n"))
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,69 @@
+package findings
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/junit"
+	"github.com/kluth/solidum-cli/internal/lint"
+)
+
+// AddLint adds the problems the Solidum rules found in pkg, with rules
+// prefixed "solidum/" to tell them from ESLint's. Each file with problems
+// becomes a test case that fails when it has errors; a package without any
+// is a single passing case.
+func (r *Report) AddLint(pkg string, diags []lint.Diagnostic) {
+	suite := &junit.Suite{Name: pkg}
+	byFile := make(map[string][]lint.Diagnostic)
+	for _, d := range diags {
+		byFile[d.File] = append(byFile[d.File], d)
+	}
+	if len(byFile) == 0 {
+		suite.Add(&junit.Case{Name: pkg, Classname: "solidum"})
+		r.Suites.Add(suite)
+		return
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		c := &junit.Case{Name: file, Classname: pkg, File: file}
+		var lines []string
+		errors, warnings := 0, 0
+		for _, d := range byFile[file] {
+			level := Warning
+			if d.Severity == lint.SeverityError {
+				level = Error
+				errors++
+			} else {
+				warnings++
+			}
+			rule := "solidum/" + d.Rule
+			r.Findings = append(r.Findings, Finding{
+				Package: pkg,
+				Rule:    rule,
+				Level:   level,
+				Message: d.Message,
+				File:    file,
+				Line:    d.Line,
+				Col:     d.Col,
+			})
+			lines = append(lines, fmt.Sprintf("%s:%d:%d: %s: %s [%s]", file, d.Line, d.Col, level, d.Message, rule))
+		}
+		if errors > 0 {
+			c.Failure = &junit.Failure{
+				Message: fmt.Sprintf("%d error(s), %d warning(s)", errors, warnings),
+				Type:    "solidum",
+				Body:    strings.Join(lines, "\n"),
+			}
+		} else {
+			c.SystemOut = strings.Join(lines, "\n")
+		}
+		suite.Add(c)
+	}
+	r.Suites.Add(suite)
+}
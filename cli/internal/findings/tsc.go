@@ -0,0 +1,128 @@
+package findings
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/junit"
+)
+
+// tscDiagnostic matches tsc's non-pretty output, with or without a location:
+// src/a.ts(12,5): error TS2322: Type 'string' is not assignable to type 'number'.
+var tscDiagnostic = regexp.MustCompile(`^(?:(.+?)\((\d+),(\d+)\): )?(error|warning|message) (TS\d+): (.*)$`)
+
+// ParseTsc reads the diagnostics in tsc's non-pretty output. Relative paths
+// are resolved against dir, the directory tsc ran in, and mapped through
+// rel.
+func ParseTsc(output, dir string, rel func(string) string) []Finding {
+	var diags []Finding
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		m := tscDiagnostic.FindStringSubmatch(line)
+		if m == nil {
+			// Related information and message chains are indented
+			if len(diags) > 0 && strings.HasPrefix(line, "  ") && strings.TrimSpace(line) != "" {
+				diags[len(diags)-1].Message += "\n" + strings.TrimSpace(line)
+			}
+			continue
+		}
+
+		f := Finding{Rule: m[5], Level: Error, Message: m[6]}
+		switch m[4] {
+		case "warning":
+			f.Level = Warning
+		case "message":
+			f.Level = Note
+		}
+		if m[1] != "" {
+			file := m[1]
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(dir, filepath.FromSlash(file))
+			}
+			f.File = rel(file)
+			f.Line, _ = strconv.Atoi(m[2])
+			f.Col, _ = strconv.Atoi(m[3])
+		}
+		diags = append(diags, f)
+	}
+	return diags
+}
+
+// Dedupe drops repeated diagnostics, keeping the first of each
+func Dedupe(diags []Finding) []Finding {
+	seen := make(map[Finding]bool)
+	var unique []Finding
+	for _, f := range diags {
+		if !seen[f] {
+			seen[f] = true
+			unique = append(unique, f)
+		}
+	}
+	return unique
+}
+
+// AddTsc adds the diagnostics tsc printed while checking pkg in dir. rel
+// maps absolute paths to workspace-relative ones.
+func (r *Report) AddTsc(pkg, dir, output string, failed bool, rel func(string) string) {
+	r.AddTscDiagnostics(pkg, ParseTsc(output, dir, rel), failed, output)
+}
+
+// AddTscDiagnostics adds the type errors of pkg. Each file with errors
+// becomes a failed test case; a package without any is a single case that
+// fails only when the run did.
+func (r *Report) AddTscDiagnostics(pkg string, diags []Finding, failed bool, output string) {
+	suite := &junit.Suite{Name: pkg}
+	byFile := make(map[string][]string)
+	for _, f := range diags {
+		f.Package = pkg
+		r.Findings = append(r.Findings, f)
+		byFile[f.File] = append(byFile[f.File], formatTsc(f))
+	}
+
+	if len(byFile) == 0 {
+		if failed {
+			r.AddFailure(pkg, "tsc failed without reporting a diagnostic", output)
+			return
+		}
+		suite.Add(&junit.Case{Name: pkg, Classname: "tsc"})
+		r.Suites.Add(suite)
+		return
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		name := file
+		if name == "" {
+			name = pkg
+		}
+		suite.Add(&junit.Case{
+			Name:      name,
+			Classname: pkg,
+			File:      file,
+			Failure: &junit.Failure{
+				Message: fmt.Sprintf("%d type error(s)", len(byFile[file])),
+				Type:    "tsc",
+				Body:    strings.Join(byFile[file], "\n"),
+			},
+		})
+	}
+	r.Suites.Add(suite)
+}
+
+func formatTsc(f Finding) string {
+	level := f.Level
+	if level == Note {
+		level = "message"
+	}
+	if f.File == "" {
+		return fmt.Sprintf("%s %s: %s", level, f.Rule, f.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s %s: %s", f.File, f.Line, f.Col, level, f.Rule, f.Message)
+}
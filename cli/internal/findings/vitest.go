@@ -0,0 +1,74 @@
+package findings
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/junit"
+	"github.com/kluth/solidum-cli/internal/vitest"
+)
+
+// stackLocation matches the file:line:col of a stack frame
+var stackLocation = regexp.MustCompile(`([^\s()]+):(\d+):(\d+)`)
+
+// AddVitest adds the test results of pkg: one suite per test file and one
+// case per test. Failed tests are findings at the line of the test file
+// their stack points to. rel maps absolute paths to workspace-relative ones.
+func (r *Report) AddVitest(pkg string, res *vitest.Results, rel func(string) string) {
+	for _, f := range res.Files {
+		file := rel(f.Name)
+		suite := &junit.Suite{Name: file, File: file}
+
+		if f.Error != "" {
+			suite.Add(&junit.Case{Name: file, Classname: pkg, File: file, Error: &junit.Failure{Message: firstLine(f.Error), Body: f.Error}})
+			line, col := errorLocation(f.Name, f.Error)
+			r.Findings = append(r.Findings, Finding{Package: pkg, Rule: "failed-file", Title: file, Level: Error, Message: f.Error, File: file, Line: line, Col: col})
+		}
+
+		for _, t := range f.Tests {
+			c := &junit.Case{Name: t.FullName(), Classname: file, File: file, Time: t.Duration.Seconds()}
+			switch t.Status {
+			case vitest.Failed:
+				c.Failure = &junit.Failure{Message: firstLine(t.Error), Body: t.Error}
+				line, col := errorLocation(f.Name, t.Error)
+				c.Line = line
+				r.Findings = append(r.Findings, Finding{
+					Package: pkg,
+					Rule:    "failed-test",
+					Title:   t.FullName(),
+					Level:   Error,
+					Message: t.Error,
+					File:    file,
+					Line:    line,
+					Col:     col,
+				})
+			case vitest.Skipped:
+				c.Skipped = &junit.Skipped{}
+			}
+			suite.Add(c)
+		}
+
+		r.Suites.Add(suite)
+	}
+}
+
+// errorLocation finds the first stack frame in the test file itself
+func errorLocation(file, msg string) (line, col int) {
+	base := filepath.Base(file)
+	for _, m := range stackLocation.FindAllStringSubmatch(msg, -1) {
+		if filepath.Base(m[1]) != base {
+			continue
+		}
+		line, _ = strconv.Atoi(m[2])
+		col, _ = strconv.Atoi(m[3])
+		return line, col
+	}
+	return 0, 0
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// run runs git in dir and returns its trimmed output, with git's own
+// message as the error
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return strings.TrimRight(string(out), "\n"), nil
+}
+
+// Root returns the top-level directory of the repository holding dir
+func Root(dir string) (string, error) {
+	return run(dir, "rev-parse", "--show-toplevel")
+}
+
+// files splits -z output into absolute paths under root
+func files(root, out string) []string {
+	var paths []string
+	for _, name := range strings.Split(out, "\x00") {
+		if name != "" {
+			paths = append(paths, filepath.Join(root, filepath.FromSlash(name)))
+		}
+	}
+	return paths
+}
+
+// Staged returns the files added, copied, modified or renamed in the index
+func Staged(root string) ([]string, error) {
+	out, err := run(root, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return files(root, out), nil
+}
+
+// Unstaged returns the files whose working tree differs from the index
+func Unstaged(root string) ([]string, error) {
+	out, err := run(root, "diff", "--name-only", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return files(root, out), nil
+}
+
+// Changed returns the files that differ from ref in the working tree,
+// committed or not, plus untracked files. Changes are taken from where the
+// current branch forked from ref, so changes made on ref since then are
+// left out.
+func Changed(root, ref string) ([]string, error) {
+	base, err := run(root, "merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("can't compare with %s: %w", ref, err)
+	}
+	diff, err := run(root, "diff", "--name-only", "--diff-filter=ACMR", "-z", base)
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := run(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, path := range append(files(root, diff), files(root, untracked)...) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Add stages paths
+func Add(root string, paths ...string) error {
+	_, err := run(root, append([]string{"add", "--"}, paths...)...)
+	return err
+}
+
+// Tracked returns those of paths that git tracks
+func Tracked(root string, paths ...string) ([]string, error) {
+	out, err := run(root, append([]string{"ls-files", "-z", "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	return files(root, out), nil
+}
+
+// HooksDir returns the repository's own hooks directory, which git runs
+// hooks from unless core.hooksPath points elsewhere
+func HooksDir(root string) (string, error) {
+	dir, err := run(root, "rev-parse", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return filepath.Join(dir, "hooks"), nil
+}
+
+// Config returns a config value, or "" when it is unset
+func Config(root, key string) string {
+	value, _ := run(root, "config", "--get", key)
+	return value
+}
+
+// UnsetConfig removes a config value from the repository's config
+func UnsetConfig(root, key string) error {
+	_, err := run(root, "config", "--local", "--unset", key)
+	return err
+}
+
+// LogEntry is a commit as read by Log
+type LogEntry struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// Log returns the commits after since up to HEAD, newest first, that touch
+// one of paths. An empty since reads the whole history.
+func Log(root, since string, paths ...string) ([]LogEntry, error) {
+	rng := "HEAD"
+	if since != "" {
+		rng = since + "..HEAD"
+	}
+	args := append([]string{"log", "--format=%H%x1f%s%x1f%b%x1e", rng, "--"}, paths...)
+	out, err := run(root, args...)
+	if err != nil {
+		return nil, err
+	}
+	var entries []LogEntry
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		entries = append(entries, LogEntry{Hash: fields[0], Subject: fields[1], Body: strings.TrimSpace(fields[2])})
+	}
+	return entries, nil
+}
+
+// Tags returns the tags matching a glob pattern such as "@sldm/core@*"
+func Tags(root, pattern string) ([]string, error) {
+	out, err := run(root, "tag", "--list", pattern)
+	if err != nil || out == "" {
+		return nil, err
+	}
+	return strings.Split(out, "\n"), nil
+}
+
+// Commit commits paths, and only those, with message
+func Commit(root, message string, paths ...string) error {
+	if err := Add(root, paths...); err != nil {
+		return err
+	}
+	_, err := run(root, append([]string{"commit", "-m", message, "--"}, paths...)...)
+	return err
+}
+
+// Tag creates an annotated tag of HEAD
+func Tag(root, name, message string) error {
+	_, err := run(root, "tag", "-a", name, "-m", message)
+	return err
+}
@@ -0,0 +1,177 @@
+package jsonc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Strip blanks out comments and trailing commas so encoding/json can read
+// JSON with comments, like a tsconfig. Removed characters become spaces,
+// newlines are kept, so offsets into the result are offsets into data.
+func Strip(data []byte) []byte {
+	out := append([]byte(nil), data...)
+	inString := false
+	lastComma := -1
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+			lastComma = -1
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			lastComma = -1
+		}
+	}
+	return out
+}
+
+// span is the position of a member's value in a JSON object
+type span struct {
+	valueStart, valueEnd int
+}
+
+// members finds the members of the JSON object in stripped, along with the
+// offset of its closing brace
+func members(stripped []byte) (map[string]span, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(stripped))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, 0, fmt.Errorf("expected an object")
+	}
+	found := make(map[string]span)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+		key, _ := tok.(string)
+		start := int(dec.InputOffset())
+		for start < len(stripped) && strings.IndexByte(" \t\r\n:", stripped[start]) >= 0 {
+			start++
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, 0, err
+		}
+		found[key] = span{valueStart: start, valueEnd: int(dec.InputOffset())}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, 0, err
+	}
+	return found, int(dec.InputOffset()) - 1, nil
+}
+
+// indentOf returns the whitespace that starts the line holding offset
+func indentOf(data []byte, offset int) string {
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	end := start
+	for end < len(data) && (data[end] == ' ' || data[end] == '\t') {
+		end++
+	}
+	return string(data[start:end])
+}
+
+// Set replaces the value of the member at path, e.g. ["dependencies",
+// "@sldm/core"], keeping the rest of data as it is. A missing last member
+// is added after its siblings. value is formatted for the member's
+// indentation.
+func Set(data []byte, path []string, value func(indent string) string) ([]byte, error) {
+	stripped := Strip(data)
+	start := bytes.IndexByte(stripped, '{')
+	if start < 0 {
+		return nil, fmt.Errorf("expected an object")
+	}
+	return set(data, stripped, start, path, value)
+}
+
+// SetString sets the member at path to a string
+func SetString(data []byte, path []string, s string) ([]byte, error) {
+	quoted, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return Set(data, path, func(string) string { return string(quoted) })
+}
+
+// set works on the object starting at offset start
+func set(data, stripped []byte, start int, path []string, value func(indent string) string) ([]byte, error) {
+	found, closing, err := members(stripped[start:])
+	if err != nil {
+		return nil, err
+	}
+	closing += start
+
+	key := path[0]
+	if s, ok := found[key]; ok {
+		from, to := start+s.valueStart, start+s.valueEnd
+		if len(path) > 1 {
+			if stripped[from] != '{' {
+				return nil, fmt.Errorf("%s is not an object", key)
+			}
+			return set(data, stripped, from, path[1:], value)
+		}
+		var b bytes.Buffer
+		b.Write(data[:from])
+		b.WriteString(value(indentOf(data, from)))
+		b.Write(data[to:])
+		return b.Bytes(), nil
+	}
+	if len(path) > 1 {
+		return nil, fmt.Errorf("no %s", key)
+	}
+
+	// After the last member, or right after the opening brace
+	last := bytes.LastIndexFunc(stripped[:closing], func(r rune) bool {
+		return !strings.ContainsRune(" \t\r\n", r)
+	}) + 1
+	indent := indentOf(data, start) + "  "
+	for _, s := range found {
+		indent = indentOf(data, start+s.valueStart)
+		break
+	}
+	member := fmt.Sprintf("\n%s%q: %s", indent, key, value(indent))
+	if len(found) > 0 {
+		member = "," + member
+	}
+	var b bytes.Buffer
+	b.Write(data[:last])
+	b.WriteString(member)
+	if len(found) == 0 {
+		b.WriteString("\n" + indentOf(data, start))
+	}
+	b.Write(data[last:])
+	return b.Bytes(), nil
+}
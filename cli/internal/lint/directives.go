@@ -0,0 +1,102 @@
+package lint
+
+import (
+	"math"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/codemod"
+)
+
+// Inline comments that disable rules, like ESLint's:
+//
+//	// solidum-disable-next-line untracked-atom-read -- read once on purpose
+//	useContext(Theme); // solidum-disable-line
+//	/* solidum-disable no-deep-import */ … /* solidum-enable */
+const (
+	directiveNextLine = "solidum-disable-next-line"
+	directiveLine     = "solidum-disable-line"
+	directiveDisable  = "solidum-disable"
+	directiveEnable   = "solidum-enable"
+)
+
+// disabledRange turns rules off from one line to another, inclusive. No
+// rules means all of them.
+type disabledRange struct {
+	from, to int
+	rules    map[string]bool
+}
+
+type directives []*disabledRange
+
+// covers reports whether rule is disabled on line
+func (d directives) covers(rule string, line int) bool {
+	for _, r := range d {
+		if line >= r.from && line <= r.to && (r.rules == nil || r.rules[rule]) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDirectives reads the disable comments of a file
+func parseDirectives(f *codemod.File) directives {
+	var d directives
+	var open []*disabledRange
+	for _, tok := range f.Tokens {
+		if tok.Kind != codemod.TokenComment {
+			continue
+		}
+		text := strings.TrimPrefix(strings.TrimPrefix(tok.Text, "//"), "/*")
+		text = strings.TrimSpace(strings.TrimSuffix(text, "*/"))
+		name, args, _ := strings.Cut(text, " ")
+		end := tok.Line + strings.Count(tok.Text, "\n")
+
+		switch name {
+		case directiveNextLine:
+			d = append(d, &disabledRange{from: end + 1, to: end + 1, rules: ruleList(args)})
+		case directiveLine:
+			d = append(d, &disabledRange{from: tok.Line, to: end, rules: ruleList(args)})
+		case directiveDisable:
+			r := &disabledRange{from: tok.Line, to: math.MaxInt, rules: ruleList(args)}
+			d = append(d, r)
+			open = append(open, r)
+		case directiveEnable:
+			rules := ruleList(args)
+			var still []*disabledRange
+			for _, r := range open {
+				if rules == nil || overlaps(r.rules, rules) {
+					r.to = tok.Line
+				} else {
+					still = append(still, r)
+				}
+			}
+			open = still
+		}
+	}
+	return d
+}
+
+// ruleList parses "a, b -- reason" into a set of rules, nil for all
+func ruleList(args string) map[string]bool {
+	args, _, _ = strings.Cut(args, "--")
+	var rules map[string]bool
+	for _, rule := range strings.FieldsFunc(args, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+		if rules == nil {
+			rules = make(map[string]bool)
+		}
+		rules[rule] = true
+	}
+	return rules
+}
+
+func overlaps(a, b map[string]bool) bool {
+	if a == nil {
+		return true
+	}
+	for rule := range b {
+		if a[rule] {
+			return true
+		}
+	}
+	return false
+}
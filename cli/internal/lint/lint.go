@@ -0,0 +1,232 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/kluth/solidum-cli/internal/codemod"
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// Severity of a diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Rule names
+const (
+	RuleUntrackedRead  = "untracked-atom-read"
+	RuleContextOutside = "context-outside-render"
+	RuleDeepImport     = "no-deep-import"
+	RuleLegacyScope    = "no-legacy-scope"
+	RuleCrossPackage   = "no-cross-package-import"
+)
+
+// Rule describes a check and how it is reported by default
+type Rule struct {
+	Description string
+	Severity    Severity
+	Fixable     bool
+}
+
+// Rules lists every rule by name
+var Rules = map[string]Rule{
+	RuleUntrackedRead: {
+		Description: "An atom or computed is read where no effect, computed or component tracks it",
+		Severity:    SeverityWarning,
+	},
+	RuleContextOutside: {
+		Description: "useContext is called outside the render of a component or use* hook",
+		Severity:    SeverityError,
+	},
+	RuleDeepImport: {
+		Description: "A Solidum package is imported by a path its package.json doesn't export",
+		Severity:    SeverityError,
+		Fixable:     true,
+	},
+	RuleLegacyScope: {
+		Description: "A package is imported from the old @solidum/ scope instead of @sldm/",
+		Severity:    SeverityError,
+		Fixable:     true,
+	},
+	RuleCrossPackage: {
+		Description: "A relative import reaches into another workspace package",
+		Severity:    SeverityError,
+		Fixable:     true,
+	},
+}
+
+// Diagnostic is a problem found in a file
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Col      int      `json:"column"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+	Fixable  bool     `json:"fixable,omitempty"`
+
+	fix *codemod.Edit
+}
+
+// Linter checks the TypeScript sources of a workspace
+type Linter struct {
+	ws       *workspace.Workspace
+	severity map[string]Severity
+	ignore   []string
+}
+
+// New returns a linter for the rules cfg leaves on. cfg may be nil.
+func New(ws *workspace.Workspace, cfg *config.Lint) (*Linter, error) {
+	l := &Linter{ws: ws, severity: make(map[string]Severity)}
+	for name, rule := range Rules {
+		l.severity[name] = rule.Severity
+	}
+	if cfg == nil {
+		return l, nil
+	}
+	for name, level := range cfg.Rules {
+		if _, ok := Rules[name]; !ok {
+			return nil, fmt.Errorf("unknown lint rule %q in %s", name, config.FileName)
+		}
+		switch level {
+		case "off":
+			delete(l.severity, name)
+		case "warn":
+			l.severity[name] = SeverityWarning
+		case "error":
+			l.severity[name] = SeverityError
+		default:
+			return nil, fmt.Errorf("lint rule %s in %s: expected off, warn or error, got %q", name, config.FileName, level)
+		}
+	}
+	l.ignore = cfg.Ignore
+	return l, nil
+}
+
+// Files lists the sources of pkg that are checked: its TypeScript files,
+// without those of nested packages or the configured ignores
+func (l *Linter) Files(pkg *workspace.Package) ([]string, error) {
+	sources, err := codemod.FindSources(l.ws.Path(pkg.Dir))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, path := range sources {
+		rel := l.ws.Rel(path)
+		if l.ws.Owner(rel) != pkg || l.ignored(rel) {
+			continue
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+func (l *Linter) ignored(rel string) bool {
+	for _, glob := range l.ignore {
+		if workspace.MatchPath(glob, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// Result is the outcome of linting one file
+type Result struct {
+	Path        string
+	Original    string
+	Updated     string
+	Diagnostics []Diagnostic
+
+	// Fixed counts the problems fixed in Updated
+	Fixed int
+}
+
+// Changed reports whether fixes modified the file
+func (r *Result) Changed() bool {
+	return r.Original != r.Updated
+}
+
+// maxFixPasses bounds how often a file is re-checked after fixing, since a
+// fix may uncover another problem on the same import
+const maxFixPasses = 10
+
+// File lints a file. With fix, fixable problems are fixed in Updated and
+// only the remaining ones are reported.
+func (l *Linter) File(path string, fix bool) (*Result, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Result{Path: path, Original: string(src), Updated: string(src)}
+	for pass := 0; ; pass++ {
+		r.Diagnostics = l.check(path, r.Updated)
+		if !fix || pass == maxFixPasses {
+			return r, nil
+		}
+
+		var edits []codemod.Edit
+		end := -1
+		for _, d := range r.Diagnostics {
+			// Diagnostics are sorted by position, so overlapping fixes
+			// wait for the next pass
+			if d.fix != nil && d.fix.Start >= end {
+				edits = append(edits, *d.fix)
+				end = d.fix.End
+			}
+		}
+		if len(edits) == 0 {
+			return r, nil
+		}
+		updated, err := codemod.ApplyEdits(r.Updated, edits)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		r.Updated = updated
+		r.Fixed += len(edits)
+	}
+}
+
+// check runs the enabled rules over src and drops what inline comments
+// disable
+func (l *Linter) check(path, src string) []Diagnostic {
+	f := codemod.NewFile(path, src)
+	rel := l.ws.Rel(path)
+	c := &checker{linter: l, file: f, rel: rel, abs: path}
+	c.imports()
+	c.scopes()
+
+	disabled := parseDirectives(f)
+	var diags []Diagnostic
+	for _, d := range c.diags {
+		if !disabled.covers(d.Rule, d.Line) {
+			diags = append(diags, d)
+		}
+	}
+	SortDiagnostics(diags)
+	return diags
+}
+
+// SortDiagnostics orders diagnostics by file and position
+func SortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+}
+
+// Write saves the fixed source of a result
+func (r *Result) Write() error {
+	return os.WriteFile(r.Path, []byte(r.Updated), 0644)
+}
@@ -0,0 +1,109 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// writeWorkspace writes a workspace where app may import @sldm/core only by
+// the paths core exports
+func writeWorkspace(t *testing.T) *workspace.Workspace {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"package.json":               `{"name": "root", "private": true}`,
+		"pnpm-workspace.yaml":        "packages:\n  - 'packages/*'\n",
+		"packages/app/package.json":  `{"name": "app", "version": "1.0.0"}`,
+		"packages/core/package.json": `{"name": "@sldm/core", "version": "1.0.0", "exports": {".": "./dist/index.js", "./atom": "./dist/atom.js"}}`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ws, err := workspace.Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ws
+}
+
+// TestFixtures lints testdata/<case>/input.ts as a source of the app
+// package. expected.txt lists the diagnostics left, one "line:col severity
+// rule: message" per line; with an expected.ts the file is fixed first and
+// must come out as expected.ts.
+func TestFixtures(t *testing.T) {
+	cases, err := os.ReadDir("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range cases {
+		dir := filepath.Join("testdata", c.Name())
+		t.Run(c.Name(), func(t *testing.T) {
+			ws := writeWorkspace(t)
+			path := ws.Path("packages", "app", "src", "input.ts")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(readFixture(t, filepath.Join(dir, "input.ts"))), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			l, err := New(ws, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedSrc, fix := "", false
+			if _, err := os.Stat(filepath.Join(dir, "expected.ts")); err == nil {
+				expectedSrc, fix = readFixture(t, filepath.Join(dir, "expected.ts")), true
+			}
+			r, err := l.File(path, fix)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if fix && r.Updated != expectedSrc {
+				t.Errorf("fixed source:\n%s\nwant\n%s", r.Updated, expectedSrc)
+			}
+			var got strings.Builder
+			for _, d := range r.Diagnostics {
+				fmt.Fprintf(&got, "%d:%d %s %s: %s\n", d.Line, d.Col, d.Severity, d.Rule, d.Message)
+			}
+			if want := readFixture(t, filepath.Join(dir, "expected.txt")); got.String() != want {
+				t.Errorf("diagnostics:\n%s\nwant\n%s", got.String(), want)
+			}
+
+			// Fixed code has nothing left to fix
+			if fix {
+				if err := r.Write(); err != nil {
+					t.Fatal(err)
+				}
+				again, err := l.File(path, true)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if again.Changed() {
+					t.Errorf("fixing again changed the source:\n%s", again.Updated)
+				}
+			}
+		})
+	}
+}
+
+func readFixture(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
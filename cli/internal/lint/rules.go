@@ -0,0 +1,213 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/kluth/solidum-cli/internal/codemod"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// checker collects the diagnostics of one file
+type checker struct {
+	linter *Linter
+	file   *codemod.File
+	rel    string
+	abs    string
+	diags  []Diagnostic
+}
+
+func (c *checker) report(rule string, tok codemod.Token, fix *codemod.Edit, format string, args ...interface{}) {
+	severity, ok := c.linter.severity[rule]
+	if !ok {
+		return
+	}
+	c.diags = append(c.diags, Diagnostic{
+		File:     c.rel,
+		Line:     tok.Line,
+		Col:      tok.Col,
+		Severity: severity,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+		Fixable:  fix != nil,
+		fix:      fix,
+	})
+}
+
+// replaceSpecifier rewrites a module specifier token, keeping its quotes
+func replaceSpecifier(tok codemod.Token, spec string) *codemod.Edit {
+	quote := tok.Text[:1]
+	return &codemod.Edit{Start: tok.Offset, End: tok.Offset + len(tok.Text), Text: quote + spec + quote}
+}
+
+// deepImport splits "@sldm/core/src/atom" into "@sldm/core" and "./src/atom"
+var deepImport = regexp.MustCompile(`^(@(?:sldm|solidum)/[^/]+)/(.+)$`)
+
+// imports checks the module specifiers of the file
+func (c *checker) imports() {
+	ws := c.linter.ws
+	owner := ws.Owner(c.rel)
+	for _, tok := range codemod.ModuleSpecifiers(c.file) {
+		spec := tok.Text[1 : len(tok.Text)-1]
+
+		if rest, ok := strings.CutPrefix(spec, "@solidum/"); ok {
+			renamed := "@sldm/" + rest
+			c.report(RuleLegacyScope, tok, replaceSpecifier(tok, renamed),
+				"'%s' uses the old @solidum/ scope, import '%s'", spec, renamed)
+		}
+
+		if m := deepImport.FindStringSubmatch(spec); m != nil {
+			name, sub := m[1], "./"+m[2]
+			if !exported(ws, name, sub) {
+				c.report(RuleDeepImport, tok, replaceSpecifier(tok, name),
+					"'%s' imports a path %s doesn't export, import '%s'", spec, name, name)
+			}
+		}
+
+		if strings.HasPrefix(spec, "./") || strings.HasPrefix(spec, "../") {
+			target := ws.Rel(filepath.Join(filepath.Dir(c.abs), filepath.FromSlash(spec)))
+			pkg := ws.Owner(target)
+			if pkg != nil && pkg != owner && pkg != ws.RootPackage && pkg.Name != "" {
+				c.report(RuleCrossPackage, tok, replaceSpecifier(tok, pkg.Name),
+					"'%s' reaches into %s, import '%s'", spec, pkg.Name, pkg.Name)
+			}
+		}
+	}
+}
+
+// exported reports whether the subpath of a Solidum package may be
+// imported. Workspace packages are checked against their exports; for
+// others only source and build directories are off limits.
+func exported(ws *workspace.Workspace, name, sub string) bool {
+	pkg, ok := ws.Lookup(name)
+	if !ok || len(pkg.Exports) == 0 {
+		for _, dir := range []string{"./src/", "./dist/", "./lib/"} {
+			if strings.HasPrefix(sub, dir) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var exports map[string]json.RawMessage
+	if json.Unmarshal(pkg.Exports, &exports) != nil {
+		// A string or conditions object only exports the package root
+		return false
+	}
+	for key := range exports {
+		if key == sub {
+			return true
+		}
+		if prefix, suffix, ok := strings.Cut(key, "*"); ok &&
+			strings.HasPrefix(sub, prefix) && strings.HasSuffix(sub[len(prefix):], suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopes checks where atoms are read and useContext is called
+func (c *checker) scopes() {
+	core := codemod.ImportedNames(c.file, "@sldm/core")
+	context := codemod.ImportedNames(c.file, "@sldm/context")
+	if len(core) == 0 && len(context) == 0 {
+		return
+	}
+
+	trackers := make(map[string]bool)
+	for local, name := range core {
+		if name == "effect" || name == "computed" {
+			trackers[local] = true
+		}
+	}
+	sig := c.file.Significant()
+	atoms := reactiveVariables(sig, core)
+
+	walk(sig, trackers, func(i int, fn *frame) {
+		tok := sig[i]
+		if tok.Kind != codemod.TokenIdent || isMember(sig, i) || i+1 >= len(sig) || sig[i+1].Text != "(" {
+			return
+		}
+
+		if atoms[tok.Text] && i+2 < len(sig) && sig[i+2].Text == ")" {
+			switch {
+			case fn == nil:
+				c.report(RuleUntrackedRead, tok, nil,
+					"%s() is read at module scope, so its value is read once and never tracked", tok.Text)
+			case fn.tracked && fn.awaited:
+				c.report(RuleUntrackedRead, tok, nil,
+					"%s() is read after an await, where the effect or computed no longer tracks it", tok.Text)
+			}
+		}
+
+		if context[tok.Text] == "useContext" {
+			switch {
+			case fn == nil:
+				c.report(RuleContextOutside, tok, nil, "useContext() is called at module scope, outside any render")
+			case fn.awaited:
+				c.report(RuleContextOutside, tok, nil, "useContext() is called after an await, when the render has finished")
+			case fn.name == "":
+				c.report(RuleContextOutside, tok, nil,
+					"useContext() is called in a callback that may run outside render; call it in the component and use the value")
+			case !isComponent(fn.name):
+				c.report(RuleContextOutside, tok, nil,
+					"useContext() is called in %s, which is neither a component nor a use* hook", fn.name)
+			}
+		}
+	})
+}
+
+// reactiveVariables finds the variables initialized with atom() or
+// computed() imported from @sldm/core
+func reactiveVariables(sig []codemod.Token, core map[string]string) map[string]bool {
+	vars := make(map[string]bool)
+	for i := 0; i+3 < len(sig); i++ {
+		if t := sig[i].Text; t != "const" && t != "let" && t != "var" {
+			continue
+		}
+		name := sig[i+1]
+		if name.Kind != codemod.TokenIdent {
+			continue
+		}
+		// Skip a type annotation up to the initializer
+		j := i + 2
+		depth := 0
+		for ; j < len(sig) && j < i+64; j++ {
+			switch sig[j].Text {
+			case "(", "[", "{", "<":
+				depth++
+			case ")", "]", "}", ">":
+				depth--
+			}
+			if depth == 0 && (sig[j].Text == "=" || sig[j].Text == ";") {
+				break
+			}
+		}
+		if j+2 >= len(sig) || sig[j].Text != "=" || sig[j+2].Text != "(" {
+			continue
+		}
+		if callee := core[sig[j+1].Text]; callee == "atom" || callee == "computed" {
+			vars[name.Text] = true
+		}
+	}
+	return vars
+}
+
+// isMember reports whether the identifier at sig[i] is a property access
+func isMember(sig []codemod.Token, i int) bool {
+	return i > 0 && (sig[i-1].Text == "." || sig[i-1].Text == "?.")
+}
+
+// isComponent reports whether a function name is a component (Button) or a
+// hook (useTheme)
+func isComponent(name string) bool {
+	r := []rune(name)
+	if len(r) > 0 && unicode.IsUpper(r[0]) {
+		return true
+	}
+	return len(r) > 3 && strings.HasPrefix(name, "use") && unicode.IsUpper(r[3])
+}
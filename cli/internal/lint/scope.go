@@ -0,0 +1,225 @@
+package lint
+
+import (
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/codemod"
+)
+
+// frame is a bracket or function body the walker is inside
+type frame struct {
+	// close is the token ending the frame; arrow functions with an
+	// expression body end at a comma or semicolon instead and have none
+	close string
+	open  int
+
+	// fn frames are function bodies, named after the declaration or
+	// variable they are assigned to
+	fn   bool
+	name string
+
+	// tracked functions are passed to effect() or computed(), and awaited
+	// is set once they await
+	tracked bool
+	awaited bool
+
+	// callArgs is set on the parentheses of an effect() or computed() call
+	callArgs bool
+}
+
+// typeContext holds the tokens after which a brace starts a type literal
+// rather than a function body, e.g. in a return type annotation
+var typeContext = map[string]bool{":": true, "<": true, "|": true, "&": true, ",": true, "(": true}
+
+// walk visits the significant tokens with the innermost function around
+// each, or nil at module scope. It follows brackets only and doesn't parse
+// statements, which is enough to tell function bodies apart.
+func walk(sig []codemod.Token, trackers map[string]bool, visit func(i int, fn *frame)) {
+	var stack []*frame
+	var pending *frame
+	pendingDepth := -1
+
+	push := func(f *frame) { stack = append(stack, f) }
+	pop := func() *frame {
+		if len(stack) == 0 {
+			return nil
+		}
+		f := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return f
+	}
+	popExpressions := func() {
+		for len(stack) > 0 && stack[len(stack)-1].close == "" {
+			pop()
+		}
+	}
+	innermost := func() *frame {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].fn {
+				return stack[i]
+			}
+		}
+		return nil
+	}
+	inCallArgs := func() bool {
+		return len(stack) > 0 && stack[len(stack)-1].callArgs
+	}
+
+	for i, tok := range sig {
+		prev := ""
+		if i > 0 {
+			prev = sig[i-1].Text
+		}
+
+		if tok.Kind == codemod.TokenTemplate {
+			// Substitutions open with "${" and resume with "}"
+			if strings.HasPrefix(tok.Text, "}") {
+				popExpressions()
+				pop()
+			}
+			if strings.HasSuffix(tok.Text, "${") {
+				push(&frame{close: "}", open: i})
+			}
+			visit(i, innermost())
+			continue
+		}
+
+		switch tok.Text {
+		case "function", "class":
+			f := &frame{fn: true, tracked: inCallArgs()}
+			if i+1 < len(sig) && sig[i+1].Kind == codemod.TokenIdent {
+				f.name = sig[i+1].Text
+			} else {
+				f.name = assignedName(sig, i)
+			}
+			pending, pendingDepth = f, len(stack)
+		case "=>":
+			f := &frame{fn: true, name: arrowName(sig, i), tracked: inCallArgs(), open: i}
+			if i+1 < len(sig) && sig[i+1].Text == "{" {
+				pending, pendingDepth = f, len(stack)
+			} else {
+				push(f)
+			}
+		case "(":
+			call := i > 0 && trackers[prev] && !isMember(sig, i-1)
+			push(&frame{close: ")", open: i, callArgs: call})
+		case "[":
+			push(&frame{close: "]", open: i})
+		case "{":
+			if pending != nil && pendingDepth == len(stack) && !typeContext[prev] {
+				pending.close, pending.open = "}", i
+				push(pending)
+				pending = nil
+			} else {
+				push(&frame{close: "}", open: i})
+			}
+		case ")", "]", "}":
+			popExpressions()
+			f := pop()
+			// A method: name(...) { or name(...): Type {
+			if tok.Text == ")" && f != nil && pending == nil && f.open > 0 && i+1 < len(sig) &&
+				(sig[i+1].Text == "{" || sig[i+1].Text == ":") {
+				if name := sig[f.open-1]; name.Kind == codemod.TokenIdent && !isMember(sig, f.open-1) {
+					pending, pendingDepth = &frame{fn: true, name: name.Text}, len(stack)
+				}
+			}
+		case ",", ";":
+			popExpressions()
+			if tok.Text == ";" {
+				pending = nil
+			}
+		case "await":
+			if f := innermost(); f != nil {
+				f.awaited = true
+			}
+		}
+		visit(i, innermost())
+	}
+}
+
+// arrowName returns the name of the variable, property or class field an
+// arrow function at sig[arrow] is assigned to
+func arrowName(sig []codemod.Token, arrow int) string {
+	start := arrow - 1
+	// (p: Props): Type => names the function like (p: Props) =>
+	if colon := returnType(sig, arrow); colon > 0 {
+		start = colon - 1
+	}
+	if start >= 0 && sig[start].Text == ")" {
+		depth := 0
+		for ; start >= 0; start-- {
+			if sig[start].Text == ")" {
+				depth++
+			} else if sig[start].Text == "(" {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+	}
+	if start > 0 && sig[start-1].Text == "async" {
+		start--
+	}
+	return assignedName(sig, start)
+}
+
+// returnType returns the index of the colon that starts the return type
+// annotation of the arrow function at sig[arrow], or -1 if it has none
+func returnType(sig []codemod.Token, arrow int) int {
+	depth := 0
+	for j := arrow - 1; j > 0 && j >= arrow-64; j-- {
+		switch sig[j].Text {
+		case ")", "]", "}", ">":
+			depth++
+		case ">>":
+			depth += 2
+		case ">>>":
+			depth += 3
+		case "(", "[", "{", "<":
+			depth--
+		case ":":
+			if depth == 0 {
+				if sig[j-1].Text == ")" {
+					return j
+				}
+				return -1
+			}
+		case "=", ";", ",", "=>":
+			if depth == 0 {
+				return -1
+			}
+		}
+		if depth < 0 {
+			return -1
+		}
+	}
+	return -1
+}
+
+// assignedName returns the name a function expression starting at
+// sig[start] is assigned to: `const Name = `, `Name = ` or `Name: `
+func assignedName(sig []codemod.Token, start int) string {
+	i := start - 1
+	if i < 1 || (sig[i].Text != "=" && sig[i].Text != ":") {
+		return ""
+	}
+	if sig[i].Text == "=" {
+		// const Name: Type = ...
+		for j := i - 1; j >= 0 && j >= i-32; j-- {
+			switch sig[j].Text {
+			case "const", "let", "var":
+				if sig[j+1].Kind == codemod.TokenIdent {
+					return sig[j+1].Text
+				}
+				return ""
+			case ";", "{", "}", "(", ")":
+				j = -1
+			}
+		}
+	}
+	if sig[i-1].Kind == codemod.TokenIdent {
+		return sig[i-1].Text
+	}
+	return ""
+}
@@ -0,0 +1,7 @@
+import { atom } from '@sldm/core';
+import { read } from '@sldm/core';
+import { write } from '@sldm/core/atom';
+import { local } from './local';
+import { shared } from '@sldm/core';
+
+export const value = atom(read(write(local(shared))));
@@ -0,0 +1,7 @@
+import { atom } from '@solidum/core';
+import { read } from '@solidum/core/src/atom';
+import { write } from '@sldm/core/atom';
+import { local } from './local';
+import { shared } from '../../core/src/shared';
+
+export const value = atom(read(write(local(shared))));
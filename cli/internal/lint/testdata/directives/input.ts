@@ -0,0 +1,22 @@
+import { atom } from '@sldm/core';
+import { useContext } from '@sldm/context';
+import { helper } from '@sldm/core/src/helper';
+
+const count = atom(0);
+
+// solidum-disable-next-line untracked-atom-read -- read once on purpose
+const first = count();
+const second = count(); // solidum-disable-line
+const third = count(); // solidum-disable-line context-outside-render
+
+/* solidum-disable context-outside-render */
+const a = useContext(Ctx);
+const b = count();
+/* solidum-enable context-outside-render */
+const c = useContext(Ctx);
+
+/* solidum-disable */
+const d = useContext(Ctx);
+import { other } from '@sldm/core/src/other';
+/* solidum-enable */
+const e = count();
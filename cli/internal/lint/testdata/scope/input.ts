@@ -0,0 +1,49 @@
+import { atom, computed, effect } from '@sldm/core';
+import { useContext } from '@sldm/context';
+
+type Theme = { dark: boolean };
+type Props = { label: string };
+
+const count = atom(0);
+const doubled = computed(() => count() * 2);
+const initial = count();
+
+// Hooks and components, with and without return types
+export function useCount() {
+  return useContext(Ctx);
+}
+export const useTheme = (): Theme => useContext(Ctx);
+export const useThemes = (): Array<Theme> => [useContext(Ctx)];
+export const useNested = (): Map<string, Array<Theme>> => useContext(Ctx);
+export const useShape = (): { theme: Theme } => ({ theme: useContext(Ctx) });
+export const useAsync = async (): Promise<Theme> => useContext(Ctx);
+const Panel = (p: Props): JSX.Element => {
+  const theme = useContext(Ctx);
+  return <div class={theme.dark ? 'dark' : ''}>{p.label}</div>;
+};
+const Badge = function (p: Props): JSX.Element {
+  return useContext(Ctx);
+};
+
+// Where useContext and atom reads are wrong
+const theme = useContext(Ctx);
+function loadTheme(): Theme {
+  return useContext(Ctx);
+}
+export const formatTheme = (t: Theme): string => String(useContext(Ctx));
+const Toolbar = (p: Props) => {
+  const onClick = () => useContext(Ctx);
+  return onClick;
+};
+async function useLater() {
+  await fetch('/theme');
+  return useContext(Ctx);
+}
+
+effect(() => {
+  console.log(doubled());
+});
+effect(async () => {
+  await fetch('/count');
+  console.log(count());
+});
@@ -0,0 +1,295 @@
+package pack
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/size"
+)
+
+// DefaultMaxFileSize is the largest file a tarball may hold unless
+// configured otherwise
+const DefaultMaxFileSize = 1024 * 1024
+
+// Tarball is a packed package, as it would be published
+type Tarball struct {
+	Path string
+
+	// Size is the size of the tarball, Unpacked the total of its files
+	Size     int64
+	Unpacked int64
+
+	// Files maps the paths of the files, relative to the package root, to
+	// their sizes
+	Files map[string]int64
+
+	// Manifest is the package.json in the tarball, nil when it has none
+	Manifest *Manifest
+}
+
+// Manifest is the part of a packed package.json that is checked
+type Manifest struct {
+	Name    string          `json:"name"`
+	Version string          `json:"version"`
+	Main    string          `json:"main"`
+	Module  string          `json:"module"`
+	Types   string          `json:"types"`
+	Typings string          `json:"typings"`
+	Bin     json.RawMessage `json:"bin"`
+	Exports json.RawMessage `json:"exports"`
+
+	Scripts              map[string]string `json:"scripts"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// Pack runs pnpm pack in the package directory dir and moves the tarball
+// into dest, returning its path. pnpm rewrites workspace: ranges and runs
+// the prepack and prepare scripts, as publishing would.
+func Pack(dir, dest string) (string, error) {
+	tmp, err := os.MkdirTemp(dest, ".pack-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	cmd := exec.Command("pnpm", "pack", "--pack-destination", tmp)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("pnpm pack failed in %s: %w\n%s", dir, err, strings.TrimSpace(string(out)))
+	}
+	tarballs, err := filepath.Glob(filepath.Join(tmp, "*.tgz"))
+	if err != nil {
+		return "", err
+	}
+	if len(tarballs) != 1 {
+		return "", fmt.Errorf("pnpm pack in %s wrote %d tarballs, expected 1", dir, len(tarballs))
+	}
+	target := filepath.Join(dest, filepath.Base(tarballs[0]))
+	return target, os.Rename(tarballs[0], target)
+}
+
+// Read lists the contents of a .tgz written by npm or pnpm pack
+func Read(file string) (*Tarball, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	defer gz.Close()
+
+	t := &Tarball{Path: file, Size: info.Size(), Files: make(map[string]int64)}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		// Everything sits in one top-level directory, usually package/
+		name := hdr.Name
+		if i := strings.IndexByte(name, '/'); i >= 0 {
+			name = name[i+1:]
+		}
+		t.Files[name] = hdr.Size
+		t.Unpacked += hdr.Size
+		if name == "package.json" {
+			var buf bytes.Buffer
+			if _, err := io.Copy(&buf, tr); err != nil {
+				return nil, err
+			}
+			t.Manifest = &Manifest{}
+			if err := json.Unmarshal(buf.Bytes(), t.Manifest); err != nil {
+				return nil, fmt.Errorf("%s: invalid package.json: %w", file, err)
+			}
+		}
+	}
+	return t, nil
+}
+
+// Violation is a problem with what a tarball would publish
+type Violation struct {
+	Rule    string `json:"rule"`
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+var (
+	testFile = regexp.MustCompile(`(^|/)(__tests__/|[^/]+\.(test|spec)\.[cm]?[jt]sx?$)`)
+	envFile  = regexp.MustCompile(`(^|/)\.env(\.[^/]+)?$`)
+)
+
+// Check verifies that a tarball holds the files its package.json points
+// to, a dist/ directory when the package is built, no tests, .env files or
+// files over maxFileSize, and no workspace: ranges
+func (t *Tarball) Check(maxFileSize int64) []Violation {
+	m := t.Manifest
+	if m == nil {
+		return []Violation{{Rule: "manifest", Message: "the tarball has no package.json"}}
+	}
+	var out []Violation
+
+	targets := t.targets()
+	built := m.Scripts["build"] != ""
+	for _, target := range targets {
+		if strings.HasPrefix(target.path, "dist/") {
+			built = true
+		}
+		if !t.has(target.path) {
+			out = append(out, Violation{Rule: "missing-target", File: target.path, Message: fmt.Sprintf("%s points to %s, which is not in the tarball", target.field, target.path)})
+		}
+	}
+	if built && !t.hasDir("dist") {
+		out = append(out, Violation{Rule: "missing-dist", Message: "dist/ is not in the tarball; build before packing and check the files field"})
+	}
+
+	for _, name := range sortedKeys(t.Files) {
+		switch {
+		case testFile.MatchString(name):
+			out = append(out, Violation{Rule: "test-file", File: name, Message: "test files should not be published"})
+		case envFile.MatchString(name) && !strings.HasSuffix(name, ".example"):
+			out = append(out, Violation{Rule: "env-file", File: name, Message: "environment files may hold secrets"})
+		}
+		if t.Files[name] > maxFileSize {
+			out = append(out, Violation{Rule: "large-file", File: name, Message: fmt.Sprintf("%s, over the %s limit", size.Format(t.Files[name]), size.Format(maxFileSize))})
+		}
+	}
+
+	fields := []struct {
+		name string
+		deps map[string]string
+	}{
+		{"dependencies", m.Dependencies},
+		{"devDependencies", m.DevDependencies},
+		{"peerDependencies", m.PeerDependencies},
+		{"optionalDependencies", m.OptionalDependencies},
+	}
+	for _, field := range fields {
+		for _, name := range sortedKeys(field.deps) {
+			if spec := field.deps[name]; strings.HasPrefix(spec, "workspace:") {
+				out = append(out, Violation{Rule: "workspace-range", Message: fmt.Sprintf("%s.%s is still %q; pack with pnpm so it is replaced by a version", field.name, name, spec)})
+			}
+		}
+	}
+	return out
+}
+
+// target is a file package.json points to
+type target struct {
+	field string
+	path  string
+}
+
+// targets collects main, module, types, bin and every exports target,
+// relative to the package root
+func (t *Tarball) targets() []target {
+	m := t.Manifest
+	var out []target
+	add := func(field, p string) {
+		if p != "" {
+			out = append(out, target{field, path.Clean(strings.TrimPrefix(p, "./"))})
+		}
+	}
+	add("main", m.Main)
+	add("module", m.Module)
+	add("types", m.Types)
+	add("typings", m.Typings)
+
+	var bin interface{}
+	if json.Unmarshal(m.Bin, &bin) == nil {
+		switch bin := bin.(type) {
+		case string:
+			add("bin", bin)
+		case map[string]interface{}:
+			for name, p := range bin {
+				if s, ok := p.(string); ok {
+					add("bin."+name, s)
+				}
+			}
+		}
+	}
+
+	var exports interface{}
+	if json.Unmarshal(m.Exports, &exports) == nil {
+		walkExports("exports", exports, add)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].field < out[j].field })
+	return out
+}
+
+// walkExports calls add for every file an exports field maps to, with the
+// path of keys leading to it; null blocks a subpath and is skipped
+func walkExports(field string, v interface{}, add func(field, p string)) {
+	switch v := v.(type) {
+	case string:
+		add(field, v)
+	case []interface{}:
+		for _, item := range v {
+			walkExports(field, item, add)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			walkExports(field+"["+key+"]", item, add)
+		}
+	}
+}
+
+// has reports whether the tarball holds p, or for a subpath pattern like
+// dist/*.js, any file it matches
+func (t *Tarball) has(p string) bool {
+	if !strings.Contains(p, "*") {
+		_, ok := t.Files[p]
+		return ok
+	}
+	pattern := regexp.MustCompile("^" + strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".+") + "$")
+	for name := range t.Files {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Tarball) hasDir(dir string) bool {
+	for name := range t.Files {
+		if strings.HasPrefix(name, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,129 @@
+package ports
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// File is where assigned ports are kept, relative to the workspace root
+const File = ".solidum/ports.json"
+
+// DefaultBase is the first port handed out, Vite's default
+const DefaultBase = 5173
+
+// Assignment is the port a package got for this run
+type Assignment struct {
+	Package string
+	Port    int
+
+	// Previous is the persisted port when it was busy and had to change
+	Previous int
+}
+
+// Load reads the persisted package → port map. A missing file is empty.
+func Load(root string) (map[string]int, error) {
+	ports := make(map[string]int)
+	data, err := os.ReadFile(filepath.Join(root, File))
+	if os.IsNotExist(err) {
+		return ports, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &ports); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", File, err)
+	}
+	return ports, nil
+}
+
+// Save writes the package → port map
+func Save(root string, ports map[string]int) error {
+	path := filepath.Join(root, File)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(ports, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Available reports whether nothing is listening on port
+func Available(port int) bool {
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return false
+	}
+	l.Close()
+	return true
+}
+
+// Assign gives every package a free port, keeping the one it had last time
+// when possible so bookmarks and proxies keep working. New packages get the
+// next free port from base and it is persisted for them.
+func Assign(root string, packages []string, base int) ([]Assignment, error) {
+	if base <= 0 {
+		base = DefaultBase
+	}
+
+	persisted, err := Load(root)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ports persisted for other packages stay reserved
+	taken := make(map[int]bool)
+	for _, port := range persisted {
+		taken[port] = true
+	}
+
+	names := append([]string(nil), packages...)
+	sort.Strings(names)
+
+	assigned := make(map[string]int)
+	used := make(map[int]bool)
+	var busy []string
+
+	for _, name := range names {
+		if port, ok := persisted[name]; ok && !used[port] && Available(port) {
+			assigned[name] = port
+			used[port] = true
+			continue
+		}
+		busy = append(busy, name)
+	}
+
+	next := base
+	for _, name := range busy {
+		for used[next] || taken[next] || !Available(next) {
+			next++
+			if next > 65535 {
+				return nil, fmt.Errorf("no free port for %s", name)
+			}
+		}
+		assigned[name] = next
+		used[next] = true
+	}
+
+	result := make([]Assignment, 0, len(packages))
+	for _, name := range packages {
+		a := Assignment{Package: name, Port: assigned[name]}
+		if prev, ok := persisted[name]; !ok {
+			persisted[name] = a.Port
+		} else if prev != a.Port {
+			// Keep the persisted port; it is reused once it is free again
+			a.Previous = prev
+		}
+		result = append(result, a)
+	}
+
+	if err := Save(root, persisted); err != nil {
+		return nil, fmt.Errorf("failed to save %s: %w", File, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,328 @@
+package prerender
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Placeholders an app shell can use to mark where rendered markup goes.
+// Without them, the page is rendered into <div id="app"></div> and the head
+// additions go before </head>.
+const (
+	HTMLPlaceholder = "<!--app-html-->"
+	HeadPlaceholder = "<!--app-head-->"
+)
+
+var (
+	appDivPattern = regexp.MustCompile(`(<div\s+id=["']app["'][^>]*>)\s*(</div>)`)
+	titlePattern  = regexp.MustCompile(`(?is)<title>.*?</title>`)
+)
+
+// BuildDir is where the SSR bundle and worker are built, relative to the
+// app. The SSR build empties it.
+const BuildDir = ".solidum/ssr"
+
+// ShellDir keeps the client build's original index.html between runs. It
+// must not be inside BuildDir, or every build would lose the copy.
+const ShellDir = ".solidum/prerender"
+
+// Marker ends every prerendered page, so a later run knows dist/index.html
+// is no longer the client build's shell
+const Marker = "<!-- prerendered by solidum -->"
+
+// fallbackShell is used when the client build has no index.html
+const fallbackShell = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>Solidum App</title>
+</head>
+<body>
+  <div id="app"></div>
+</body>
+</html>
+`
+
+// Options configure a prerender run
+type Options struct {
+	// Bundle is the built SSR entry and Script the worker that loads it
+	Bundle string
+	Script string
+
+	// OutDir is the client build directory pages are written into
+	OutDir string
+
+	// Shell is the HTML document rendered pages are inserted into
+	Shell string
+
+	// Concurrency is the number of Node workers
+	Concurrency int
+
+	// Timeout limits how long one route may take to render; 0 waits forever
+	Timeout time.Duration
+}
+
+// Result is the outcome of rendering one route
+type Result struct {
+	Route    string
+	File     string
+	Duration time.Duration
+	Err      error
+}
+
+// ReadShell returns the client build's outDir/index.html, or a minimal
+// document when there is none; found reports which. The shell is kept in
+// saveDir because prerendering / overwrites it: a later run over the same
+// build reads the copy instead.
+func ReadShell(outDir, saveDir string) (shell string, found bool, err error) {
+	saved := filepath.Join(saveDir, "shell.html")
+
+	data, err := os.ReadFile(filepath.Join(outDir, "index.html"))
+	if os.IsNotExist(err) {
+		return fallbackShell, false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	if strings.Contains(string(data), Marker) {
+		data, err = os.ReadFile(saved)
+		if err != nil {
+			return "", false, fmt.Errorf("%s/index.html is already prerendered and the original was not kept; rebuild the app", outDir)
+		}
+		return string(data), true, nil
+	}
+
+	if err := os.MkdirAll(saveDir, 0755); err != nil {
+		return "", false, err
+	}
+	return string(data), true, os.WriteFile(saved, data, 0644)
+}
+
+// LoadManifest reads a routes manifest: a JSON array of paths, or an object
+// keyed by path like the router's RouteConfig
+func LoadManifest(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: expected an array of paths or an object keyed by path", file)
+	}
+	for route := range config {
+		list = append(list, route)
+	}
+	return list, nil
+}
+
+// Normalize cleans, de-duplicates and sorts routes. Dynamic routes such as
+// /users/:id can't be rendered without their parameters and are returned
+// separately.
+func Normalize(routes []string) (static, dynamic []string) {
+	seen := make(map[string]bool)
+	for _, route := range routes {
+		route = path.Clean("/" + strings.TrimSpace(route))
+		if seen[route] {
+			continue
+		}
+		seen[route] = true
+		if strings.ContainsAny(route, ":*") {
+			dynamic = append(dynamic, route)
+		} else {
+			static = append(static, route)
+		}
+	}
+	sort.Strings(static)
+	sort.Strings(dynamic)
+	return static, dynamic
+}
+
+// File returns where a route's page is written: / → index.html,
+// /about → about/index.html
+func File(outDir, route string) string {
+	return filepath.Join(outDir, filepath.FromSlash(strings.TrimPrefix(route, "/")), "index.html")
+}
+
+// Inject inserts a rendered page into the shell
+func Inject(shell string, page *Page) (string, error) {
+	html := shell
+	switch {
+	case strings.Contains(html, HTMLPlaceholder):
+		html = strings.Replace(html, HTMLPlaceholder, page.HTML, 1)
+	case appDivPattern.MatchString(html):
+		loc := appDivPattern.FindStringSubmatchIndex(html)
+		html = html[:loc[3]] + page.HTML + html[loc[4]:]
+	default:
+		return "", fmt.Errorf(`index.html has no <div id="app"></div> or %s placeholder`, HTMLPlaceholder)
+	}
+
+	if page.Title != "" {
+		title := "<title>" + escapeText(page.Title) + "</title>"
+		if titlePattern.MatchString(html) {
+			html = titlePattern.ReplaceAllLiteralString(html, title)
+		} else {
+			page.Head = title + page.Head
+		}
+	}
+	if strings.Contains(html, HeadPlaceholder) {
+		html = strings.Replace(html, HeadPlaceholder, page.Head, 1)
+	} else if page.Head != "" {
+		if i := strings.Index(strings.ToLower(html), "</head>"); i >= 0 {
+			html = html[:i] + page.Head + "\n" + html[i:]
+		}
+	}
+	return strings.TrimRight(html, "\n") + "\n" + Marker + "\n", nil
+}
+
+func escapeText(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// DiscoverRoutes asks the SSR bundle for its routes export. ok is false when
+// the bundle exports none.
+func DiscoverRoutes(opts Options) (routes []string, ok bool, err error) {
+	w, err := StartWorker(opts.Script, opts.Bundle, "prerender")
+	if err != nil {
+		return nil, false, err
+	}
+	defer w.Close()
+	return w.Routes()
+}
+
+// Render renders routes on a pool of workers and writes their pages,
+// calling onResult as each route finishes. Routes still queued when ctx is
+// cancelled are not rendered.
+func Render(ctx context.Context, opts Options, routes []string, onResult func(Result)) {
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(routes) {
+		workers = len(routes)
+	}
+
+	queue := make(chan string)
+	go func() {
+		defer close(queue)
+		for _, route := range routes {
+			select {
+			case queue <- route:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var w *Worker
+			defer func() {
+				if w != nil {
+					w.Close()
+				}
+			}()
+
+			for route := range queue {
+				// Replace workers that crashed on an earlier route
+				if w == nil || w.Dead {
+					if w != nil {
+						w.Close()
+					}
+					var err error
+					w, err = StartWorker(opts.Script, opts.Bundle, fmt.Sprintf("prerender-%d", i+1))
+					if err != nil {
+						w = nil
+						mu.Lock()
+						onResult(Result{Route: route, Err: err})
+						mu.Unlock()
+						continue
+					}
+					w.Timeout = opts.Timeout
+				}
+
+				res := renderRoute(w, opts, route)
+				mu.Lock()
+				onResult(res)
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func renderRoute(w *Worker, opts Options, route string) Result {
+	start := time.Now()
+	res := Result{Route: route, File: File(opts.OutDir, route)}
+
+	page, err := w.Render(route)
+	if err == nil {
+		var html string
+		html, err = Inject(opts.Shell, page)
+		if err == nil {
+			err = os.MkdirAll(filepath.Dir(res.File), 0755)
+		}
+		if err == nil {
+			err = os.WriteFile(res.File, []byte(html), 0644)
+		}
+	}
+	res.Err = err
+	res.Duration = time.Since(start)
+	return res
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	URLs    []sitemapURL
+}
+
+type sitemapURL struct {
+	XMLName xml.Name `xml:"url"`
+	Loc     string   `xml:"loc"`
+	LastMod string   `xml:"lastmod,omitempty"`
+}
+
+// WriteSitemap writes sitemap.xml listing routes under siteURL, which may
+// include a base path (https://example.com/docs)
+func WriteSitemap(file, siteURL string, routes []string) error {
+	siteURL = strings.TrimSuffix(siteURL, "/")
+	today := time.Now().UTC().Format("2006-01-02")
+
+	set := urlSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for _, route := range routes {
+		loc := siteURL + route
+		if route != "/" {
+			loc += "/"
+		}
+		set.URLs = append(set.URLs, sitemapURL{Loc: loc, LastMod: today})
+	}
+
+	data, err := xml.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append([]byte(xml.Header), append(data, '\n')...), 0644)
+}
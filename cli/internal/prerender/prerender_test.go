@@ -0,0 +1,138 @@
+package prerender
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBundle = `export const routes = ['/', '/about'];
+export function render(url) {
+  return { html: '<p>page ' + url + '</p>', title: 'Page ' + url };
+}
+`
+
+const testShell = `<!DOCTYPE html>
+<html>
+<head>
+  <title>App</title>
+  <script type="module" src="/assets/index.js"></script>
+</head>
+<body>
+  <div id="app"></div>
+</body>
+</html>
+`
+
+// prerenderOnce does what solidum prerender does in root: build the SSR
+// bundle into an emptied BuildDir, read the shell and render every route
+func prerenderOnce(t *testing.T, root string) {
+	t.Helper()
+	build := filepath.Join(root, BuildDir)
+	if err := os.RemoveAll(build); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(build, 0755); err != nil {
+		t.Fatal(err)
+	}
+	bundle := filepath.Join(build, "entry-server.mjs")
+	if err := os.WriteFile(bundle, []byte(testBundle), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script, err := WriteWorkerScript(build)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outDir := filepath.Join(root, "dist")
+	shell, found, err := ReadShell(outDir, filepath.Join(root, ShellDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found {
+		t.Fatal("ReadShell did not find dist/index.html")
+	}
+
+	opts := Options{Bundle: bundle, Script: script, OutDir: outDir, Shell: shell, Concurrency: 2, Timeout: 10 * time.Second}
+	Render(context.Background(), opts, []string{"/", "/about"}, func(res Result) {
+		if res.Err != nil {
+			t.Errorf("%s: %v", res.Route, res.Err)
+		}
+	})
+}
+
+func TestPrerenderTwice(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not installed")
+	}
+	root := t.TempDir()
+	index := filepath.Join(root, "dist", "index.html")
+	if err := os.MkdirAll(filepath.Dir(index), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(index, []byte(testShell), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	prerenderOnce(t, root)
+	first, err := os.ReadFile(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(first), Marker) || !strings.Contains(string(first), "<p>page /</p>") {
+		t.Fatalf("first run wrote:\n%s", first)
+	}
+
+	// dist/index.html is now a rendered page; the second run must start
+	// from the original shell again
+	prerenderOnce(t, root)
+	second, err := os.ReadFile(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(second) != string(first) {
+		t.Errorf("second run wrote a different page:\n%s\nwant\n%s", second, first)
+	}
+	about, err := os.ReadFile(filepath.Join(root, "dist", "about", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(about), Marker) != 1 || !strings.Contains(string(about), "<title>Page /about</title>") {
+		t.Errorf("second run wrote /about as:\n%s", about)
+	}
+}
+
+func TestWorkerCloseWithPendingTimers(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node is not installed")
+	}
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "entry-server.mjs")
+	// The interval keeps Node alive after stdin closes
+	src := "setInterval(() => {}, 1000);\n" + testBundle
+	if err := os.WriteFile(bundle, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script, err := WriteWorkerScript(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := StartWorker(script, bundle, "worker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Timeout = 10 * time.Second
+	if _, err := w.Render("/"); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	w.Close()
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Close took %s", elapsed)
+	}
+}
@@ -0,0 +1,278 @@
+package prerender
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kluth/solidum-cli/internal/proc"
+)
+
+// WorkerScript is the file name of the Node worker in the SSR directory
+const WorkerScript = "prerender-worker.mjs"
+
+// workerSource imports the SSR bundle and answers one JSON request per line
+// on stdin with one JSON response per line on stdout. The app's own console
+// output goes to stderr so it can't corrupt the protocol.
+const workerSource = `import { pathToFileURL } from 'node:url';
+import { createInterface } from 'node:readline';
+
+const write = process.stdout.write.bind(process.stdout);
+const send = message => write(JSON.stringify(message) + '\n');
+console.log = console.info = console.debug = (...args) => console.error(...args);
+
+const mod = await import(pathToFileURL(process.argv[2]).href);
+
+async function routes() {
+  let routes = mod.routes;
+  if (routes === undefined && typeof mod.getRoutes === 'function') {
+    routes = await mod.getRoutes();
+  }
+  if (routes === undefined || routes === null) return null;
+  return Array.isArray(routes) ? routes.map(String) : Object.keys(routes);
+}
+
+async function render(url) {
+  if (typeof mod.render !== 'function') {
+    throw new Error('the SSR entry must export render(url)');
+  }
+  const result = await mod.render(url);
+  return typeof result === 'string' ? { html: result } : result;
+}
+
+// describe keeps the app's stack frames, dropping the worker's and Node's
+function describe(err) {
+  if (!err || !err.stack) return String(err);
+  return String(err.stack)
+    .split('\n')
+    .filter(line => !line.includes('` + WorkerScript + `') && !line.includes('(node:'))
+    .join('\n');
+}
+
+createInterface({ input: process.stdin }).on('line', async line => {
+  const request = JSON.parse(line);
+  try {
+    if (request.type === 'routes') {
+      send({ id: request.id, routes: await routes() });
+    } else {
+      send({ id: request.id, ...(await render(request.url)) });
+    }
+  } catch (err) {
+    send({ id: request.id, error: describe(err) });
+  }
+}).on('close', () => process.exit(0));
+`
+
+// Page is what the SSR entry's render(url) returns
+type Page struct {
+	HTML  string `json:"html"`
+	Title string `json:"title,omitempty"`
+	Head  string `json:"head,omitempty"`
+}
+
+type request struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+	URL  string `json:"url,omitempty"`
+}
+
+type response struct {
+	Page
+	ID     int       `json:"id"`
+	Routes *[]string `json:"routes,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Worker is a Node process that renders pages from the SSR bundle, one
+// request at a time
+type Worker struct {
+	process *proc.Process
+	stdin   io.WriteCloser
+	stderr  *tailBuffer
+	nextID  int
+
+	// lines carries what the worker prints on stdout and is closed once it
+	// stops, with readErr set
+	lines   chan []byte
+	readErr error
+	quit    chan struct{}
+
+	// Timeout limits how long rendering a page may take; 0 waits forever
+	Timeout time.Duration
+
+	// Dead is set once the process has exited or was killed
+	Dead bool
+}
+
+// WriteWorkerScript writes the worker into dir and returns its path
+func WriteWorkerScript(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, WorkerScript)
+	return path, os.WriteFile(path, []byte(workerSource), 0644)
+}
+
+// StartWorker starts a worker for bundle. name labels the process in
+// shutdown reports.
+func StartWorker(script, bundle, name string) (*Worker, error) {
+	cmd := exec.Command("node", script, bundle)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	// Not StdoutPipe: proc waits for the process in the background, and
+	// Wait closes that pipe while responses may still be unread
+	stdout, stdoutW, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = stdoutW
+	stderr := &tailBuffer{}
+	cmd.Stderr = stderr
+
+	p, err := proc.Start(name, cmd)
+	stdoutW.Close()
+	if err != nil {
+		stdout.Close()
+		return nil, err
+	}
+
+	w := &Worker{process: p, stdin: stdin, stderr: stderr, lines: make(chan []byte), quit: make(chan struct{})}
+	go func() {
+		defer close(w.lines)
+		defer stdout.Close()
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		for scanner.Scan() {
+			select {
+			case w.lines <- append([]byte(nil), scanner.Bytes()...):
+			case <-w.quit:
+				return
+			}
+		}
+		w.readErr = scanner.Err()
+	}()
+	return w, nil
+}
+
+func (w *Worker) call(req request, timeout time.Duration) (*response, error) {
+	w.nextID++
+	req.ID = w.nextID
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.stdin.Write(append(data, '\n')); err != nil {
+		return nil, w.exited(err)
+	}
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	for {
+		select {
+		case line, ok := <-w.lines:
+			if !ok {
+				return nil, w.exited(w.readErr)
+			}
+			var res response
+			if err := json.Unmarshal(line, &res); err != nil || res.ID != req.ID {
+				// Not ours: output written straight to stdout by the app
+				continue
+			}
+			if res.Error != "" {
+				return nil, fmt.Errorf("%s", res.Error)
+			}
+			return &res, nil
+		case <-expired:
+			// The page may never finish; the next route gets a new worker
+			w.Dead = true
+			w.process.Kill()
+			return nil, fmt.Errorf("timed out after %s", timeout)
+		}
+	}
+}
+
+// exited explains a broken pipe with the last lines the worker printed
+// before dying
+func (w *Worker) exited(err error) error {
+	w.Dead = true
+	lines := strings.Split(strings.TrimSpace(w.stderr.String()), "\n")
+	if len(lines) > 20 {
+		lines = lines[len(lines)-20:]
+	}
+	msg := strings.Join(lines, "\n")
+	if msg == "" {
+		if err == nil {
+			return fmt.Errorf("worker exited")
+		}
+		return fmt.Errorf("worker exited: %w", err)
+	}
+	return fmt.Errorf("worker exited:\n%s", msg)
+}
+
+// Routes asks the bundle for its routes. ok is false when it exports none.
+func (w *Worker) Routes() (routes []string, ok bool, err error) {
+	res, err := w.call(request{Type: "routes"}, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	if res.Routes == nil {
+		return nil, false, nil
+	}
+	return *res.Routes, true, nil
+}
+
+// Render renders one URL within the worker's Timeout
+func (w *Worker) Render(url string) (*Page, error) {
+	res, err := w.call(request{Type: "render", URL: url}, w.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &res.Page, nil
+}
+
+// Close ends the worker and waits for it to exit. A worker still busy
+// rendering doesn't notice stdin closing, so it is stopped after proc.Grace.
+func (w *Worker) Close() {
+	w.stdin.Close()
+	close(w.quit)
+	ctx, cancel := context.WithTimeout(context.Background(), proc.Grace)
+	defer cancel()
+	w.process.Wait(ctx)
+}
+
+// tailBuffer keeps the last 8KB written to it
+type tailBuffer struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf = append(b.buf, p...)
+	if len(b.buf) > 8192 {
+		b.buf = b.buf[len(b.buf)-8192:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
+}
@@ -0,0 +1,250 @@
+package proc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Grace is how long children get to exit after a forwarded signal before
+// they are killed
+var Grace = 5 * time.Second
+
+// Process is a running child. Children that do not read from the terminal
+// are started in their own process group so signals reach the whole tree
+// (pnpm → node → vite) and nothing is left behind holding a port.
+type Process struct {
+	Name    string
+	Cmd     *exec.Cmd
+	Started time.Time
+
+	// group is true when the child leads its own process group
+	group bool
+
+	done     chan struct{}
+	err      error
+	stopOnce sync.Once
+	killed   bool
+}
+
+var (
+	mu      sync.Mutex
+	running = make(map[*Process]struct{})
+
+	// received is the signal that started a shutdown, if any
+	received os.Signal
+)
+
+// Start starts cmd and tracks it until it exits. Commands whose stdin is the
+// terminal stay in the foreground process group so they can still read
+// input; they receive Ctrl+C from the terminal directly.
+func Start(name string, cmd *exec.Cmd) (*Process, error) {
+	p := &Process{
+		Name:  name,
+		Cmd:   cmd,
+		group: cmd.Stdin != os.Stdin,
+		done:  make(chan struct{}),
+	}
+	if p.group {
+		setGroup(cmd)
+	}
+	// Grandchildren that escape the group can keep stdout open; don't let
+	// them block Wait forever
+	if cmd.WaitDelay == 0 {
+		cmd.WaitDelay = time.Second
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p.Started = time.Now()
+
+	mu.Lock()
+	running[p] = struct{}{}
+	mu.Unlock()
+
+	go func() {
+		p.err = cmd.Wait()
+		mu.Lock()
+		delete(running, p)
+		mu.Unlock()
+		close(p.done)
+	}()
+
+	return p, nil
+}
+
+// Wait blocks until the process exits. When ctx is done first the process
+// is sent the shutdown signal (SIGTERM by default) and killed after Grace.
+func (p *Process) Wait(ctx context.Context) error {
+	select {
+	case <-p.done:
+		return p.err
+	case <-ctx.Done():
+		mu.Lock()
+		sig := received
+		mu.Unlock()
+		if sig == nil {
+			sig = syscall.SIGTERM
+		}
+		p.Stop(sig, Grace)
+		<-p.done
+		if p.err == nil {
+			return nil
+		}
+		return fmt.Errorf("%w (%v)", ctx.Err(), p.err)
+	}
+}
+
+// Done is closed when the process has exited
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Pid returns the process id
+func (p *Process) Pid() int {
+	return p.Cmd.Process.Pid
+}
+
+// Signal sends sig to the process, or to its whole group
+func (p *Process) Signal(sig os.Signal) error {
+	return signalProcess(p, sig)
+}
+
+// Stop sends sig and waits up to grace for the process and the rest of its
+// group to exit, then kills them. It reports whether anything had to be
+// killed. Only the first call signals; later calls wait for the same outcome.
+func (p *Process) Stop(sig os.Signal, grace time.Duration) bool {
+	p.stopOnce.Do(func() {
+		p.Signal(sig)
+		deadline := time.After(grace)
+		select {
+		case <-p.done:
+		case <-deadline:
+			p.killed = true
+			killProcess(p)
+			return
+		}
+
+		// Background jobs of a shell ignore SIGINT and outlive the leader;
+		// they still hold the group and often a port
+		for groupAlive(p) {
+			select {
+			case <-deadline:
+				p.killed = true
+				killProcess(p)
+				return
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+	})
+	<-p.done
+	return p.killed
+}
+
+// Kill kills the process and every process in its group
+func (p *Process) Kill() {
+	killProcess(p)
+}
+
+// Run starts cmd and waits for it, stopping it when ctx is done
+func Run(ctx context.Context, name string, cmd *exec.Cmd) error {
+	p, err := Start(name, cmd)
+	if err != nil {
+		return err
+	}
+	return p.Wait(ctx)
+}
+
+// Running returns the children that have not exited yet, oldest first
+func Running() []*Process {
+	mu.Lock()
+	defer mu.Unlock()
+
+	procs := make([]*Process, 0, len(running))
+	for p := range running {
+		procs = append(procs, p)
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].Started.Before(procs[j].Started) })
+	return procs
+}
+
+// Shutdown forwards sig to every running child, waits up to grace for them
+// to exit and kills the rest. It returns the children that had to be killed.
+func Shutdown(sig os.Signal, grace time.Duration) []*Process {
+	procs := Running()
+
+	var wg sync.WaitGroup
+	killed := make([]bool, len(procs))
+	for i, p := range procs {
+		wg.Add(1)
+		go func(i int, p *Process) {
+			defer wg.Done()
+			killed[i] = p.Stop(sig, grace)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var stuck []*Process
+	for i, p := range procs {
+		if killed[i] {
+			stuck = append(stuck, p)
+		}
+	}
+	return stuck
+}
+
+// HandleSignals returns a context that is canceled on SIGINT or SIGTERM.
+// The signal is forwarded to all children, which get Grace to exit before
+// they are killed; a second signal kills them at once. Children that were
+// still running at the deadline are reported to stderr. Call stop before
+// exiting to restore default signal handling and finish the report.
+func HandleSignals(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, shutdownSignals...)
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+
+		var sig os.Signal
+		select {
+		case sig = <-signals:
+		case <-ctx.Done():
+			return
+		}
+		mu.Lock()
+		received = sig
+		mu.Unlock()
+		cancel()
+
+		go func() {
+			<-signals
+			for _, p := range Running() {
+				p.Kill()
+			}
+		}()
+
+		stuck := Shutdown(sig, Grace)
+		if len(stuck) > 0 {
+			fmt.Fprintf(os.Stderr, "\n⚠️  Killed %d process(es) still running after %s:\n", len(stuck), Grace)
+			for _, p := range stuck {
+				fmt.Fprintf(os.Stderr, "  %s (pid %d)\n", p.Name, p.Pid())
+			}
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+		<-finished
+	}
+}
@@ -0,0 +1,207 @@
+//go:build !windows
+
+package proc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// The test binary doubles as the child process: with PROC_TEST_CHILD set it
+// behaves as that kind of child instead of running the tests.
+func TestMain(m *testing.M) {
+	switch os.Getenv("PROC_TEST_CHILD") {
+	case "":
+		os.Exit(m.Run())
+	case "trap":
+		// Record the signal in $PROC_TEST_MARKER and exit cleanly
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+		fmt.Println("ready")
+		sig := <-signals
+		if marker := os.Getenv("PROC_TEST_MARKER"); marker != "" {
+			os.WriteFile(marker, []byte(sig.String()), 0644)
+		}
+		os.Exit(0)
+	case "ignore":
+		signal.Ignore(syscall.SIGTERM, syscall.SIGINT)
+		fmt.Println("ready")
+		time.Sleep(time.Hour)
+	case "parent":
+		// Start a trapping grandchild, then die on the signal without
+		// passing it on: the grandchild only sees it through the group
+		grandchild := exec.Command(os.Args[0])
+		grandchild.Env = append(os.Environ(), "PROC_TEST_CHILD=trap")
+		grandchild.Stdout = os.Stdout
+		if err := grandchild.Start(); err != nil {
+			os.Exit(2)
+		}
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM)
+		fmt.Println("ready")
+		<-signals
+		os.Exit(0)
+	case "sleep":
+		fmt.Println("ready")
+		time.Sleep(time.Hour)
+	}
+}
+
+// startChild starts the test binary as a child of the given kind and waits
+// until ready lines have been printed
+func startChild(t *testing.T, kind string, ready int, env ...string) *Process {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0])
+	// With -race, children otherwise linger for a second on exit
+	cmd.Env = append(append(os.Environ(), "PROC_TEST_CHILD="+kind, "GORACE=atexit_sleep_ms=0"), env...)
+	cmd.Stdout = w
+	p, err := Start(kind, cmd)
+	w.Close()
+	if err != nil {
+		r.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		p.Kill()
+		<-p.Done()
+		r.Close()
+	})
+
+	lines := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for i := 0; i < ready; i++ {
+			if !scanner.Scan() {
+				lines <- fmt.Errorf("child exited after %d ready line(s)", i)
+				return
+			}
+		}
+		lines <- nil
+	}()
+	select {
+	case err := <-lines:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("%s child never got ready", kind)
+	}
+	return p
+}
+
+func isRunning(p *Process) bool {
+	for _, r := range Running() {
+		if r == p {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStopSignalsWholeGroup(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "signal")
+	p := startChild(t, "parent", 2, "PROC_TEST_MARKER="+marker)
+
+	if killed := p.Stop(syscall.SIGTERM, 5*time.Second); killed {
+		t.Fatal("Stop killed a group that exits on SIGTERM")
+	}
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("grandchild did not get the signal: %v", err)
+	}
+	if got := string(data); got != syscall.SIGTERM.String() {
+		t.Errorf("grandchild got %q, want %q", got, syscall.SIGTERM.String())
+	}
+}
+
+func TestStopKillsAfterGrace(t *testing.T) {
+	p := startChild(t, "ignore", 1)
+
+	grace := 200 * time.Millisecond
+	start := time.Now()
+	if killed := p.Stop(syscall.SIGTERM, grace); !killed {
+		t.Fatal("Stop did not report killing a child that ignores SIGTERM")
+	}
+	if elapsed := time.Since(start); elapsed < grace {
+		t.Errorf("killed after %s, before the %s grace period", elapsed, grace)
+	}
+	select {
+	case <-p.Done():
+	default:
+		t.Fatal("process still running after Stop")
+	}
+}
+
+func TestWaitStopsOnCancel(t *testing.T) {
+	old := Grace
+	Grace = 5 * time.Second
+	defer func() { Grace = old }()
+
+	p := startChild(t, "trap", 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	start := time.Now()
+	if err := p.Wait(ctx); err != nil {
+		t.Fatalf("Wait = %v, want nil for a child that exits cleanly on SIGTERM", err)
+	}
+	if elapsed := time.Since(start); elapsed >= Grace {
+		t.Errorf("Wait took %s; the child should have exited on SIGTERM", elapsed)
+	}
+}
+
+func TestShutdownReportsStuckChildren(t *testing.T) {
+	polite := startChild(t, "trap", 1)
+	stuck := startChild(t, "ignore", 1)
+
+	killed := Shutdown(syscall.SIGTERM, 300*time.Millisecond)
+	if len(killed) != 1 || killed[0] != stuck {
+		names := make([]string, len(killed))
+		for i, p := range killed {
+			names[i] = p.Name
+		}
+		t.Fatalf("Shutdown killed %v, want only the ignore child", names)
+	}
+	for _, p := range []*Process{polite, stuck} {
+		select {
+		case <-p.Done():
+		default:
+			t.Errorf("%s still running after Shutdown", p.Name)
+		}
+	}
+}
+
+func TestRunningTracksChildren(t *testing.T) {
+	first := startChild(t, "sleep", 1)
+	second := startChild(t, "sleep", 1)
+
+	var ours []*Process
+	for _, p := range Running() {
+		if p == first || p == second {
+			ours = append(ours, p)
+		}
+	}
+	if len(ours) != 2 || ours[0] != first || ours[1] != second {
+		t.Fatalf("Running() = %v, want both children oldest first", ours)
+	}
+
+	first.Kill()
+	<-first.Done()
+	if isRunning(first) {
+		t.Error("Running() still lists a child that exited")
+	}
+	if !isRunning(second) {
+		t.Error("Running() lost a child that is still running")
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+package proc
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+func setGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+func signalProcess(p *Process, sig os.Signal) error {
+	if !p.group {
+		return p.Cmd.Process.Signal(sig)
+	}
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return p.Cmd.Process.Signal(sig)
+	}
+	// A negative pid addresses the whole process group
+	return syscall.Kill(-p.Cmd.Process.Pid, s)
+}
+
+func killProcess(p *Process) {
+	if p.group {
+		syscall.Kill(-p.Cmd.Process.Pid, syscall.SIGKILL)
+		return
+	}
+	p.Cmd.Process.Kill()
+}
+
+// groupAlive reports whether any process is left in the child's group
+func groupAlive(p *Process) bool {
+	return p.group && syscall.Kill(-p.Cmd.Process.Pid, 0) == nil
+}
@@ -0,0 +1,36 @@
+package proc
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+var shutdownSignals = []os.Signal{os.Interrupt}
+
+func setGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= syscall.CREATE_NEW_PROCESS_GROUP
+}
+
+// Windows cannot deliver SIGINT or SIGTERM to another process, so any
+// signal ends the tree
+func signalProcess(p *Process, sig os.Signal) error {
+	killProcess(p)
+	return nil
+}
+
+func killProcess(p *Process) {
+	kill := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Cmd.Process.Pid))
+	if kill.Run() != nil {
+		p.Cmd.Process.Kill()
+	}
+}
+
+// taskkill /T already ends the whole tree
+func groupAlive(p *Process) bool {
+	return false
+}
@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the public npm registry
+const DefaultURL = "https://registry.npmjs.org/"
+
+// Client reads package metadata from an npm registry
+type Client struct {
+	URL   string
+	Token string
+	HTTP  *http.Client
+
+	versions map[string]map[string]bool
+}
+
+// New returns a client for the registry at registryURL; token, if set,
+// authenticates the requests
+func New(registryURL, token string) *Client {
+	return &Client{
+		URL:      strings.TrimRight(registryURL, "/") + "/",
+		Token:    token,
+		HTTP:     &http.Client{Timeout: 30 * time.Second},
+		versions: make(map[string]map[string]bool),
+	}
+}
+
+// Published reports whether version of the named package is on the
+// registry. A package the registry doesn't know has no versions.
+func (c *Client) Published(ctx context.Context, name, version string) (bool, error) {
+	versions, ok := c.versions[name]
+	if !ok {
+		var err error
+		if versions, err = c.fetch(ctx, name); err != nil {
+			return false, err
+		}
+		c.versions[name] = versions
+	}
+	return versions[version], nil
+}
+
+// fetch reads the versions of a package from its abbreviated metadata
+func (c *Client) fetch(ctx context.Context, name string) (map[string]bool, error) {
+	// Scoped names keep the @ but escape the slash: @sldm%2fcore
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL+url.PathEscape(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.npm.install-v1+json; q=1.0, application/json; q=0.8")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s for %s: %w", c.URL, name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return map[string]bool{}, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("%s answered %s for %s: %s", c.URL, resp.Status, name, strings.TrimSpace(string(body)))
+	}
+	var doc struct {
+		Versions map[string]json.RawMessage `json:"versions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to read the %s metadata from %s: %w", name, c.URL, err)
+	}
+	versions := make(map[string]bool, len(doc.Versions))
+	for v := range doc.Versions {
+		versions[v] = true
+	}
+	return versions, nil
+}
+
+// Configured returns the registry pnpm publishes a package to from dir: the
+// registry set for its scope, else the default one
+func Configured(dir, name string) string {
+	if scope, _, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(scope, "@") {
+		if reg := pnpmConfig(dir, scope+":registry"); reg != "" {
+			return reg
+		}
+	}
+	if reg := pnpmConfig(dir, "registry"); reg != "" {
+		return reg
+	}
+	return DefaultURL
+}
+
+// ConfiguredToken returns the auth token .npmrc holds for a registry, else
+// $NPM_TOKEN or $NODE_AUTH_TOKEN
+func ConfiguredToken(dir, registryURL string) string {
+	if u, err := url.Parse(registryURL); err == nil && u.Host != "" {
+		key := "//" + u.Host + strings.TrimRight(u.Path, "/") + "/:_authToken"
+		if token := pnpmConfig(dir, key); token != "" {
+			return token
+		}
+	}
+	if token := os.Getenv("NPM_TOKEN"); token != "" {
+		return token
+	}
+	return os.Getenv("NODE_AUTH_TOKEN")
+}
+
+// pnpmConfig reads a config value through pnpm, which merges .npmrc files
+// and npm_config_* variables; "" when unset
+func pnpmConfig(dir, key string) string {
+	cmd := exec.Command("pnpm", "config", "get", key)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	value := strings.TrimSpace(string(out))
+	if err != nil || value == "undefined" || value == "null" {
+		return ""
+	}
+	return value
+}
@@ -0,0 +1,90 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChangelogFile is the changelog of the workspace, and of each package when
+// versioned independently
+const ChangelogFile = "CHANGELOG.md"
+
+// sections maps commit types to the Keep a Changelog headings they are
+// listed under; other types are left out
+var sections = map[string]string{
+	"feat":   "Added",
+	"fix":    "Fixed",
+	"perf":   "Changed",
+	"revert": "Changed",
+}
+
+// sectionOrder is the order headings appear in
+var sectionOrder = []string{"Added", "Changed", "Fixed"}
+
+// changelogHeader starts a new changelog
+const changelogHeader = `# Changelog
+
+All notable changes to this project will be documented in this file.
+
+The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.0.0/),
+and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).
+`
+
+// Change is a line of a changelog entry and the release it calls for
+type Change struct {
+	// Bump is major, minor or patch
+	Bump    string
+	Section string
+	Text    string
+}
+
+// Entry renders the changelog section of a release
+func Entry(version string, date time.Time, changes []Change) string {
+	lines := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, c := range changes {
+		// A change listed by several packages of a fixed release shows once
+		if seen[c.Text] {
+			continue
+		}
+		seen[c.Text] = true
+		lines[c.Section] = append(lines[c.Section], "- "+c.Text)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## [%s] - %s\n", version, date.Format("2006-01-02"))
+	if len(lines) == 0 {
+		b.WriteString("\nNo notable changes.\n")
+	}
+	for _, section := range sectionOrder {
+		if len(lines[section]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %s\n\n%s\n", section, strings.Join(lines[section], "\n"))
+	}
+	return b.String()
+}
+
+func short(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// Prepend inserts entry above the newest release of a changelog, keeping
+// its title and introduction. An empty changelog gets the standard header.
+func Prepend(changelog, entry string) string {
+	if strings.TrimSpace(changelog) == "" {
+		return changelogHeader + "\n" + entry
+	}
+	if strings.HasPrefix(changelog, "## ") {
+		return entry + "\n" + changelog
+	}
+	i := strings.Index(changelog, "\n## ")
+	if i < 0 {
+		return strings.TrimRight(changelog, "\n") + "\n\n" + entry
+	}
+	return changelog[:i+1] + entry + "\n" + changelog[i+1:]
+}
@@ -0,0 +1,159 @@
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/semver"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// ChangesetDir holds the pending changesets of a workspace
+const ChangesetDir = ".changesets"
+
+// Changeset is a release intent committed with a change: the packages it
+// affects with the bump each needs, and a summary for their changelogs.
+// It is a Markdown file with the bumps as front matter:
+//
+//	---
+//	"@sldm/router": minor
+//	---
+//
+//	Add nested routes
+type Changeset struct {
+	// Path is the workspace-relative path of the file
+	Path     string
+	Releases map[string]string
+	Summary  string
+}
+
+// releaseLine matches a front matter line such as "@sldm/core": minor
+var releaseLine = regexp.MustCompile(`^(?:"([^"]+)"|'([^']+)'|([^\s:'"]+))\s*:\s*(\w+)$`)
+
+// ParseChangeset reads the changeset at rel
+func ParseChangeset(rel string, data []byte) (*Changeset, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return nil, fmt.Errorf("%s: expected front matter starting with ---", rel)
+	}
+	c := &Changeset{Path: rel, Releases: make(map[string]string)}
+	for i := 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "---" {
+			c.Summary = strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+			return c, nil
+		}
+		if line == "" {
+			continue
+		}
+		m := releaseLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("%s:%d: expected \"<package>\": major, minor or patch", rel, i+1)
+		}
+		name := m[1] + m[2] + m[3]
+		if _, dup := c.Releases[name]; dup {
+			return nil, fmt.Errorf("%s:%d: %s is listed twice", rel, i+1, name)
+		}
+		c.Releases[name] = m[4]
+	}
+	return nil, fmt.Errorf("%s: front matter is not closed with ---", rel)
+}
+
+// Validate checks that the changeset names public workspace packages with
+// a major, minor or patch bump
+func (c *Changeset) Validate(ws *workspace.Workspace) error {
+	for _, name := range sortedNames(c.Releases) {
+		switch c.Releases[name] {
+		case semver.Major, semver.Minor, semver.Patch:
+		default:
+			return fmt.Errorf("%s: %s has bump %q, expected major, minor or patch", c.Path, name, c.Releases[name])
+		}
+		pkg, ok := ws.Lookup(name)
+		if !ok {
+			return fmt.Errorf("%s: no workspace package named %s", c.Path, name)
+		}
+		if !releasable(pkg) {
+			return fmt.Errorf("%s: %s is private or has no version", c.Path, name)
+		}
+	}
+	return nil
+}
+
+// Change returns the changelog line of the changeset for a package bumped
+// by bump. Major changes are listed as breaking, minor ones as added and
+// patches as fixes.
+func (c *Changeset) Change(bump string) Change {
+	text := strings.ReplaceAll(c.Summary, "\n", "\n  ")
+	switch bump {
+	case semver.Major:
+		return Change{Bump: bump, Section: "Changed", Text: "**BREAKING:** " + text}
+	case semver.Minor:
+		return Change{Bump: bump, Section: "Added", Text: text}
+	}
+	return Change{Bump: bump, Section: "Fixed", Text: text}
+}
+
+// Bytes renders the changeset file
+func (c *Changeset) Bytes() []byte {
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	for _, name := range sortedNames(c.Releases) {
+		fmt.Fprintf(&b, "%s: %s\n", strconv.Quote(name), c.Releases[name])
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(strings.TrimSpace(c.Summary) + "\n")
+	return b.Bytes()
+}
+
+// ReadChangesets returns the pending changesets of the workspace, ordered by
+// path. A README.md in the directory is not a changeset.
+func ReadChangesets(ws *workspace.Workspace) ([]*Changeset, error) {
+	paths, err := filepath.Glob(ws.Path(ChangesetDir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var sets []*Changeset
+	for _, path := range paths {
+		if strings.EqualFold(filepath.Base(path), "README.md") {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		set, err := ParseChangeset(ws.Rel(path), data)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// slugWord splits a summary into the words of a file name
+var slugWord = regexp.MustCompile(`[a-z0-9]+`)
+
+// NewChangesetPath names a file for a new changeset after the first words
+// of its summary, e.g. .changesets/add-nested-routes.md, adding a number
+// when the name is taken
+func NewChangesetPath(ws *workspace.Workspace, summary string) string {
+	words := slugWord.FindAllString(strings.ToLower(summary), 6)
+	slug := strings.Join(words, "-")
+	if slug == "" {
+		slug = "changeset"
+	}
+	rel := filepath.Join(ChangesetDir, slug+".md")
+	for n := 2; ; n++ {
+		if _, err := os.Stat(ws.Path(rel)); os.IsNotExist(err) {
+			return rel
+		}
+		rel = filepath.Join(ChangesetDir, fmt.Sprintf("%s-%d.md", slug, n))
+	}
+}
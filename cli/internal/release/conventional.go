@@ -0,0 +1,101 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/git"
+	"github.com/kluth/solidum-cli/internal/semver"
+)
+
+// Commit is a commit message read as a Conventional Commit
+type Commit struct {
+	Hash     string
+	Type     string
+	Scope    string
+	Subject  string
+	Breaking bool
+}
+
+// header matches "feat(router)!: add nested routes"
+var header = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: (.+)$`)
+
+// ParseCommit reads a commit; ok is false when it isn't a Conventional
+// Commit
+func ParseCommit(e git.LogEntry) (c Commit, ok bool) {
+	m := header.FindStringSubmatch(e.Subject)
+	if m == nil {
+		return c, false
+	}
+	c = Commit{
+		Hash:    e.Hash,
+		Type:    strings.ToLower(m[1]),
+		Scope:   m[2],
+		Subject: m[4],
+		// A "BREAKING CHANGE:" footer marks the commit as well
+		Breaking: m[3] == "!" || strings.Contains(e.Body, "BREAKING CHANGE:") || strings.Contains(e.Body, "BREAKING-CHANGE:"),
+	}
+	return c, true
+}
+
+// Releasable reports whether the commit belongs in a changelog
+func (c Commit) Releasable() bool {
+	_, ok := sections[c.Type]
+	return ok || c.Breaking
+}
+
+// Change returns the changelog line of the commit; ok is false for commits
+// that aren't listed, like chores
+func (c Commit) Change() (change Change, ok bool) {
+	if !c.Releasable() {
+		return change, false
+	}
+	change = Change{Bump: semver.Patch, Section: sections[c.Type]}
+	if c.Type == "feat" {
+		change.Bump = semver.Minor
+	}
+	if c.Breaking {
+		change.Bump = semver.Major
+		change.Section = "Changed"
+		change.Text = "**BREAKING:** "
+	}
+	if c.Scope != "" {
+		change.Text += fmt.Sprintf("**%s:** ", c.Scope)
+	}
+	change.Text += fmt.Sprintf("%s (%s)", c.Subject, short(c.Hash))
+	return change, true
+}
+
+// Infer returns the release the changes call for, the largest of their
+// bumps, with breaking changes bumping minor before 1.0.0; "" when none is
+// needed
+func Infer(changes []Change, current semver.Version) string {
+	kind := ""
+	for _, c := range changes {
+		bump := c.Bump
+		if bump == semver.Major && current.Major == 0 {
+			bump = semver.Minor
+		}
+		kind = larger(kind, bump)
+	}
+	return kind
+}
+
+// Largest returns the largest of major, minor and patch bumps
+func Largest(bumps ...string) string {
+	kind := ""
+	for _, bump := range bumps {
+		kind = larger(kind, bump)
+	}
+	return kind
+}
+
+// larger returns the larger of two release kinds
+func larger(a, b string) string {
+	rank := map[string]int{"": 0, semver.Patch: 1, semver.Minor: 2, semver.Major: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,97 @@
+package release
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// JournalFile records the progress of solidum publish, relative to the
+// workspace root
+const JournalFile = ".solidum/release-journal.json"
+
+// Statuses of a package in a journal
+const (
+	Pending   = "pending"
+	Published = "published"
+	// Skipped packages were already on the registry
+	Skipped = "skipped"
+	Failed  = "failed"
+)
+
+// Journal is a release in progress: the packages to publish in dependency
+// order and how far publishing got, so an interrupted release can resume
+type Journal struct {
+	Started time.Time `json:"started"`
+	Updated time.Time `json:"updated"`
+
+	// Tag and Access are the dist-tag and access the release publishes with
+	Tag    string `json:"tag"`
+	Access string `json:"access"`
+
+	Packages []*JournalEntry `json:"packages"`
+}
+
+// JournalEntry is a package version in a release
+type JournalEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// Dir is the package directory relative to the workspace root
+	Dir      string `json:"dir"`
+	Registry string `json:"registry"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+}
+
+// Done reports whether every package is published or skipped
+func (j *Journal) Done() bool {
+	for _, e := range j.Packages {
+		if e.Status != Published && e.Status != Skipped {
+			return false
+		}
+	}
+	return true
+}
+
+// LoadJournal reads the journal at path; nil when there is none
+func LoadJournal(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var j Journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &j, nil
+}
+
+// Save writes the journal to path, replacing the file in one step so an
+// interruption never leaves half a journal
+func (j *Journal) Save(path string) error {
+	j.Updated = time.Now().UTC().Truncate(time.Second)
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(j); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
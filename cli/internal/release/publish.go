@@ -0,0 +1,137 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kluth/solidum-cli/internal/registry"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// NewJournal lists the public packages of the workspace in dependency order,
+// each with the registry registryFor returns for it
+func NewJournal(ws *workspace.Workspace, tag, access string, registryFor func(pkg *workspace.Package) string) (*Journal, error) {
+	var public []*workspace.Package
+	for _, pkg := range ws.Packages {
+		if !pkg.Private && pkg.Name != "" && pkg.Version != "" {
+			public = append(public, pkg)
+		}
+	}
+	if len(public) == 0 {
+		return nil, fmt.Errorf("no public packages to publish")
+	}
+	sorted, err := ws.TopoSort(public)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	journal := &Journal{Started: now, Tag: tag, Access: access}
+	for _, pkg := range sorted {
+		journal.Packages = append(journal.Packages, &JournalEntry{
+			Name:     pkg.Name,
+			Version:  pkg.Version,
+			Dir:      pkg.Dir,
+			Registry: registryFor(pkg),
+			Status:   Pending,
+		})
+	}
+	return journal, nil
+}
+
+// Publisher publishes the packages of a journal one at a time, recording
+// each outcome
+type Publisher struct {
+	Journal *Journal
+
+	// Path is where the journal is saved after every package; "" keeps it
+	// in memory, as for a dry run
+	Path string
+
+	// DryRun leaves published packages pending
+	DryRun bool
+
+	// Token returns the auth token for a registry, "" for none
+	Token func(registryURL string) string
+
+	// Publish publishes one package
+	Publish func(ctx context.Context, e *JournalEntry) error
+
+	clients map[string]*registry.Client
+}
+
+// Pending asks the registries which packages not yet published or skipped
+// still need to be, in journal order. Versions already on a registry are
+// marked skipped, or published when resuming, since then an interrupted run
+// must have published them before it could record it.
+func (p *Publisher) Pending(ctx context.Context, resume bool) ([]*JournalEntry, error) {
+	if p.clients == nil {
+		p.clients = make(map[string]*registry.Client)
+	}
+	var pending []*JournalEntry
+	for _, e := range p.Journal.Packages {
+		if e.Status == Published || e.Status == Skipped {
+			continue
+		}
+		client := p.clients[e.Registry]
+		if client == nil {
+			token := ""
+			if p.Token != nil {
+				token = p.Token(e.Registry)
+			}
+			client = registry.New(e.Registry, token)
+			p.clients[e.Registry] = client
+		}
+		published, err := client.Published(ctx, e.Name, e.Version)
+		if err != nil {
+			return nil, err
+		}
+		if !published {
+			pending = append(pending, e)
+			continue
+		}
+		e.Status = Skipped
+		if resume {
+			e.Status = Published
+		}
+	}
+	return pending, nil
+}
+
+// Run publishes the pending packages in order, saving the journal before
+// the first and after each one. It stops at the first package that fails,
+// which is recorded as failed.
+func (p *Publisher) Run(ctx context.Context, pending []*JournalEntry) error {
+	if err := p.save(); err != nil {
+		return err
+	}
+	for _, e := range pending {
+		if err := p.Publish(ctx, e); err != nil {
+			e.Status = Failed
+			e.Error = err.Error()
+			if saveErr := p.save(); saveErr != nil {
+				return saveErr
+			}
+			return fmt.Errorf("%s@%s failed to publish: %w", e.Name, e.Version, err)
+		}
+		if !p.DryRun {
+			now := time.Now().UTC().Truncate(time.Second)
+			e.Status = Published
+			e.Error = ""
+			e.PublishedAt = &now
+		}
+		if err := p.save(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// save writes the journal to Path, if set
+func (p *Publisher) save() error {
+	if p.Path == "" {
+		return nil
+	}
+	return p.Journal.Save(p.Path)
+}
@@ -0,0 +1,266 @@
+package release
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// fakeRegistry serves abbreviated package metadata for the versions it
+// holds and 404 for packages it doesn't know
+type fakeRegistry struct {
+	mu       sync.Mutex
+	versions map[string][]string
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	versions, ok := f.versions[name]
+	if !ok {
+		http.Error(w, `{"error":"Not found"}`, http.StatusNotFound)
+		return
+	}
+	doc := map[string]map[string]any{"versions": {}}
+	for _, v := range versions {
+		doc["versions"][v] = map[string]string{"name": name, "version": v}
+	}
+	json.NewEncoder(w).Encode(doc)
+}
+
+func (f *fakeRegistry) add(name, version string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.versions[name] = append(f.versions[name], version)
+}
+
+// newRegistry starts a fake registry holding the given versions
+func newRegistry(t *testing.T, versions map[string][]string) (*fakeRegistry, string) {
+	t.Helper()
+	reg := &fakeRegistry{versions: versions}
+	srv := httptest.NewServer(reg)
+	t.Cleanup(srv.Close)
+	return reg, srv.URL
+}
+
+// writeWorkspace writes a workspace where @t/app depends on @t/ui, which
+// depends on @t/core, plus a private package that is never published
+func writeWorkspace(t *testing.T) *workspace.Workspace {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"package.json":               `{"name": "root", "private": true}`,
+		"pnpm-workspace.yaml":        "packages:\n  - 'packages/*'\n",
+		"packages/app/package.json":  `{"name": "@t/app", "version": "1.0.0", "dependencies": {"@t/ui": "workspace:*"}}`,
+		"packages/core/package.json": `{"name": "@t/core", "version": "1.0.0"}`,
+		"packages/ui/package.json":   `{"name": "@t/ui", "version": "1.0.0", "dependencies": {"@t/core": "workspace:*"}}`,
+		"packages/docs/package.json": `{"name": "@t/docs", "version": "1.0.0", "private": true}`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ws, err := workspace.Load(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ws
+}
+
+func statuses(j *Journal) map[string]string {
+	out := make(map[string]string)
+	for _, e := range j.Packages {
+		out[e.Name] = e.Status
+	}
+	return out
+}
+
+func TestPublishSkipsPublishedInDependencyOrder(t *testing.T) {
+	ws := writeWorkspace(t)
+	// @t/core is unknown to the registry and @t/app only has an older version
+	reg, url := newRegistry(t, map[string][]string{
+		"@t/ui":  {"0.9.0", "1.0.0"},
+		"@t/app": {"0.9.0"},
+	})
+
+	journal, err := NewJournal(ws, "latest", "public", func(*workspace.Package) string { return url })
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, e := range journal.Packages {
+		order = append(order, e.Name)
+	}
+	if want := []string{"@t/core", "@t/ui", "@t/app"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("journal order = %v, want %v", order, want)
+	}
+
+	path := filepath.Join(ws.Root, JournalFile)
+	var published []string
+	p := &Publisher{
+		Journal: journal,
+		Path:    path,
+		Publish: func(ctx context.Context, e *JournalEntry) error {
+			// Everything before this package is already on disk
+			saved, err := LoadJournal(path)
+			if err != nil || saved == nil {
+				t.Fatalf("no journal saved before publishing %s: %v", e.Name, err)
+			}
+			for _, s := range saved.Packages {
+				if s.Name == e.Name {
+					break
+				}
+				if s.Status != Published && s.Status != Skipped {
+					t.Errorf("publishing %s while the journal records %s as %s", e.Name, s.Name, s.Status)
+				}
+			}
+			published = append(published, e.Name)
+			reg.add(e.Name, e.Version)
+			return nil
+		},
+	}
+
+	pending, err := p.Pending(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Run(context.Background(), pending); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"@t/core", "@t/app"}; !reflect.DeepEqual(published, want) {
+		t.Errorf("published %v, want %v", published, want)
+	}
+
+	saved, err := LoadJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"@t/core": Published, "@t/ui": Skipped, "@t/app": Published}
+	if got := statuses(saved); !reflect.DeepEqual(got, want) {
+		t.Errorf("saved journal = %v, want %v", got, want)
+	}
+	for _, e := range saved.Packages {
+		if e.Status == Published && e.PublishedAt == nil {
+			t.Errorf("%s is published without a time", e.Name)
+		}
+	}
+}
+
+func TestPublishResume(t *testing.T) {
+	ws := writeWorkspace(t)
+	reg, url := newRegistry(t, map[string][]string{})
+	path := filepath.Join(ws.Root, JournalFile)
+
+	journal, err := NewJournal(ws, "next", "public", func(*workspace.Package) string { return url })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// run publishes what the journal at path has left, uploading every
+	// package but failing the way fail says
+	run := func(j *Journal, resume bool, fail func(e *JournalEntry) (uploaded bool, err error)) ([]string, error) {
+		t.Helper()
+		var attempts []string
+		p := &Publisher{
+			Journal: j,
+			Path:    path,
+			Publish: func(ctx context.Context, e *JournalEntry) error {
+				attempts = append(attempts, e.Name)
+				if fail != nil {
+					if uploaded, err := fail(e); err != nil {
+						if uploaded {
+							reg.add(e.Name, e.Version)
+						}
+						return err
+					}
+				}
+				reg.add(e.Name, e.Version)
+				return nil
+			},
+		}
+		pending, err := p.Pending(context.Background(), resume)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return attempts, p.Run(context.Background(), pending)
+	}
+	load := func() *Journal {
+		t.Helper()
+		j, err := LoadJournal(path)
+		if err != nil || j == nil {
+			t.Fatalf("failed to load the journal: %v", err)
+		}
+		return j
+	}
+
+	// @t/ui fails before anything reaches the registry
+	attempts, err := run(journal, false, func(e *JournalEntry) (bool, error) {
+		if e.Name == "@t/ui" {
+			return false, errors.New("E403 forbidden")
+		}
+		return false, nil
+	})
+	if err == nil {
+		t.Fatal("Run succeeded although @t/ui failed")
+	}
+	if want := []string{"@t/core", "@t/ui"}; !reflect.DeepEqual(attempts, want) {
+		t.Errorf("first run attempted %v, want %v", attempts, want)
+	}
+	saved := load()
+	if want := map[string]string{"@t/core": Published, "@t/ui": Failed, "@t/app": Pending}; !reflect.DeepEqual(statuses(saved), want) {
+		t.Errorf("journal after the failure = %v, want %v", statuses(saved), want)
+	}
+	if saved.Packages[1].Error != "E403 forbidden" {
+		t.Errorf("@t/ui error = %q, want the publish error", saved.Packages[1].Error)
+	}
+	if saved.Done() {
+		t.Error("a journal with a failed package reports done")
+	}
+
+	// The resumed run uploads @t/ui but loses the connection before it is
+	// recorded
+	attempts, err = run(saved, true, func(e *JournalEntry) (bool, error) {
+		if e.Name == "@t/ui" {
+			return true, errors.New("ECONNRESET")
+		}
+		return false, nil
+	})
+	if err == nil {
+		t.Fatal("Run succeeded although @t/ui failed")
+	}
+	if want := []string{"@t/ui"}; !reflect.DeepEqual(attempts, want) {
+		t.Errorf("first resume attempted %v, want %v", attempts, want)
+	}
+
+	// The next resume finds @t/ui on the registry and only publishes @t/app
+	attempts, err = run(load(), true, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"@t/app"}; !reflect.DeepEqual(attempts, want) {
+		t.Errorf("second resume attempted %v, want %v", attempts, want)
+	}
+	saved = load()
+	if want := map[string]string{"@t/core": Published, "@t/ui": Published, "@t/app": Published}; !reflect.DeepEqual(statuses(saved), want) {
+		t.Errorf("journal after resuming = %v, want %v", statuses(saved), want)
+	}
+	if !saved.Done() || saved.Tag != "next" {
+		t.Errorf("resumed journal: done = %v, tag = %q", saved.Done(), saved.Tag)
+	}
+}
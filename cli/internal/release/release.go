@@ -0,0 +1,464 @@
+package release
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kluth/solidum-cli/internal/git"
+	"github.com/kluth/solidum-cli/internal/jsonc"
+	"github.com/kluth/solidum-cli/internal/semver"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// Versioning modes: fixed gives every package the same version, independent
+// versions each package on its own
+const (
+	Fixed       = "fixed"
+	Independent = "independent"
+)
+
+// Options select what NewPlan releases
+type Options struct {
+	Mode string
+
+	// Bump is major, minor, patch or prerelease; empty infers it from the
+	// commits since the last release
+	Bump  string
+	Preid string
+
+	// Packages limits the release to these packages; nil releases every
+	// public package
+	Packages []*workspace.Package
+
+	Date time.Time
+}
+
+// Release is the next version of one package
+type Release struct {
+	Package *workspace.Package
+	From    semver.Version
+	To      semver.Version
+
+	// Since is the tag of the previous release, if any
+	Since   string
+	Commits []Commit
+
+	// Changesets are the paths of the changesets naming the package
+	Changesets []string
+
+	// Changes are the changelog lines of the release, from its commits or
+	// changesets
+	Changes []Change
+
+	// Dependencies are the released packages this one is bumped for
+	Dependencies []string
+}
+
+// Tag names the release's git tag, e.g. @sldm/core@0.4.0
+func (r *Release) Tag() string {
+	return r.Package.Name + "@" + r.To.String()
+}
+
+// Plan is a set of releases with the files they change
+type Plan struct {
+	Mode     string
+	Releases []*Release
+
+	// Files maps workspace-relative paths to their new contents, in the
+	// order of Paths
+	Files map[string][]byte
+	Paths []string
+
+	ws *workspace.Workspace
+}
+
+// NewPlan works out the next version of each package from opts and the git
+// history of repo, the repository root, and prepares the package.json and
+// changelog changes. Nothing is written until Apply.
+func NewPlan(ws *workspace.Workspace, repo string, opts Options) (*Plan, error) {
+	if err := checkMode(opts.Mode); err != nil {
+		return nil, err
+	}
+	selected := opts.Packages
+	if selected == nil {
+		selected = ws.Packages
+	}
+
+	var candidates []*Release
+	for _, pkg := range selected {
+		if !releasable(pkg) {
+			continue
+		}
+		r, err := newRelease(pkg)
+		if err != nil {
+			return nil, err
+		}
+		if r.Since, err = lastTag(repo, pkg.Name); err != nil {
+			return nil, err
+		}
+		log, err := git.Log(repo, r.Since, ws.Path(pkg.Dir))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range log {
+			if c, ok := ParseCommit(e); ok {
+				r.Commits = append(r.Commits, c)
+				if change, ok := c.Change(); ok {
+					r.Changes = append(r.Changes, change)
+				}
+			}
+		}
+		// Unchanged packages only take part when picked or versioned together
+		if len(log) > 0 || opts.Packages != nil || opts.Mode == Fixed {
+			candidates = append(candidates, r)
+		}
+	}
+	return newPlan(ws, opts, candidates)
+}
+
+// NewChangesetPlan releases the packages named in changesets, each with the
+// largest bump asked for it, instead of reading the git history of repo.
+// opts.Bump and opts.Packages are ignored.
+func NewChangesetPlan(ws *workspace.Workspace, repo string, sets []*Changeset, opts Options) (*Plan, error) {
+	if err := checkMode(opts.Mode); err != nil {
+		return nil, err
+	}
+	named := make(map[string]*Release)
+	for _, set := range sets {
+		if err := set.Validate(ws); err != nil {
+			return nil, err
+		}
+		for _, name := range sortedNames(set.Releases) {
+			r := named[name]
+			if r == nil {
+				pkg, _ := ws.Lookup(name)
+				var err error
+				if r, err = newRelease(pkg); err != nil {
+					return nil, err
+				}
+				named[name] = r
+			}
+			r.Changesets = append(r.Changesets, set.Path)
+			r.Changes = append(r.Changes, set.Change(set.Releases[name]))
+		}
+	}
+	if len(named) == 0 {
+		return nil, fmt.Errorf("nothing to release: no changeset names a package")
+	}
+
+	var candidates []*Release
+	for _, pkg := range ws.Packages {
+		r := named[pkg.Name]
+		if r == nil && opts.Mode == Fixed && releasable(pkg) {
+			var err error
+			if r, err = newRelease(pkg); err != nil {
+				return nil, err
+			}
+		}
+		if r == nil {
+			continue
+		}
+		var err error
+		if r.Since, err = lastTag(repo, r.Package.Name); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, r)
+	}
+	opts.Bump = ""
+	return newPlan(ws, opts, candidates)
+}
+
+func checkMode(mode string) error {
+	if mode != Fixed && mode != Independent {
+		return fmt.Errorf("unknown versioning mode %q, expected fixed or independent", mode)
+	}
+	return nil
+}
+
+// releasable reports whether a package is published, and so versioned
+func releasable(pkg *workspace.Package) bool {
+	return !pkg.Private && pkg.Name != "" && pkg.Version != ""
+}
+
+func newRelease(pkg *workspace.Package) (*Release, error) {
+	from, err := semver.Parse(pkg.Version)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pkg.Name, err)
+	}
+	return &Release{Package: pkg, From: from}, nil
+}
+
+// newPlan bumps the candidates, the same for all in fixed mode, and
+// prepares the file changes
+func newPlan(ws *workspace.Workspace, opts Options, candidates []*Release) (*Plan, error) {
+	p := &Plan{Mode: opts.Mode, Files: make(map[string][]byte), ws: ws}
+	if opts.Mode == Fixed {
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("no public packages to version")
+		}
+		latest := candidates[0].From
+		var all []Change
+		for _, r := range candidates {
+			if semver.Compare(r.From, latest) > 0 {
+				latest = r.From
+			}
+			all = append(all, r.Changes...)
+		}
+		kind := opts.Bump
+		if kind == "" {
+			if kind = Infer(all, latest); kind == "" {
+				return nil, fmt.Errorf("no features, fixes or breaking changes since the last release; name the bump: major, minor, patch or prerelease")
+			}
+		}
+		to, err := semver.Bump(latest, kind, opts.Preid)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range candidates {
+			r.To = to
+		}
+		p.Releases = candidates
+	} else {
+		for _, r := range candidates {
+			kind := opts.Bump
+			if kind == "" {
+				kind = Infer(r.Changes, r.From)
+			}
+			if kind == "" {
+				continue
+			}
+			to, err := semver.Bump(r.From, kind, opts.Preid)
+			if err != nil {
+				return nil, err
+			}
+			r.To = to
+			p.Releases = append(p.Releases, r)
+		}
+		if err := p.addDependents(opts); err != nil {
+			return nil, err
+		}
+	}
+	if len(p.Releases) == 0 {
+		return nil, fmt.Errorf("nothing to release: no package has features, fixes or breaking changes since its last release")
+	}
+	sort.Slice(p.Releases, func(i, j int) bool { return p.Releases[i].Package.Name < p.Releases[j].Package.Name })
+
+	if err := p.prepare(opts.Date); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// addDependents releases the public packages that depend on a released one
+// through a workspace: range, since their published dependency changes
+func (p *Plan) addDependents(opts Options) error {
+	kind := semver.Patch
+	if opts.Bump == semver.Prerelease {
+		kind = semver.Prerelease
+	}
+	released := make(map[string]*Release)
+	for _, r := range p.Releases {
+		released[r.Package.Name] = r
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, pkg := range p.ws.Packages {
+			if !releasable(pkg) || released[pkg.Name] != nil {
+				continue
+			}
+			var deps []string
+			for _, m := range []map[string]string{pkg.Dependencies, pkg.PeerDependencies} {
+				for name, spec := range m {
+					if released[name] != nil && strings.HasPrefix(spec, "workspace:") {
+						deps = append(deps, name)
+					}
+				}
+			}
+			if len(deps) == 0 {
+				continue
+			}
+			r, err := newRelease(pkg)
+			if err != nil {
+				return err
+			}
+			if r.To, err = semver.Bump(r.From, kind, opts.Preid); err != nil {
+				return err
+			}
+			sort.Strings(deps)
+			r.Dependencies = deps
+			p.Releases = append(p.Releases, r)
+			released[pkg.Name] = r
+			changed = true
+		}
+	}
+	return nil
+}
+
+// lastTag returns the tag of the newest release of a package, or "" before
+// its first one
+func lastTag(repo, name string) (string, error) {
+	tags, err := git.Tags(repo, name+"@*")
+	if err != nil {
+		return "", err
+	}
+	var last string
+	var newest semver.Version
+	for _, tag := range tags {
+		v, err := semver.Parse(strings.TrimPrefix(tag, name+"@"))
+		if err != nil {
+			continue
+		}
+		if last == "" || semver.Compare(v, newest) > 0 {
+			last, newest = tag, v
+		}
+	}
+	return last, nil
+}
+
+// workspaceRange matches ranges that pin a version, e.g. workspace:^0.3.0;
+// workspace:*, workspace:^ and workspace:~ follow the package by themselves
+var workspaceRange = regexp.MustCompile(`^workspace:(\^|~|>=|=)?(\d+\.\d+\.\d+\S*)$`)
+
+// prepare computes the new package.json files and changelogs
+func (p *Plan) prepare(date time.Time) error {
+	released := make(map[string]*Release)
+	for _, r := range p.Releases {
+		released[r.Package.Name] = r
+		if err := p.edit(filepath.Join(r.Package.Dir, "package.json"), []string{"version"}, r.To.String()); err != nil {
+			return err
+		}
+	}
+
+	// The private root package follows a fixed version
+	root := p.ws.RootPackage
+	if p.Mode == Fixed && root != nil && root.Version != "" && released[root.Name] == nil {
+		if err := p.edit("package.json", []string{"version"}, p.Releases[0].To.String()); err != nil {
+			return err
+		}
+	}
+
+	pkgs := p.ws.Packages
+	if root != nil && p.ws.IsMonorepo() {
+		pkgs = append([]*workspace.Package{root}, pkgs...)
+	}
+	for _, pkg := range pkgs {
+		fields := []struct {
+			name string
+			deps map[string]string
+		}{
+			{"dependencies", pkg.Dependencies},
+			{"devDependencies", pkg.DevDependencies},
+			{"peerDependencies", pkg.PeerDependencies},
+		}
+		for _, field := range fields {
+			for _, name := range sortedNames(field.deps) {
+				r := released[name]
+				m := workspaceRange.FindStringSubmatch(field.deps[name])
+				if r == nil || m == nil {
+					continue
+				}
+				spec := "workspace:" + m[1] + r.To.String()
+				if err := p.edit(filepath.Join(pkg.Dir, "package.json"), []string{field.name, name}, spec); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if p.Mode == Fixed {
+		var changes []Change
+		for _, r := range p.Releases {
+			changes = append(changes, r.Changes...)
+		}
+		return p.prependChangelog(ChangelogFile, Entry(p.Releases[0].To.String(), date, changes))
+	}
+	for _, r := range p.Releases {
+		changes := r.Changes
+		if len(r.Dependencies) > 0 {
+			deps := make([]string, len(r.Dependencies))
+			for i, name := range r.Dependencies {
+				deps[i] = released[name].Tag()
+			}
+			changes = append(changes, Change{Bump: semver.Patch, Section: "Changed", Text: "Updated dependencies: " + strings.Join(deps, ", ")})
+		}
+		path := filepath.Join(r.Package.Dir, ChangelogFile)
+		if err := p.prependChangelog(path, Entry(r.To.String(), date, changes)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// read returns the planned contents of a workspace-relative file, or what
+// is on disk
+func (p *Plan) read(rel string) ([]byte, error) {
+	if data, ok := p.Files[rel]; ok {
+		return data, nil
+	}
+	data, err := os.ReadFile(p.ws.Path(rel))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (p *Plan) write(rel string, data []byte) {
+	if _, ok := p.Files[rel]; !ok {
+		p.Paths = append(p.Paths, rel)
+	}
+	p.Files[rel] = data
+}
+
+// edit sets a string in a package.json, keeping its formatting
+func (p *Plan) edit(rel string, path []string, value string) error {
+	data, err := p.read(rel)
+	if err != nil {
+		return err
+	}
+	updated, err := jsonc.SetString(data, path, value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", rel, err)
+	}
+	p.write(rel, updated)
+	return nil
+}
+
+func (p *Plan) prependChangelog(rel, entry string) error {
+	data, err := p.read(rel)
+	if err != nil {
+		return err
+	}
+	p.write(rel, []byte(Prepend(string(data), entry)))
+	return nil
+}
+
+// Original returns the current contents of a planned file, empty for new
+// files
+func (p *Plan) Original(rel string) string {
+	data, _ := os.ReadFile(p.ws.Path(rel))
+	return string(data)
+}
+
+// Apply writes the planned files
+func (p *Plan) Apply() error {
+	for _, rel := range p.Paths {
+		if err := os.WriteFile(p.ws.Path(rel), p.Files[rel], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/kluth/solidum-cli/internal/coverage"
+	"github.com/kluth/solidum-cli/internal/size"
+	"github.com/kluth/solidum-cli/internal/vitest"
+)
+
+// The types below match the interfaces of the same name in
+// @sldm/dev-reports, so the JSON report can be fed to its generators
+
+type buildInfo struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	BuildTime   int64  `json:"buildTime"`
+	Timestamp   int64  `json:"timestamp"`
+	Environment string `json:"environment"`
+	Branch      string `json:"branch,omitempty"`
+	Commit      string `json:"commit,omitempty"`
+}
+
+type testResult struct {
+	Name     string  `json:"name"`
+	Passed   bool    `json:"passed"`
+	Duration float64 `json:"duration"`
+	Error    string  `json:"error,omitempty"`
+}
+
+type testSuite struct {
+	Name     string        `json:"name"`
+	Tests    []*testResult `json:"tests"`
+	Passed   bool          `json:"passed"`
+	Duration float64       `json:"duration"`
+}
+
+type testReport struct {
+	Name          string          `json:"name"`
+	Suites        []*testSuite    `json:"suites"`
+	TotalTests    int             `json:"totalTests"`
+	PassedTests   int             `json:"passedTests"`
+	FailedTests   int             `json:"failedTests"`
+	SkippedTests  int             `json:"skippedTests"`
+	TotalDuration float64         `json:"totalDuration"`
+	Timestamp     int64           `json:"timestamp"`
+	Coverage      *coverageReport `json:"coverage,omitempty"`
+}
+
+type coverageMetrics struct {
+	Total      int     `json:"total"`
+	Covered    int     `json:"covered"`
+	Skipped    int     `json:"skipped"`
+	Percentage float64 `json:"percentage"`
+}
+
+type coverageData struct {
+	Lines      coverageMetrics `json:"lines"`
+	Statements coverageMetrics `json:"statements"`
+	Functions  coverageMetrics `json:"functions"`
+	Branches   coverageMetrics `json:"branches"`
+}
+
+type fileCoverage struct {
+	File     string       `json:"file"`
+	Coverage coverageData `json:"coverage"`
+}
+
+type coverageReport struct {
+	Overall   coverageData    `json:"overall"`
+	Files     []*fileCoverage `json:"files"`
+	Timestamp int64           `json:"timestamp"`
+}
+
+type devReport struct {
+	Build     buildInfo       `json:"build"`
+	Bundle    *size.Report    `json:"bundle,omitempty"`
+	Tests     *testReport     `json:"tests,omitempty"`
+	Coverage  *coverageReport `json:"coverage,omitempty"`
+	Timestamp int64           `json:"timestamp"`
+
+	// Packages is an extra: the per-package summary
+	Packages []*Package `json:"packages"`
+}
+
+// JSON renders the report as a DevReport, ending with a newline
+func (r *Report) JSON() ([]byte, error) {
+	now := r.Timestamp.UnixMilli()
+	dr := &devReport{
+		Build: buildInfo{
+			Name:        r.Build.Name,
+			Version:     r.Build.Version,
+			Timestamp:   now,
+			Environment: r.Build.Environment,
+			Branch:      r.Build.Branch,
+			Commit:      r.Build.Commit,
+		},
+		Bundle:    r.Bundle,
+		Timestamp: now,
+		Packages:  r.Packages,
+	}
+	if dr.Packages == nil {
+		dr.Packages = []*Package{}
+	}
+
+	if r.Total.Tests != nil {
+		t := r.Total.Tests
+		dr.Tests = &testReport{
+			Name:          r.Build.Name,
+			Suites:        []*testSuite{},
+			TotalTests:    t.Total,
+			PassedTests:   t.Passed,
+			FailedTests:   t.Failed,
+			SkippedTests:  t.Skipped,
+			TotalDuration: t.DurationMs,
+			Timestamp:     now,
+		}
+		for _, name := range sortedNames(r.Results) {
+			suite := &testSuite{Name: name, Tests: []*testResult{}, Passed: true}
+			for _, f := range r.Results[name].Files {
+				suite.Duration += ms(f.Duration)
+				if f.Error != "" {
+					suite.Passed = false
+					suite.Tests = append(suite.Tests, &testResult{Name: r.Rel(f.Name), Error: f.Error})
+				}
+				for _, t := range f.Tests {
+					if t.Status == vitest.Skipped {
+						continue
+					}
+					passed := t.Status == vitest.Passed
+					suite.Passed = suite.Passed && passed
+					suite.Tests = append(suite.Tests, &testResult{
+						Name:     t.FullName(),
+						Passed:   passed,
+						Duration: ms(t.Duration),
+						Error:    t.Error,
+					})
+				}
+			}
+			dr.Tests.Suites = append(dr.Tests.Suites, suite)
+		}
+	}
+
+	if r.Total.Coverage != nil {
+		dr.Coverage = &coverageReport{Overall: toData(*r.Total.Coverage), Files: []*fileCoverage{}, Timestamp: now}
+		for _, name := range sortedNames(r.Coverage) {
+			files := r.Coverage[name].Files
+			paths := make([]string, 0, len(files))
+			for path := range files {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			for _, path := range paths {
+				dr.Coverage.Files = append(dr.Coverage.Files, &fileCoverage{File: r.Rel(path), Coverage: toData(files[path])})
+			}
+		}
+		if dr.Tests != nil {
+			dr.Tests.Coverage = dr.Coverage
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(dr); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func toData(s coverage.Summary) coverageData {
+	metrics := func(m coverage.Metrics) coverageMetrics {
+		return coverageMetrics{Total: m.Total, Covered: m.Covered, Skipped: m.Skipped, Percentage: m.Pct}
+	}
+	return coverageData{
+		Lines:      metrics(s.Lines),
+		Statements: metrics(s.Statements),
+		Functions:  metrics(s.Functions),
+		Branches:   metrics(s.Branches),
+	}
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
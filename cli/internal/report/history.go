@@ -0,0 +1,207 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/kluth/solidum-cli/internal/coverage"
+	"github.com/kluth/solidum-cli/internal/size"
+)
+
+// HistoryDir holds one summary per report run, relative to the output
+// directory
+const HistoryDir = "history"
+
+// Entry is the summary of one report kept in the history
+type Entry struct {
+	Timestamp time.Time  `json:"timestamp"`
+	Branch    string     `json:"branch,omitempty"`
+	Commit    string     `json:"commit,omitempty"`
+	Total     *Package   `json:"total"`
+	Packages  []*Package `json:"packages"`
+
+	file string
+}
+
+// Entry summarizes the report for the history
+func (r *Report) Entry() *Entry {
+	return &Entry{
+		Timestamp: r.Timestamp,
+		Branch:    r.Build.Branch,
+		Commit:    r.Build.Commit,
+		Total:     r.Total,
+		Packages:  r.Packages,
+	}
+}
+
+// Package returns the entry's summary of a package, or nil
+func (e *Entry) Package(name string) *Package {
+	for _, p := range e.Packages {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// LoadHistory reads the entries in dir, oldest first
+func LoadHistory(dir string) ([]*Entry, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var entries []*Entry
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var e Entry
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if e.Total == nil {
+			e.Total = &Package{Name: "Total"}
+		}
+		e.file = file
+		entries = append(entries, &e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+// Previous returns the latest entry from another commit, which is what the
+// current report is compared against
+func Previous(history []*Entry, commit string) *Entry {
+	for i := len(history) - 1; i >= 0; i-- {
+		if commit == "" || history[i].Commit != commit {
+			return history[i]
+		}
+	}
+	return nil
+}
+
+// SaveEntry writes e into dir as <date>-<time>-<commit>.json. Earlier
+// entries for the same commit are replaced, so re-running the report
+// doesn't flood the trend.
+func SaveEntry(dir string, e *Entry, history []*Entry) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if e.Commit != "" {
+		for _, old := range history {
+			if old.Commit == e.Commit && old.file != "" {
+				if err := os.Remove(old.file); err != nil && !os.IsNotExist(err) {
+					return "", err
+				}
+			}
+		}
+	}
+
+	name := e.Timestamp.Format("20060102-150405")
+	if e.Commit != "" {
+		name += "-" + e.Commit
+	}
+	file := filepath.Join(dir, name+".json")
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	e.file = file
+	return file, os.WriteFile(file, append(data, '\n'), 0644)
+}
+
+// Regression is a metric that got worse since the previous entry
+type Regression struct {
+	Package  string
+	Metric   string
+	Previous string
+	Current  string
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s: %s %s → %s", r.Package, r.Metric, r.Previous, r.Current)
+}
+
+// sizeTolerance is the relative gzip growth that is not yet a regression
+const sizeTolerance = 0.01
+
+// Regressions compares the current entry with a previous one: more failing
+// tests, lower coverage and bigger gzipped bundles are reported, for the
+// total and for each package present in both
+func Regressions(previous, current *Entry) []Regression {
+	var out []Regression
+	out = append(out, compare("Total", previous.Total, current.Total)...)
+	for _, p := range current.Packages {
+		if old := previous.Package(p.Name); old != nil {
+			out = append(out, compare(p.Name, old, p)...)
+		}
+	}
+	return out
+}
+
+func compare(name string, old, cur *Package) []Regression {
+	var out []Regression
+	add := func(metric, previous, current string) {
+		out = append(out, Regression{Package: name, Metric: metric, Previous: previous, Current: current})
+	}
+
+	if old.Tests != nil && cur.Tests != nil && cur.Tests.Failed > old.Tests.Failed {
+		add("failed tests", fmt.Sprint(old.Tests.Failed), fmt.Sprint(cur.Tests.Failed))
+	}
+
+	if old.Coverage != nil && cur.Coverage != nil {
+		metrics := []struct {
+			name     string
+			old, cur float64
+		}{
+			{"line coverage", old.Coverage.Lines.Pct, cur.Coverage.Lines.Pct},
+			{"statement coverage", old.Coverage.Statements.Pct, cur.Coverage.Statements.Pct},
+			{"function coverage", old.Coverage.Functions.Pct, cur.Coverage.Functions.Pct},
+			{"branch coverage", old.Coverage.Branches.Pct, cur.Coverage.Branches.Pct},
+		}
+		for _, m := range metrics {
+			// Percentages carry two decimals
+			if m.old-m.cur >= 0.01 {
+				add(m.name, coverage.FormatPercent(m.old), coverage.FormatPercent(m.cur))
+			}
+		}
+	}
+
+	if old.BundleSize != nil && cur.BundleSize != nil {
+		before, after := old.BundleSize.GzipSize, cur.BundleSize.GzipSize
+		if float64(after) > float64(before)*(1+sizeTolerance) {
+			add("gzip size", size.Format(before), size.Format(after))
+		}
+	}
+	return out
+}
+
+// SetHistory attaches the trend to the report: the last limit entries of
+// history ending with this report, and the regressions since the previous
+// commit's report
+func (r *Report) SetHistory(history []*Entry, limit int) {
+	current := r.Entry()
+	if previous := Previous(history, current.Commit); previous != nil {
+		r.Previous = previous
+		r.Regressions = Regressions(previous, current)
+	}
+
+	var trend []*Entry
+	for _, e := range history {
+		if current.Commit == "" || e.Commit != current.Commit {
+			trend = append(trend, e)
+		}
+	}
+	trend = append(trend, current)
+	if limit > 0 && len(trend) > limit {
+		trend = trend[len(trend)-limit:]
+	}
+	r.Trend = trend
+}
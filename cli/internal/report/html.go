@@ -0,0 +1,384 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/coverage"
+	"github.com/kluth/solidum-cli/internal/size"
+)
+
+// htmlTemplate follows the layout and styling of the report that
+// scripts/generate-dev-report.js used to produce
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"size":       size.Format,
+	"percent":    coverage.FormatPercent,
+	"tests":      testsCell,
+	"failed":     failedCell,
+	"duration":   durationCell,
+	"lines":      func(p *Package) string { return coverageCell(p, lines) },
+	"statements": func(p *Package) string { return coverageCell(p, statements) },
+	"functions":  func(p *Package) string { return coverageCell(p, functions) },
+	"branches":   func(p *Package) string { return coverageCell(p, branches) },
+	"gzip":       gzipCell,
+	"date":       shortDate,
+	"commit":     commitCell,
+	"rate":       func(t *Tests) string { return fmt.Sprintf("%.1f", successRate(t)) },
+	"ms":         func(f float64) string { return fmt.Sprintf("%.0f", f) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <meta name="viewport" content="width=device-width, initial-scale=1.0">
+  <title>{{.Build.Name}} - Development Report</title>
+  <style>
+    * { margin: 0; padding: 0; box-sizing: border-box; }
+    body {
+      font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif;
+      line-height: 1.6;
+      color: #333;
+      background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+      min-height: 100vh;
+      padding: 20px;
+    }
+    .container { max-width: 1200px; margin: 0 auto; }
+    .header {
+      background: white;
+      padding: 40px;
+      border-radius: 16px;
+      box-shadow: 0 20px 60px rgba(0,0,0,0.3);
+      margin-bottom: 30px;
+      text-align: center;
+    }
+    h1 { color: #667eea; font-size: 48px; margin-bottom: 10px; font-weight: 700; }
+    .meta { color: #666; font-size: 16px; }
+    .card {
+      background: white;
+      padding: 30px;
+      border-radius: 16px;
+      box-shadow: 0 10px 30px rgba(0,0,0,0.2);
+      margin-bottom: 30px;
+    }
+    h2 {
+      color: #667eea;
+      font-size: 28px;
+      margin-bottom: 20px;
+      border-bottom: 3px solid #667eea;
+      padding-bottom: 15px;
+      font-weight: 700;
+    }
+    h3 { color: #555; margin: 25px 0 10px; }
+    .stats {
+      display: grid;
+      grid-template-columns: repeat(auto-fit, minmax(220px, 1fr));
+      gap: 20px;
+      margin: 25px 0;
+    }
+    .stat {
+      background: linear-gradient(135deg, #667eea 0%, #764ba2 100%);
+      color: white;
+      padding: 25px;
+      border-radius: 12px;
+      text-align: center;
+      box-shadow: 0 5px 15px rgba(102, 126, 234, 0.4);
+      transition: transform 0.2s;
+    }
+    .stat:hover { transform: translateY(-5px); }
+    .stat.failed { background: linear-gradient(135deg, #ef4444 0%, #b91c1c 100%); }
+    .stat-value { font-size: 36px; font-weight: bold; margin-bottom: 8px; }
+    .stat-label { font-size: 14px; opacity: 0.95; text-transform: uppercase; letter-spacing: 1px; }
+    table {
+      width: 100%;
+      border-collapse: collapse;
+      margin: 20px 0;
+    }
+    th, td {
+      text-align: left;
+      padding: 15px;
+      border-bottom: 1px solid #e5e7eb;
+    }
+    th {
+      background: #f8f9fa;
+      font-weight: 700;
+      color: #555;
+      text-transform: uppercase;
+      font-size: 12px;
+      letter-spacing: 0.5px;
+    }
+    tr:hover { background: #f8f9fa; }
+    tr.total td { font-weight: 700; }
+    .progress-bar {
+      height: 12px;
+      background: #e5e7eb;
+      border-radius: 6px;
+      overflow: hidden;
+      margin: 15px 0;
+    }
+    .progress-fill {
+      height: 100%;
+      background: linear-gradient(90deg, #667eea 0%, #764ba2 100%);
+      transition: width 0.3s ease;
+    }
+    .rate { text-align: center; margin-top: 15px; font-size: 18px; font-weight: 600; color: #10b981; }
+    .rate.failed { color: #ef4444; }
+    .failures li, .regressions li { margin: 8px 0 8px 20px; }
+    .failures code { color: #b91c1c; }
+    .regressions { color: #b45309; }
+    .ok { color: #10b981; font-weight: 600; }
+    .footer {
+      text-align: center;
+      color: white;
+      font-size: 16px;
+      margin-top: 50px;
+      padding: 20px;
+    }
+    .footer a { color: white; text-decoration: underline; }
+  </style>
+</head>
+<body>
+  <div class="container">
+    <div class="header">
+      <h1>📊 {{.Build.Name}}</h1>
+      <div class="meta">
+        <strong>Version:</strong> {{.Build.Version}} |
+        <strong>Environment:</strong> {{.Build.Environment}} |
+        {{- if .Build.Branch}}
+        <strong>Branch:</strong> {{.Build.Branch}} |
+        {{- end}}
+        {{- if .Build.Commit}}
+        <strong>Commit:</strong> <code>{{.Build.Commit}}</code> |
+        {{- end}}
+        <strong>Generated:</strong> {{.Timestamp.Local.Format "2006-01-02 15:04:05"}}
+      </div>
+    </div>
+{{- if .Packages}}
+
+    <div class="card">
+      <h2>📋 Packages</h2>
+      <table>
+        <thead>
+          <tr>
+            <th>Package</th>
+            <th>Tests</th>
+            <th>Failed</th>
+            <th>Duration</th>
+            <th>Lines</th>
+            <th>Branches</th>
+            <th>Functions</th>
+            <th>Gzip</th>
+          </tr>
+        </thead>
+        <tbody>
+{{- range .Packages}}
+          <tr>
+            <td><strong>{{.Name}}</strong></td>
+            <td>{{tests .}}</td>
+            <td>{{failed .}}</td>
+            <td>{{duration .}}</td>
+            <td>{{lines .}}</td>
+            <td>{{branches .}}</td>
+            <td>{{functions .}}</td>
+            <td>{{gzip .}}</td>
+          </tr>
+{{- end}}
+          <tr class="total">
+            <td>Total</td>
+            <td>{{tests .Total}}</td>
+            <td>{{failed .Total}}</td>
+            <td>{{duration .Total}}</td>
+            <td>{{lines .Total}}</td>
+            <td>{{branches .Total}}</td>
+            <td>{{functions .Total}}</td>
+            <td>{{gzip .Total}}</td>
+          </tr>
+        </tbody>
+      </table>
+    </div>
+{{- end}}
+{{- with .Bundle}}
+
+    <div class="card">
+      <h2>📦 Bundle Size</h2>
+      <div class="stats">
+        <div class="stat">
+          <div class="stat-value">{{size .TotalSize}}</div>
+          <div class="stat-label">Total Size</div>
+        </div>
+        <div class="stat">
+          <div class="stat-value">{{size .TotalGzipSize}}</div>
+          <div class="stat-label">Gzipped</div>
+        </div>
+        <div class="stat">
+          <div class="stat-value">{{size .TotalBrotliSize}}</div>
+          <div class="stat-label">Brotli</div>
+        </div>
+      </div>
+      <table>
+        <thead>
+          <tr>
+            <th>File</th>
+            <th>Size</th>
+            <th>Gzip</th>
+            <th>Brotli</th>
+          </tr>
+        </thead>
+        <tbody>
+{{- range .Bundles}}
+          <tr>
+            <td><strong>{{.File}}</strong></td>
+            <td>{{size .Size}}</td>
+            <td>{{size .GzipSize}}</td>
+            <td>{{size .BrotliSize}}</td>
+          </tr>
+{{- end}}
+        </tbody>
+      </table>
+    </div>
+{{- end}}
+{{- with .Total.Tests}}
+
+    <div class="card">
+      <h2>✅ Test Results</h2>
+      <div class="stats">
+        <div class="stat">
+          <div class="stat-value">{{.Total}}</div>
+          <div class="stat-label">Total Tests</div>
+        </div>
+        <div class="stat">
+          <div class="stat-value">{{.Passed}}</div>
+          <div class="stat-label">Passed</div>
+        </div>
+        <div class="stat{{if .Failed}} failed{{end}}">
+          <div class="stat-value">{{.Failed}}</div>
+          <div class="stat-label">Failed</div>
+        </div>
+        <div class="stat">
+          <div class="stat-value">{{ms .DurationMs}}ms</div>
+          <div class="stat-label">Duration</div>
+        </div>
+      </div>
+      <div class="progress-bar">
+        <div class="progress-fill" style="width: {{rate .}}%"></div>
+      </div>
+      <p class="rate{{if .Failed}} failed{{end}}">{{rate .}}% Success Rate{{if not .Failed}} ✨{{end}}</p>
+{{- end}}
+{{- if .Total.Tests}}
+{{- with .Failures}}
+      <h3>Failures</h3>
+      <ul class="failures">
+{{- range .List}}
+        <li><strong>{{.Package}}</strong> › {{.Name}}{{if .Message}}: <code>{{.Message}}</code>{{end}}</li>
+{{- end}}
+{{- if .More}}
+        <li>…and {{.More}} more</li>
+{{- end}}
+      </ul>
+{{- end}}
+    </div>
+{{- end}}
+{{- with .Total.Coverage}}
+
+    <div class="card">
+      <h2>🎯 Code Coverage</h2>
+      <div class="stats">
+        <div class="stat">
+          <div class="stat-value">{{percent .Lines.Pct}}</div>
+          <div class="stat-label">Lines</div>
+        </div>
+        <div class="stat">
+          <div class="stat-value">{{percent .Statements.Pct}}</div>
+          <div class="stat-label">Statements</div>
+        </div>
+        <div class="stat">
+          <div class="stat-value">{{percent .Functions.Pct}}</div>
+          <div class="stat-label">Functions</div>
+        </div>
+        <div class="stat">
+          <div class="stat-value">{{percent .Branches.Pct}}</div>
+          <div class="stat-label">Branches</div>
+        </div>
+      </div>
+    </div>
+{{- end}}
+{{- if gt (len .Trend) 1}}
+
+    <div class="card">
+      <h2>📈 Trend</h2>
+{{- if .Regressions}}
+      <p class="regressions"><strong>⚠️ Regressions since <code>{{commit .Previous}}</code>:</strong></p>
+      <ul class="regressions">
+{{- range .Regressions}}
+        <li>{{.}}</li>
+{{- end}}
+      </ul>
+{{- else}}
+      <p class="ok">✅ No regressions since <code>{{commit .Previous}}</code></p>
+{{- end}}
+      <table>
+        <thead>
+          <tr>
+            <th>Date</th>
+            <th>Commit</th>
+            <th>Tests</th>
+            <th>Failed</th>
+            <th>Lines</th>
+            <th>Branches</th>
+            <th>Gzip</th>
+          </tr>
+        </thead>
+        <tbody>
+{{- range .Trend}}
+          <tr>
+            <td>{{date .}}</td>
+            <td><code>{{commit .}}</code></td>
+            <td>{{tests .Total}}</td>
+            <td>{{failed .Total}}</td>
+            <td>{{lines .Total}}</td>
+            <td>{{branches .Total}}</td>
+            <td>{{gzip .Total}}</td>
+          </tr>
+{{- end}}
+        </tbody>
+      </table>
+    </div>
+{{- end}}
+
+    <div class="footer">
+      <p><strong>Generated by solidum report</strong></p>
+      <p>
+        <a href="https://github.com/kluth/solidum" target="_blank">GitHub</a> |
+        <a href="https://www.npmjs.com/org/sldm" target="_blank">npm</a> |
+        <a href="https://kluth.github.io/solidum" target="_blank">Documentation</a>
+      </p>
+    </div>
+  </div>
+</body>
+</html>
+`))
+
+// failureList feeds the failures section of the HTML template
+type failureList struct {
+	List []Failure
+	More int
+}
+
+// htmlData adds the failures to the report for the template
+type htmlData struct {
+	*Report
+	Failures *failureList
+}
+
+// HTML renders the report as a standalone page
+func (r *Report) HTML() (string, error) {
+	data := htmlData{Report: r}
+	if list, more := r.Failures(); len(list) > 0 {
+		data.Failures = &failureList{List: list, More: more}
+	}
+
+	var b strings.Builder
+	if err := htmlTemplate.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
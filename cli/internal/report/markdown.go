@@ -0,0 +1,226 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/coverage"
+	"github.com/kluth/solidum-cli/internal/size"
+	"github.com/kluth/solidum-cli/internal/vitest"
+)
+
+// maxFailures caps the failures listed in a report
+const maxFailures = 20
+
+// Failure is a failed test or test file
+type Failure struct {
+	Package string
+	Name    string
+	Message string
+}
+
+// Failures lists the failed tests of every package, at most maxFailures
+func (r *Report) Failures() (failures []Failure, more int) {
+	for _, name := range sortedNames(r.Results) {
+		for _, f := range r.Results[name].Failed() {
+			if f.Error != "" {
+				failures = append(failures, Failure{Package: name, Name: r.Rel(f.Name), Message: firstLine(f.Error)})
+			}
+			for _, t := range f.Tests {
+				if t.Status == vitest.Failed {
+					failures = append(failures, Failure{Package: name, Name: t.FullName(), Message: firstLine(t.Error)})
+				}
+			}
+		}
+	}
+	if len(failures) > maxFailures {
+		more = len(failures) - maxFailures
+		failures = failures[:maxFailures]
+	}
+	return failures, more
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return s
+}
+
+// Cells of the package and trend tables; "–" marks missing data
+
+func testsCell(p *Package) string {
+	if p.Tests == nil {
+		return "–"
+	}
+	return fmt.Sprintf("%d/%d", p.Tests.Passed, p.Tests.Total)
+}
+
+func failedCell(p *Package) string {
+	if p.Tests == nil {
+		return "–"
+	}
+	return fmt.Sprint(p.Tests.Failed)
+}
+
+func durationCell(p *Package) string {
+	if p.Tests == nil {
+		return "–"
+	}
+	return fmt.Sprintf("%.0fms", p.Tests.DurationMs)
+}
+
+func coverageCell(p *Package, metric func(coverage.Summary) coverage.Metrics) string {
+	if p.Coverage == nil {
+		return "–"
+	}
+	return coverage.FormatPercent(metric(*p.Coverage).Pct)
+}
+
+func gzipCell(p *Package) string {
+	if p.BundleSize == nil {
+		return "–"
+	}
+	return size.Format(p.BundleSize.GzipSize)
+}
+
+func lines(s coverage.Summary) coverage.Metrics      { return s.Lines }
+func statements(s coverage.Summary) coverage.Metrics { return s.Statements }
+func functions(s coverage.Summary) coverage.Metrics  { return s.Functions }
+func branches(s coverage.Summary) coverage.Metrics   { return s.Branches }
+
+func shortDate(e *Entry) string {
+	return e.Timestamp.Local().Format("2006-01-02 15:04")
+}
+
+func commitCell(e *Entry) string {
+	if e.Commit == "" {
+		return "–"
+	}
+	return e.Commit
+}
+
+// Markdown renders the report like MarkdownReportGenerator in
+// @sldm/dev-reports, with per-package results and the trend added
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	w := func(format string, args ...interface{}) { fmt.Fprintf(&b, format, args...) }
+
+	w("# Development Report: %s\n\n", r.Build.Name)
+	w("**Version:** %s  \n", r.Build.Version)
+	w("**Environment:** %s  \n", r.Build.Environment)
+	w("**Generated:** %s  \n", r.Timestamp.Local().Format("2006-01-02 15:04:05"))
+	if r.Build.Branch != "" {
+		w("**Branch:** %s  \n", r.Build.Branch)
+	}
+	if r.Build.Commit != "" {
+		w("**Commit:** `%s`  \n", r.Build.Commit)
+	}
+	w("\n---\n\n")
+
+	if len(r.Packages) > 0 {
+		w("## 📋 Packages\n\n")
+		w("| Package | Tests | Failed | Duration | Lines | Branches | Functions | Gzip |\n")
+		w("|---------|-------|--------|----------|-------|----------|-----------|------|\n")
+		row := func(name string, p *Package) {
+			w("| %s | %s | %s | %s | %s | %s | %s | %s |\n", name, testsCell(p), failedCell(p), durationCell(p),
+				coverageCell(p, lines), coverageCell(p, branches), coverageCell(p, functions), gzipCell(p))
+		}
+		for _, p := range r.Packages {
+			row(p.Name, p)
+		}
+		row("**Total**", r.Total)
+		w("\n")
+	}
+
+	if bundle := r.Bundle; bundle != nil {
+		w("## 📦 Bundle Size\n\n")
+		w("- **Total Size:** %s\n", size.Format(bundle.TotalSize))
+		w("- **Gzipped:** %s\n", size.Format(bundle.TotalGzipSize))
+		if bundle.TotalBrotliSize > 0 {
+			w("- **Brotli:** %s\n", size.Format(bundle.TotalBrotliSize))
+		}
+		w("\n### Bundle Files\n\n")
+		w("| File | Size | Gzip | Brotli |\n")
+		w("|------|------|------|-------|\n")
+		for _, f := range bundle.Bundles {
+			w("| %s | %s | %s | %s |\n", f.File, size.Format(f.Size), size.Format(f.GzipSize), size.Format(f.BrotliSize))
+		}
+		w("\n")
+	}
+
+	if t := r.Total.Tests; t != nil {
+		w("## ✅ Test Results\n\n")
+		w("- **Total Tests:** %d\n", t.Total)
+		w("- **Passed:** %d ✅\n", t.Passed)
+		if t.Failed > 0 {
+			w("- **Failed:** %d ❌\n", t.Failed)
+		}
+		if t.Skipped > 0 {
+			w("- **Skipped:** %d ⏭️\n", t.Skipped)
+		}
+		w("- **Duration:** %.0fms\n", t.DurationMs)
+		w("- **Success Rate:** %.1f%%\n\n", successRate(t))
+
+		if failures, more := r.Failures(); len(failures) > 0 {
+			w("### Failures\n\n")
+			for _, f := range failures {
+				w("- **%s** › %s", f.Package, f.Name)
+				if f.Message != "" {
+					w(": `%s`", strings.ReplaceAll(f.Message, "`", "'"))
+				}
+				w("\n")
+			}
+			if more > 0 {
+				w("- …and %d more\n", more)
+			}
+			w("\n")
+		}
+	}
+
+	if c := r.Total.Coverage; c != nil {
+		w("## 🎯 Code Coverage\n\n")
+		w("| Metric | Coverage |\n")
+		w("|--------|----------|\n")
+		for _, m := range []struct {
+			name string
+			m    coverage.Metrics
+		}{{"Lines", c.Lines}, {"Statements", c.Statements}, {"Functions", c.Functions}, {"Branches", c.Branches}} {
+			w("| %s | %s (%d/%d) |\n", m.name, coverage.FormatPercent(m.m.Pct), m.m.Covered, m.m.Total)
+		}
+		w("\n")
+	}
+
+	if len(r.Trend) > 1 {
+		w("## 📈 Trend\n\n")
+		if len(r.Regressions) > 0 {
+			w("⚠️ **Regressions since `%s`:**\n\n", commitCell(r.Previous))
+			for _, reg := range r.Regressions {
+				w("- %s\n", reg)
+			}
+			w("\n")
+		} else {
+			w("✅ No regressions since `%s`\n\n", commitCell(r.Previous))
+		}
+		w("| Date | Commit | Tests | Failed | Lines | Branches | Gzip |\n")
+		w("|------|--------|-------|--------|-------|----------|------|\n")
+		for _, e := range r.Trend {
+			w("| %s | `%s` | %s | %s | %s | %s | %s |\n", shortDate(e), commitCell(e), testsCell(e.Total), failedCell(e.Total),
+				coverageCell(e.Total, lines), coverageCell(e.Total, branches), gzipCell(e.Total))
+		}
+		w("\n")
+	}
+
+	w("---\n\n")
+	w("*Generated by solidum report*\n")
+	return b.String()
+}
+
+func successRate(t *Tests) float64 {
+	run := t.Passed + t.Failed
+	if run == 0 {
+		return 100
+	}
+	return float64(t.Passed) / float64(run) * 100
+}
@@ -0,0 +1,197 @@
+package report
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kluth/solidum-cli/internal/coverage"
+	"github.com/kluth/solidum-cli/internal/size"
+	"github.com/kluth/solidum-cli/internal/vitest"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// Build identifies what the report was generated from
+type Build struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Branch      string `json:"branch,omitempty"`
+	Commit      string `json:"commit,omitempty"`
+	Environment string `json:"environment"`
+}
+
+// Sizes are a package's summed bundle sizes
+type Sizes struct {
+	Size       int64 `json:"size"`
+	GzipSize   int64 `json:"gzipSize"`
+	BrotliSize int64 `json:"brotliSize"`
+}
+
+// Package merges one package's test results, coverage and size. A nil
+// field means the data was not found.
+type Package struct {
+	Name string `json:"name"`
+
+	Tests      *Tests            `json:"tests,omitempty"`
+	Coverage   *coverage.Summary `json:"coverage,omitempty"`
+	BundleSize *Sizes            `json:"bundleSize,omitempty"`
+}
+
+// Tests counts test results
+type Tests struct {
+	Total      int     `json:"total"`
+	Passed     int     `json:"passed"`
+	Failed     int     `json:"failed"`
+	Skipped    int     `json:"skipped"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+func (t *Tests) add(o *Tests) {
+	t.Total += o.Total
+	t.Passed += o.Passed
+	t.Failed += o.Failed
+	t.Skipped += o.Skipped
+	t.DurationMs += o.DurationMs
+}
+
+// Report is everything collected for one dev report
+type Report struct {
+	Build     Build
+	Timestamp time.Time
+	Packages  []*Package
+
+	// Total sums every package
+	Total *Package
+
+	// Results, Coverage and Bundle keep the detailed data by package name
+	Results  map[string]*vitest.Results
+	Coverage map[string]*coverage.Package
+	Bundle   *size.Report
+
+	// Trend, Previous and Regressions are set by SetHistory
+	Trend       []*Entry
+	Previous    *Entry
+	Regressions []Regression
+
+	root string
+}
+
+// Collect reads each package's test results and coverage from its last test
+// run and merges them with the bundle sizes, which may be nil
+func Collect(ws *workspace.Workspace, pkgs []*workspace.Package, bundle *size.Report) (*Report, error) {
+	r := &Report{
+		Build:     readBuild(ws),
+		Timestamp: time.Now(),
+		Total:     &Package{Name: "Total"},
+		Results:   make(map[string]*vitest.Results),
+		Coverage:  make(map[string]*coverage.Package),
+		Bundle:    bundle,
+		root:      ws.Root,
+	}
+
+	for _, pkg := range pkgs {
+		dir := ws.Path(pkg.Dir)
+		p := &Package{Name: pkg.Name}
+
+		results, err := vitest.Load(filepath.Join(dir, vitest.ResultsFile))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if results != nil {
+			r.Results[pkg.Name] = results
+			passed, failed, skipped := results.Counts()
+			p.Tests = &Tests{
+				Total:      passed + failed + skipped,
+				Passed:     passed,
+				Failed:     failed,
+				Skipped:    skipped,
+				DurationMs: ms(results.Duration()),
+			}
+		}
+
+		cov, ok, err := coverage.LoadPackage(dir)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			r.Coverage[pkg.Name] = cov
+			total := cov.Total
+			p.Coverage = &total
+		}
+
+		if bundle != nil {
+			if b := bundle.Package(pkg.Name); b.Modules > 0 {
+				p.BundleSize = &Sizes{Size: b.Size, GzipSize: b.GzipSize, BrotliSize: b.BrotliSize}
+			}
+		}
+
+		if p.Tests == nil && p.Coverage == nil && p.BundleSize == nil {
+			continue
+		}
+		r.Packages = append(r.Packages, p)
+		r.Total.add(p)
+	}
+	return r, nil
+}
+
+func (p *Package) add(o *Package) {
+	if o.Tests != nil {
+		if p.Tests == nil {
+			p.Tests = &Tests{}
+		}
+		p.Tests.add(o.Tests)
+	}
+	if o.Coverage != nil {
+		if p.Coverage == nil {
+			p.Coverage = &coverage.Summary{}
+		}
+		sum := p.Coverage.Add(*o.Coverage)
+		p.Coverage = &sum
+	}
+	if o.BundleSize != nil {
+		if p.BundleSize == nil {
+			p.BundleSize = &Sizes{}
+		}
+		p.BundleSize.Size += o.BundleSize.Size
+		p.BundleSize.GzipSize += o.BundleSize.GzipSize
+		p.BundleSize.BrotliSize += o.BundleSize.BrotliSize
+	}
+}
+
+// Rel returns a path relative to the workspace root
+func (r *Report) Rel(path string) string {
+	if rel, err := filepath.Rel(r.root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+func readBuild(ws *workspace.Workspace) Build {
+	b := Build{Name: filepath.Base(ws.Root), Version: "0.0.0", Environment: "development"}
+	if ws.RootPackage != nil {
+		if ws.RootPackage.Name != "" {
+			b.Name = ws.RootPackage.Name
+		}
+		if ws.RootPackage.Version != "" {
+			b.Version = ws.RootPackage.Version
+		}
+	}
+	if os.Getenv("CI") != "" {
+		b.Environment = "ci"
+	}
+	b.Branch = git(ws.Root, "rev-parse", "--abbrev-ref", "HEAD")
+	b.Commit = git(ws.Root, "rev-parse", "--short", "HEAD")
+	return b
+}
+
+func git(dir string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
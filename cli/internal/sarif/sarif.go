@@ -0,0 +1,138 @@
+package sarif
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Version is the SARIF version written
+const Version = "2.1.0"
+
+const schema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// Log is the root of a SARIF file
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []*Run `json:"runs"`
+}
+
+// Run is the output of one tool
+type Run struct {
+	Tool    Tool      `json:"tool"`
+	Results []*Result `json:"results"`
+}
+
+// Tool describes the analyzer
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver names the analyzer and lists its rules
+type Driver struct {
+	Name           string  `json:"name"`
+	Version        string  `json:"version,omitempty"`
+	InformationURI string  `json:"informationUri,omitempty"`
+	Rules          []*Rule `json:"rules,omitempty"`
+}
+
+// Rule is a check the tool performs
+type Rule struct {
+	ID               string   `json:"id"`
+	ShortDescription *Message `json:"shortDescription,omitempty"`
+}
+
+// Result is one finding
+type Result struct {
+	RuleID    string     `json:"ruleId"`
+	Level     string     `json:"level"`
+	Message   Message    `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+}
+
+// Message is a plain-text message
+type Message struct {
+	Text string `json:"text"`
+}
+
+// Location points at a region of a file
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+// PhysicalLocation is a file and region
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           *Region          `json:"region,omitempty"`
+}
+
+// ArtifactLocation is a file URI, relative to the repository root
+type ArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// Region is a 1-based position in a file
+type Region struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// New returns a log with a single run for the named tool
+func New(tool, version string) *Log {
+	return &Log{
+		Schema:  schema,
+		Version: Version,
+		Runs:    []*Run{{Tool: Tool{Driver: Driver{Name: tool, Version: version}}, Results: []*Result{}}},
+	}
+}
+
+// Run returns the log's first run
+func (l *Log) Run() *Run {
+	return l.Runs[0]
+}
+
+// AddRule declares a rule once
+func (r *Run) AddRule(id, description string) {
+	for _, rule := range r.Tool.Driver.Rules {
+		if rule.ID == id {
+			return
+		}
+	}
+	r.Tool.Driver.Rules = append(r.Tool.Driver.Rules, &Rule{ID: id, ShortDescription: &Message{Text: description}})
+}
+
+// Add appends a result at file:line:col. level is "error", "warning" or
+// "note"; line 0 leaves out the region.
+func (r *Run) Add(ruleID, level, message, file string, line, col int) {
+	loc := Location{PhysicalLocation: PhysicalLocation{ArtifactLocation: ArtifactLocation{URI: filepath.ToSlash(file)}}}
+	if line > 0 {
+		loc.PhysicalLocation.Region = &Region{StartLine: line, StartColumn: col}
+	}
+	result := &Result{RuleID: ruleID, Level: level, Message: Message{Text: message}}
+	if file != "" {
+		result.Locations = []Location{loc}
+	}
+	r.Results = append(r.Results, result)
+}
+
+// Marshal returns the log as indented JSON
+func (l *Log) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Write saves the log, creating parent directories
+func Write(path string, l *Log) error {
+	data, err := l.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,272 @@
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version such as 1.2.3-beta.1
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease []string
+	Build      string
+}
+
+// Parse parses a version, tolerating a leading "v" and missing minor or
+// patch components ("18" → 18.0.0)
+func Parse(s string) (Version, error) {
+	var v Version
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if s == "" {
+		return v, fmt.Errorf("empty version")
+	}
+
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		v.Build = s[i+1:]
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.Prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid version: %s", s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid version: %s", s)
+		}
+		*nums[i] = n
+	}
+
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Release kinds accepted by Bump
+const (
+	Major      = "major"
+	Minor      = "minor"
+	Patch      = "patch"
+	Prerelease = "prerelease"
+)
+
+// Bump returns the next version of the given kind the way npm version does:
+// a prerelease of 1.0.0 bumps to 1.0.0 itself, and prerelease bumps count
+// up 1.0.1-beta.0 → 1.0.1-beta.1. preid names new prereleases ("beta").
+func Bump(v Version, kind, preid string) (Version, error) {
+	pre := len(v.Prerelease) > 0
+	next := Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch kind {
+	case Major:
+		if !pre || v.Minor != 0 || v.Patch != 0 {
+			next = Version{Major: v.Major + 1}
+		}
+	case Minor:
+		if !pre || v.Patch != 0 {
+			next = Version{Major: v.Major, Minor: v.Minor + 1}
+		}
+	case Patch:
+		if !pre {
+			next.Patch++
+		}
+	case Prerelease:
+		if !pre {
+			next.Patch++
+			next.Prerelease = []string{"0"}
+			if preid != "" {
+				next.Prerelease = []string{preid, "0"}
+			}
+			return next, nil
+		}
+		ids := append([]string(nil), v.Prerelease...)
+		if preid != "" && ids[0] != preid {
+			next.Prerelease = []string{preid, "0"}
+			return next, nil
+		}
+		last := ids[len(ids)-1]
+		if n, err := strconv.Atoi(last); err == nil {
+			ids[len(ids)-1] = strconv.Itoa(n + 1)
+		} else {
+			ids = append(ids, "0")
+		}
+		next.Prerelease = ids
+	default:
+		return v, fmt.Errorf("unknown release %q, expected major, minor, patch or prerelease", kind)
+	}
+	return next, nil
+}
+
+// Compare returns -1, 0 or 1 following semver precedence rules
+func Compare(a, b Version) int {
+	for _, pair := range [][2]int{{a.Major, b.Major}, {a.Minor, b.Minor}, {a.Patch, b.Patch}} {
+		if pair[0] != pair[1] {
+			if pair[0] < pair[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	// A version without prerelease has higher precedence
+	switch {
+	case len(a.Prerelease) == 0 && len(b.Prerelease) == 0:
+		return 0
+	case len(a.Prerelease) == 0:
+		return 1
+	case len(b.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(a.Prerelease) && i < len(b.Prerelease); i++ {
+		if c := compareIdentifier(a.Prerelease[i], b.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a.Prerelease) < len(b.Prerelease):
+		return -1
+	case len(a.Prerelease) > len(b.Prerelease):
+		return 1
+	}
+	return 0
+}
+
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.Atoi(a)
+	bn, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		if an < bn {
+			return -1
+		} else if an > bn {
+			return 1
+		}
+		return 0
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// Satisfies reports whether v matches an npm-style range such as
+// ">=18.0.0", "^0.3.0", "~1.2", "1.x" or "^1 || ^2"
+func Satisfies(v Version, rng string) (bool, error) {
+	rng = strings.TrimSpace(rng)
+	if rng == "" || rng == "*" || rng == "latest" {
+		return true, nil
+	}
+
+	for _, alt := range strings.Split(rng, "||") {
+		ok, err := satisfiesAll(v, strings.Fields(alt))
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func satisfiesAll(v Version, comparators []string) (bool, error) {
+	// Join operators written with a space: ">= 18"
+	var joined []string
+	for i := 0; i < len(comparators); i++ {
+		c := comparators[i]
+		if strings.Trim(c, "<>=~^") == "" && i+1 < len(comparators) {
+			c += comparators[i+1]
+			i++
+		}
+		joined = append(joined, c)
+	}
+
+	for _, c := range joined {
+		ok, err := satisfiesOne(v, c)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func satisfiesOne(v Version, comparator string) (bool, error) {
+	target := strings.TrimLeft(comparator, "<>=~^")
+	op := comparator[:len(comparator)-len(target)]
+	if target == "*" || target == "x" {
+		return true, nil
+	}
+
+	// Partial versions ("1", "1.2", "1.x") constrain only what they specify
+	parts := strings.Split(strings.SplitN(strings.SplitN(target, "-", 2)[0], "+", 2)[0], ".")
+	specified := 0
+	for _, p := range parts {
+		if p == "x" || p == "X" || p == "*" {
+			break
+		}
+		specified++
+	}
+	base, err := Parse(strings.Join(parts[:specified], "."))
+	if err != nil && specified > 0 {
+		return false, fmt.Errorf("invalid range %q: %w", comparator, err)
+	}
+	if specified == len(parts) && strings.Contains(target, "-") {
+		base, _ = Parse(target)
+	}
+
+	c := Compare(v, base)
+	switch op {
+	case ">=":
+		return c >= 0, nil
+	case ">":
+		return c > 0, nil
+	case "<=":
+		return c <= 0, nil
+	case "<":
+		return c < 0, nil
+	case "^":
+		upper := Version{Major: base.Major + 1}
+		if base.Major == 0 && specified > 1 {
+			upper = Version{Minor: base.Minor + 1}
+			if base.Minor == 0 && specified > 2 {
+				upper = Version{Patch: base.Patch + 1}
+			}
+		}
+		return c >= 0 && Compare(v, upper) < 0, nil
+	case "~":
+		upper := Version{Major: base.Major, Minor: base.Minor + 1}
+		if specified == 1 {
+			upper = Version{Major: base.Major + 1}
+		}
+		return c >= 0 && Compare(v, upper) < 0, nil
+	case "", "=":
+		if specified < 3 {
+			upper := Version{Major: base.Major + 1}
+			if specified == 2 {
+				upper = Version{Major: base.Major, Minor: base.Minor + 1}
+			}
+			return c >= 0 && Compare(v, upper) < 0, nil
+		}
+		return c == 0, nil
+	}
+	return false, fmt.Errorf("unsupported range operator %q", op)
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// LivePath is the Server-Sent Events endpoint
+	LivePath = "/__solidum/live"
+
+	// LiveScriptPath serves the reload client injected into HTML pages
+	LiveScriptPath = "/__solidum/live.js"
+)
+
+// liveScript reloads the page on "reload" events and swaps matching
+// stylesheets in place on "css" events, keeping page state
+const liveScript = `(() => {
+  const source = new EventSource('` + LivePath + `');
+  let connected = false;
+  source.addEventListener('open', () => {
+    // The server restarted while we were disconnected
+    if (connected) location.reload();
+    connected = true;
+  });
+  source.addEventListener('reload', () => location.reload());
+  source.addEventListener('css', event => {
+    const changed = JSON.parse(event.data);
+    const links = [...document.querySelectorAll('link[rel="stylesheet"]')];
+    let swapped = false;
+    for (const link of links) {
+      const url = new URL(link.href, location.href);
+      if (url.origin !== location.origin) continue;
+      if (!changed.some(file => url.pathname.endsWith('/' + file))) continue;
+      const next = link.cloneNode();
+      url.searchParams.set('t', Date.now());
+      next.href = url.href;
+      next.addEventListener('load', () => link.remove());
+      link.after(next);
+      swapped = true;
+    }
+    if (!swapped) location.reload();
+  });
+})();
+`
+
+// LiveReload pushes reload events to connected browsers
+type LiveReload struct {
+	mu      sync.Mutex
+	clients map[chan liveEvent]struct{}
+	closed  bool
+}
+
+type liveEvent struct {
+	name string
+	data string
+}
+
+// NewLiveReload creates a live reload hub
+func NewLiveReload() *LiveReload {
+	return &LiveReload{clients: make(map[chan liveEvent]struct{})}
+}
+
+// Notify tells browsers that files changed. Stylesheet-only changes are
+// swapped in place; anything else reloads the page.
+func (l *LiveReload) Notify(changed []string) {
+	css := true
+	for _, file := range changed {
+		if path.Ext(file) != ".css" {
+			css = false
+			break
+		}
+	}
+
+	event := liveEvent{name: "reload", data: "{}"}
+	if css {
+		data, _ := json.Marshal(changed)
+		event = liveEvent{name: "css", data: string(data)}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for c := range l.clients {
+		select {
+		case c <- event:
+		default:
+			// A slow client misses this event; it will get the next one
+		}
+	}
+}
+
+// Clients returns the number of connected browsers
+func (l *LiveReload) Clients() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.clients)
+}
+
+// Close disconnects every browser so the server can shut down
+func (l *LiveReload) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	for c := range l.clients {
+		close(c)
+		delete(l.clients, c)
+	}
+}
+
+// ServeHTTP streams events to one browser
+func (l *LiveReload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	events := make(chan liveEvent, 8)
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	l.clients[events] = struct{}{}
+	l.mu.Unlock()
+
+	defer func() {
+		l.mu.Lock()
+		if _, ok := l.clients[events]; ok {
+			delete(l.clients, events)
+		}
+		l.mu.Unlock()
+	}()
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	fmt.Fprint(w, "retry: 1000\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, event.data)
+		}
+		flusher.Flush()
+	}
+}
+
+func serveLiveScript(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprint(w, liveScript)
+}
+
+// injectLiveScript adds the reload client before </body>, or at the end
+// when the page has no body tag
+func injectLiveScript(html []byte) []byte {
+	tag := []byte(`<script src="` + LiveScriptPath + `"></script>`)
+	lower := bytes.ToLower(html)
+	if i := bytes.LastIndex(lower, []byte("</body>")); i >= 0 {
+		out := make([]byte, 0, len(html)+len(tag))
+		out = append(out, html[:i]...)
+		out = append(out, tag...)
+		return append(out, html[i:]...)
+	}
+	return append(append([]byte(nil), html...), tag...)
+}
+
+// isHTML reports whether name is an HTML page
+func isHTML(name string) bool {
+	return strings.HasSuffix(name, ".html") || strings.HasSuffix(name, ".htm")
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	// The platform MIME tables are often missing or wrong for these
+	types := map[string]string{
+		".js":          "text/javascript; charset=utf-8",
+		".mjs":         "text/javascript; charset=utf-8",
+		".css":         "text/css; charset=utf-8",
+		".html":        "text/html; charset=utf-8",
+		".json":        "application/json",
+		".map":         "application/json",
+		".webmanifest": "application/manifest+json",
+		".svg":         "image/svg+xml",
+		".wasm":        "application/wasm",
+		".woff":        "font/woff",
+		".woff2":       "font/woff2",
+		".ico":         "image/x-icon",
+		".txt":         "text/plain; charset=utf-8",
+		".xml":         "application/xml",
+	}
+	for ext, typ := range types {
+		mime.AddExtensionType(ext, typ)
+	}
+}
+
+// Options configure the server
+type Options struct {
+	// Dir is the directory to serve
+	Dir string
+
+	// Base is the path the app is served under, e.g. "/docs". It matches
+	// the basePath given to @sldm/router's createRouter.
+	Base string
+
+	// SPA serves index.html for unknown routes so client-side routes work
+	// on reload
+	SPA bool
+
+	// Proxies forward path prefixes to other servers
+	Proxies []Proxy
+
+	// Live, when set, injects a reload client into HTML pages and serves
+	// its event stream
+	Live *LiveReload
+}
+
+// Proxy forwards requests whose path starts with Prefix to Target
+type Proxy struct {
+	Prefix string
+	Target *url.URL
+}
+
+// ParseProxy parses a "/api=http://localhost:8080" flag value
+func ParseProxy(value string) (Proxy, error) {
+	prefix, target, ok := strings.Cut(value, "=")
+	if !ok || !strings.HasPrefix(prefix, "/") {
+		return Proxy{}, fmt.Errorf("invalid proxy %q, expected /prefix=http://host:port", value)
+	}
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return Proxy{}, fmt.Errorf("invalid proxy target %q", target)
+	}
+	return Proxy{Prefix: prefix, Target: u}, nil
+}
+
+// NormalizeBase turns "docs", "/docs/" and "/docs" into "/docs"; "" and "/"
+// mean the root
+func NormalizeBase(base string) string {
+	base = strings.Trim(base, "/")
+	if base == "" {
+		return ""
+	}
+	return "/" + base
+}
+
+// New returns a handler serving opts.Dir
+func New(opts Options) (http.Handler, error) {
+	info, err := os.Stat(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", opts.Dir)
+	}
+
+	mux := http.NewServeMux()
+
+	// Longest prefix first, so /api/v2 wins over /api
+	proxies := append([]Proxy(nil), opts.Proxies...)
+	sort.Slice(proxies, func(i, j int) bool { return len(proxies[i].Prefix) > len(proxies[j].Prefix) })
+
+	static := &staticHandler{dir: opts.Dir, base: NormalizeBase(opts.Base), spa: opts.SPA, live: opts.Live}
+
+	if opts.Live != nil {
+		mux.Handle(LivePath, opts.Live)
+		mux.HandleFunc(LiveScriptPath, serveLiveScript)
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		for _, p := range proxies {
+			if r.URL.Path == p.Prefix || strings.HasPrefix(r.URL.Path, strings.TrimSuffix(p.Prefix, "/")+"/") {
+				newProxy(p).ServeHTTP(w, r)
+				return
+			}
+		}
+		static.ServeHTTP(w, r)
+	})
+
+	return mux, nil
+}
+
+func newProxy(p Proxy) http.Handler {
+	proxy := httputil.NewSingleHostReverseProxy(p.Target)
+	director := proxy.Director
+	proxy.Director = func(r *http.Request) {
+		director(r)
+		r.Host = p.Target.Host
+	}
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, fmt.Sprintf("proxy to %s failed: %v", p.Target, err), http.StatusBadGateway)
+	}
+	return proxy
+}
+
+type staticHandler struct {
+	dir  string
+	base string
+	spa  bool
+	live *LiveReload
+}
+
+func (h *staticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	urlPath := r.URL.Path
+	if h.base != "" {
+		if urlPath == "/" {
+			http.Redirect(w, r, h.base+"/", http.StatusFound)
+			return
+		}
+		if urlPath != h.base && !strings.HasPrefix(urlPath, h.base+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		urlPath = strings.TrimPrefix(urlPath, h.base)
+	}
+
+	file, ok := h.resolve(urlPath)
+	if !ok {
+		if !h.spa || !isRoute(urlPath, r) {
+			http.NotFound(w, r)
+			return
+		}
+		// Client-side route: let the router pick it up from location.pathname
+		file, ok = h.resolve("/")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	h.serveFile(w, r, file)
+}
+
+// resolve maps a URL path to a file, using index.html for directories. Routes
+// prerendered to <route>/index.html are found without a trailing slash.
+func (h *staticHandler) resolve(urlPath string) (string, bool) {
+	name := filepath.Join(h.dir, filepath.FromSlash(path.Clean("/"+urlPath)))
+
+	info, err := os.Stat(name)
+	if err == nil && info.IsDir() {
+		name = filepath.Join(name, "index.html")
+		info, err = os.Stat(name)
+	}
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return name, true
+}
+
+// isRoute reports whether a request for a missing file is a navigation to a
+// client-side route rather than a missing asset. Router paths have no file
+// extension; browsers navigating send Accept: text/html.
+func isRoute(urlPath string, r *http.Request) bool {
+	if path.Ext(urlPath) == "" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// serveFile serves name, or a precompressed .br/.gz sibling the client
+// accepts, with an ETag and caching headers
+func (h *staticHandler) serveFile(w http.ResponseWriter, r *http.Request, name string) {
+	if h.live != nil && isHTML(name) {
+		h.serveLiveHTML(w, r, name)
+		return
+	}
+
+	served := name
+	accept := r.Header.Get("Accept-Encoding")
+	for _, enc := range []struct{ name, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+		if !acceptsEncoding(accept, enc.name) {
+			continue
+		}
+		if info, err := os.Stat(name + enc.ext); err == nil && !info.IsDir() {
+			served = name + enc.ext
+			w.Header().Set("Content-Encoding", enc.name)
+			break
+		}
+	}
+
+	f, err := os.Open(served)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	header := w.Header()
+	header.Add("Vary", "Accept-Encoding")
+	header.Set("ETag", etag(info))
+	if typ := mime.TypeByExtension(filepath.Ext(name)); typ != "" {
+		header.Set("Content-Type", typ)
+	}
+	switch {
+	case strings.HasSuffix(name, ".html"):
+		header.Set("Cache-Control", "no-cache")
+	case strings.Contains(filepath.ToSlash(name), "/assets/"):
+		// Vite puts content-hashed files in assets/
+		header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	default:
+		header.Set("Cache-Control", "public, max-age=0, must-revalidate")
+	}
+
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+func etag(info os.FileInfo) string {
+	return fmt.Sprintf(`"%x-%x"`, info.ModTime().UnixNano(), info.Size())
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows enc
+func acceptsEncoding(header, enc string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(name) != enc && strings.TrimSpace(name) != "*" {
+			continue
+		}
+		if q := strings.TrimSpace(params); q == "q=0" || q == "q=0.0" {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// Logger wraps a handler and reports every request to log
+func Logger(next http.Handler, log func(method, path string, status int, elapsed time.Duration)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log(r.Method, r.URL.RequestURI(), rec.status, time.Since(start))
+	})
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets streamed responses through the logger
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// serveLiveHTML serves a page with the live reload client injected. The page
+// is never cached so every reload picks up the latest build.
+func (h *staticHandler) serveLiveHTML(w http.ResponseWriter, r *http.Request, name string) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data = injectLiveScript(data)
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(data)
+}
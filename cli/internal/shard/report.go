@@ -0,0 +1,91 @@
+package shard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kluth/solidum-cli/internal/coverage"
+	"github.com/kluth/solidum-cli/internal/vitest"
+)
+
+// Dir holds the reports of the shards of a run, relative to the workspace
+// root. CI jobs upload it and the merging job downloads all of them into it.
+const Dir = ".solidum/shards"
+
+// Report is what one shard ran. Paths are relative to the workspace root,
+// which may differ between machines.
+type Report struct {
+	Shard    Spec                                 `json:"shard"`
+	Packages map[string]*vitest.Results           `json:"packages"`
+	Coverage map[string]map[string]*coverage.File `json:"coverage,omitempty"`
+
+	// Failures holds the error of each task, by ID, that failed without
+	// test results to show for it, e.g. a build or a test script that
+	// doesn't run vitest
+	Failures map[string]string `json:"failures,omitempty"`
+}
+
+// NewReport returns an empty report for a shard
+func NewReport(spec Spec) *Report {
+	return &Report{Shard: spec, Packages: make(map[string]*vitest.Results)}
+}
+
+// FileName is the name of a shard's report inside Dir
+func (s Spec) FileName() string {
+	return fmt.Sprintf("shard-%d-of-%d.json", s.Index, s.Total)
+}
+
+// Save writes the report into dir and returns its path
+func (r *Report) Save(dir string) (string, error) {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, r.Shard.FileName())
+	return path, os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// LoadReports reads every shard report in dir, ordered by shard
+func LoadReports(dir string) ([]*Report, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "shard-*-of-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var reports []*Report
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var r Report
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		reports = append(reports, &r)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Shard.Index < reports[j].Shard.Index
+	})
+	return reports, nil
+}
+
+// Missing returns the shards of a run of total shards that have no report
+func Missing(reports []*Report, total int) []Spec {
+	seen := make(map[int]bool)
+	for _, r := range reports {
+		seen[r.Shard.Index] = true
+	}
+	var missing []Spec
+	for i := 1; i <= total; i++ {
+		if !seen[i] {
+			missing = append(missing, Spec{Index: i, Total: total})
+		}
+	}
+	return missing
+}
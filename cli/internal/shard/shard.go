@@ -0,0 +1,101 @@
+package shard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec selects one shard of a split run, e.g. 2/4
+type Spec struct {
+	Index int `json:"index"`
+	Total int `json:"total"`
+}
+
+// ParseSpec reads a shard given as index/total, counting from 1
+func ParseSpec(s string) (Spec, error) {
+	index, total, ok := strings.Cut(s, "/")
+	i, err1 := strconv.Atoi(strings.TrimSpace(index))
+	n, err2 := strconv.Atoi(strings.TrimSpace(total))
+	if !ok || err1 != nil || err2 != nil || n < 1 || i < 1 || i > n {
+		return Spec{}, fmt.Errorf("invalid shard %q, expected i/n with 1 <= i <= n", s)
+	}
+	return Spec{Index: i, Total: n}, nil
+}
+
+func (s Spec) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Total)
+}
+
+// Item is a unit of work to assign: a test file, or a whole package when
+// File is empty
+type Item struct {
+	Package  string
+	File     string
+	Duration time.Duration
+}
+
+func (it Item) key() string {
+	return it.Package + "\x00" + it.File
+}
+
+// Split assigns items to n shards, balancing their estimated durations.
+// The longest items go first, each to the shard with the least work so far.
+// Ties are broken by package, file and shard index, so every machine
+// computes the same split from the same timings.
+func Split(items []Item, n int) [][]Item {
+	sorted := append([]Item(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Duration != sorted[j].Duration {
+			return sorted[i].Duration > sorted[j].Duration
+		}
+		return sorted[i].key() < sorted[j].key()
+	})
+
+	shards := make([][]Item, n)
+	load := make([]time.Duration, n)
+	for _, it := range sorted {
+		least := 0
+		for i := 1; i < n; i++ {
+			if load[i] < load[least] {
+				least = i
+			}
+		}
+		shards[least] = append(shards[least], it)
+		load[least] += it.Duration
+	}
+	return shards
+}
+
+// testFile matches vitest's default include pattern
+var testFile = regexp.MustCompile(`\.(test|spec)\.[cm]?[jt]sx?$`)
+
+// FindTests lists the test files of the package in dir, relative to dir
+// with forward slashes
+func FindTests(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != dir && (name == "node_modules" || name == "dist" || name == "coverage" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if testFile.MatchString(info.Name()) {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
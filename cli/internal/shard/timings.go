@@ -0,0 +1,116 @@
+package shard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kluth/solidum-cli/internal/vitest"
+)
+
+// TimingsFile keeps how long each test file and package took, relative to
+// the workspace root
+const TimingsFile = ".solidum/test-timings.json"
+
+// defaultDuration is assumed when nothing has been timed yet
+const defaultDuration = time.Second
+
+// Timings are the durations of the last runs in milliseconds, by
+// workspace-relative test file and by package name
+type Timings struct {
+	Files    map[string]float64 `json:"files"`
+	Packages map[string]float64 `json:"packages"`
+}
+
+// LoadTimings reads the timings file; a missing one has no timings
+func LoadTimings(path string) (*Timings, error) {
+	t := &Timings{Files: make(map[string]float64), Packages: make(map[string]float64)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if t.Files == nil {
+		t.Files = make(map[string]float64)
+	}
+	if t.Packages == nil {
+		t.Packages = make(map[string]float64)
+	}
+	return t, nil
+}
+
+// Record updates the timings with the results of the package in dir, both
+// relative to the workspace root. rel maps test files to workspace-relative
+// paths. The package total sums all its timed files, since a shard may have
+// run only some of them.
+func (t *Timings) Record(pkg, dir string, res *vitest.Results, rel func(string) string) {
+	for _, f := range res.Files {
+		t.Files[rel(f.Name)] = ms(f.Duration)
+	}
+	prefix := strings.TrimPrefix(path.Clean(dir)+"/", "./")
+	var total float64
+	for file, d := range t.Files {
+		if strings.HasPrefix(file, prefix) {
+			total += d
+		}
+	}
+	t.Packages[pkg] = total
+}
+
+// Save writes the timings file
+func (t *Timings) Save(path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// File returns the last duration of a workspace-relative test file, or the
+// average of all timed files when it has none
+func (t *Timings) File(path string) time.Duration {
+	if d, ok := t.Files[path]; ok {
+		return millis(d)
+	}
+	return average(t.Files)
+}
+
+// Package returns the last duration of a package, or the average of all
+// timed packages when it has none
+func (t *Timings) Package(name string) time.Duration {
+	if d, ok := t.Packages[name]; ok {
+		return millis(d)
+	}
+	return average(t.Packages)
+}
+
+func average(m map[string]float64) time.Duration {
+	if len(m) == 0 {
+		return defaultDuration
+	}
+	var sum float64
+	for _, d := range m {
+		sum += d
+	}
+	return millis(sum / float64(len(m)))
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func millis(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
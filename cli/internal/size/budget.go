@@ -0,0 +1,70 @@
+package size
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Compression names the size a budget applies to
+const (
+	Raw    = "raw"
+	Gzip   = "gzip"
+	Brotli = "brotli"
+)
+
+// Budget is a size limit checked against a report
+type Budget struct {
+	// Key is a package name or an entry file ("@sldm/core/dist/index.js")
+	Key    string
+	Limit  int64
+	Actual int64
+
+	// Missing is set when nothing in the report matches Key
+	Missing bool
+}
+
+// Exceeded reports whether the budget is blown
+func (b Budget) Exceeded() bool {
+	return !b.Missing && b.Actual > b.Limit
+}
+
+// Of returns the size of b that budgets with the given compression apply to
+func Of(b *Bundle, compression string) int64 {
+	switch compression {
+	case Raw:
+		return b.Size
+	case Brotli:
+		return b.BrotliSize
+	default:
+		return b.GzipSize
+	}
+}
+
+// CheckBudgets evaluates budgets, sorted by key
+func CheckBudgets(r *Report, budgets map[string]string, compression string) ([]Budget, error) {
+	switch compression {
+	case "", Raw, Gzip, Brotli:
+	default:
+		return nil, fmt.Errorf("unknown compression %q, expected raw, gzip or brotli", compression)
+	}
+
+	var out []Budget
+	for key, limit := range budgets {
+		n, err := ParseBytes(limit)
+		if err != nil {
+			return nil, fmt.Errorf("budget for %s: %w", key, err)
+		}
+
+		b := Budget{Key: key, Limit: n}
+		if entry := r.Lookup(key); entry != nil {
+			b.Actual = Of(entry, compression)
+		} else if pkg := r.Package(key); pkg.Modules > 0 {
+			b.Actual = Of(pkg, compression)
+		} else {
+			b.Missing = true
+		}
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out, nil
+}
@@ -0,0 +1,349 @@
+package size
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+const (
+	// ReportFile is where the latest measurement is written
+	ReportFile = ".solidum/size.json"
+
+	// BaselineFile is the measurement sizes are compared against
+	BaselineFile = ".solidum/size-baseline.json"
+)
+
+// Bundle is the size of one entry file with everything it imports from its
+// own package. It matches BundleSize in @sldm/dev-reports.
+type Bundle struct {
+	File       string `json:"file"`
+	Size       int64  `json:"size"`
+	GzipSize   int64  `json:"gzipSize"`
+	BrotliSize int64  `json:"brotliSize"`
+
+	// Package and Modules are extras: the owning package and the number of
+	// files counted
+	Package string `json:"package"`
+	Modules int    `json:"modules"`
+}
+
+// Comparison matches BundleComparison in @sldm/dev-reports
+type Comparison struct {
+	PreviousSize     int64   `json:"previousSize"`
+	CurrentSize      int64   `json:"currentSize"`
+	Difference       int64   `json:"difference"`
+	PercentageChange float64 `json:"percentageChange"`
+}
+
+// Report matches BundleReport in @sldm/dev-reports
+type Report struct {
+	Name            string      `json:"name"`
+	TotalSize       int64       `json:"totalSize"`
+	TotalGzipSize   int64       `json:"totalGzipSize"`
+	TotalBrotliSize int64       `json:"totalBrotliSize"`
+	Bundles         []*Bundle   `json:"bundles"`
+	Timestamp       int64       `json:"timestamp"`
+	Comparison      *Comparison `json:"comparison,omitempty"`
+}
+
+// Lookup returns the bundle for file, or nil
+func (r *Report) Lookup(file string) *Bundle {
+	for _, b := range r.Bundles {
+		if b.File == file {
+			return b
+		}
+	}
+	return nil
+}
+
+// Package returns the summed sizes of a package's bundles
+func (r *Report) Package(name string) *Bundle {
+	total := &Bundle{File: name, Package: name}
+	for _, b := range r.Bundles {
+		if b.Package == name {
+			total.Size += b.Size
+			total.GzipSize += b.GzipSize
+			total.BrotliSize += b.BrotliSize
+			total.Modules += b.Modules
+		}
+	}
+	return total
+}
+
+// Compare fills in the comparison with a previous report
+func (r *Report) Compare(previous *Report) {
+	diff := r.TotalSize - previous.TotalSize
+	c := &Comparison{PreviousSize: previous.TotalSize, CurrentSize: r.TotalSize, Difference: diff}
+	if previous.TotalSize > 0 {
+		c.PercentageChange = round2(float64(diff) / float64(previous.TotalSize) * 100)
+	}
+	r.Comparison = c
+}
+
+func round2(f float64) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'f', 2, 64), 64)
+	return v
+}
+
+// Measure sizes the entry files of each package's build output
+func Measure(root, name string, pkgs []*workspace.Package) (*Report, error) {
+	report := &Report{Name: name, Bundles: []*Bundle{}, Timestamp: time.Now().UnixMilli()}
+	for _, pkg := range pkgs {
+		dir := filepath.Join(root, pkg.Dir)
+		entries, err := Entries(dir, pkg)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			b, err := measureEntry(dir, entry)
+			if err != nil {
+				return nil, err
+			}
+			b.Package = pkg.Name
+			b.File = pkg.Name + "/" + entry
+			report.Bundles = append(report.Bundles, b)
+			report.TotalSize += b.Size
+			report.TotalGzipSize += b.GzipSize
+			report.TotalBrotliSize += b.BrotliSize
+		}
+	}
+	return report, nil
+}
+
+// Entries returns the built entry files of a package, relative to dir: the
+// JavaScript and CSS files named by main, module and exports, or every such
+// file directly in dist/ when package.json names none
+func Entries(dir string, pkg *workspace.Package) ([]string, error) {
+	var targets []string
+	targets = append(targets, pkg.Main, pkg.Module)
+	if len(pkg.Exports) > 0 {
+		var exports interface{}
+		if err := json.Unmarshal(pkg.Exports, &exports); err != nil {
+			return nil, fmt.Errorf("%s: invalid exports: %w", pkg.Name, err)
+		}
+		targets = append(targets, exportTargets(exports)...)
+	}
+
+	seen := make(map[string]bool)
+	var entries []string
+	for _, t := range targets {
+		rel := path.Clean(strings.TrimPrefix(t, "./"))
+		if t == "" || seen[rel] || !isAsset(rel) || strings.Contains(rel, "*") {
+			continue
+		}
+		seen[rel] = true
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(rel))); err == nil {
+			entries = append(entries, rel)
+		}
+	}
+	if len(seen) > 0 {
+		sort.Strings(entries)
+		return entries, nil
+	}
+
+	dist := filepath.Join(dir, "dist")
+	if _, err := os.Stat(dist); err != nil {
+		return nil, nil
+	}
+	err := filepath.WalkDir(dist, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && p != dist {
+			// Chunks are counted with the entries importing them
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !isAsset(p) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err == nil {
+			entries = append(entries, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	sort.Strings(entries)
+	return entries, err
+}
+
+// exportTargets collects the file paths of an exports field, skipping type
+// declarations
+func exportTargets(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			out = append(out, exportTargets(item)...)
+		}
+		return out
+	case map[string]interface{}:
+		var out []string
+		for key, item := range v {
+			if key == "types" {
+				continue
+			}
+			out = append(out, exportTargets(item)...)
+		}
+		return out
+	}
+	return nil
+}
+
+func isAsset(name string) bool {
+	switch path.Ext(name) {
+	case ".js", ".mjs", ".cjs", ".css":
+		return true
+	}
+	return false
+}
+
+// importPattern matches relative static imports, re-exports and dynamic
+// imports in built JavaScript
+var importPattern = regexp.MustCompile(`(?:\bfrom\s*|\bimport\s*\(?\s*)["'](\.{1,2}/[^"']+)["']`)
+
+// measureEntry sizes an entry file together with the files it imports from
+// its own package, as a bundler would ship them
+func measureEntry(dir, entry string) (*Bundle, error) {
+	var content bytes.Buffer
+	modules := 0
+
+	seen := make(map[string]bool)
+	queue := []string{filepath.Join(dir, filepath.FromSlash(entry))}
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		if seen[file] {
+			continue
+		}
+		seen[file] = true
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			if os.IsNotExist(err) && len(seen) > 1 {
+				// Unresolvable import, e.g. generated at runtime
+				continue
+			}
+			return nil, err
+		}
+		content.Write(data)
+		modules++
+
+		if filepath.Ext(file) == ".css" {
+			continue
+		}
+		for _, m := range importPattern.FindAllSubmatch(data, -1) {
+			queue = append(queue, filepath.Join(filepath.Dir(file), filepath.FromSlash(string(m[1]))))
+		}
+	}
+
+	gz, err := gzipSize(content.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	br, err := brotliSize(content.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return &Bundle{Size: int64(content.Len()), GzipSize: gz, BrotliSize: br, Modules: modules}, nil
+}
+
+func gzipSize(data []byte) (int64, error) {
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	return int64(buf.Len()), nil
+}
+
+func brotliSize(data []byte) (int64, error) {
+	var buf bytes.Buffer
+	w := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	if _, err := w.Write(data); err != nil {
+		return 0, err
+	}
+	if err := w.Close(); err != nil {
+		return 0, err
+	}
+	return int64(buf.Len()), nil
+}
+
+// Load reads a report written by Write
+func Load(file string) (*Report, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+	return &r, nil
+}
+
+// Write saves the report as indented JSON, creating parent directories
+func (r *Report) Write(file string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, append(data, '\n'), 0644)
+}
+
+// Format renders bytes like formatSize in @sldm/dev-reports
+func Format(n int64) string {
+	switch {
+	case n == 0:
+		return "0 B"
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.2f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%.2f MB", float64(n)/(1024*1024))
+	}
+}
+
+var bytesPattern = regexp.MustCompile(`^(?i)\s*([0-9]+(?:\.[0-9]+)?)\s*(b|kb|kib|mb|mib)?\s*$`)
+
+// ParseBytes parses "512", "512 B", "10 KB" or "1.5MB"; units are
+// multiples of 1024, as in Format
+func ParseBytes(s string) (int64, error) {
+	m := bytesPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %q, expected e.g. \"10 KB\"", s)
+	}
+	n, _ := strconv.ParseFloat(m[1], 64)
+	switch strings.ToLower(m[2]) {
+	case "kb", "kib":
+		n *= 1024
+	case "mb", "mib":
+		n *= 1024 * 1024
+	}
+	return int64(n), nil
+}
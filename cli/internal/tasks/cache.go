@@ -0,0 +1,164 @@
+package tasks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// CacheFile stores the input hashes of the last successful runs
+const CacheFile = ".solidum/cache/tasks.json"
+
+type taskCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+	updated map[string]string
+}
+
+// saveMu serializes writes from runs executing concurrently, e.g. the
+// parallel stages of `solidum ci`
+var saveMu sync.Mutex
+
+func loadCache(ws *workspace.Workspace) *taskCache {
+	c := &taskCache{entries: make(map[string]string), updated: make(map[string]string)}
+	if data, err := os.ReadFile(ws.Path(CacheFile)); err == nil {
+		json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+// key hashes everything that influences a node's result: the command, its
+// arguments and environment, and the content of every input file
+func (c *taskCache) key(ws *workspace.Workspace, n *Node, args []string) string {
+	h := sha256.New()
+	io.WriteString(h, n.Task+"\x00"+n.Config.Command+"\x00")
+	if script, ok := n.Package.Scripts[n.Task]; ok {
+		io.WriteString(h, script+"\x00")
+	}
+	io.WriteString(h, strings.Join(args, "\x00")+"\x00")
+
+	envKeys := make([]string, 0, len(n.Config.Env))
+	for k := range n.Config.Env {
+		envKeys = append(envKeys, k)
+	}
+	sort.Strings(envKeys)
+	for _, k := range envKeys {
+		io.WriteString(h, k+"="+n.Config.Env[k]+"\x00")
+	}
+
+	// Upstream results change our inputs, e.g. a rebuilt dependency's types
+	for _, dep := range n.Deps {
+		c.mu.Lock()
+		io.WriteString(h, dep.ID()+"="+c.entries[dep.ID()]+"\x00")
+		c.mu.Unlock()
+	}
+
+	dir := ws.Path(n.Package.Dir)
+	for _, file := range MatchFiles(dir, n.Config.Inputs, n.Config.Outputs) {
+		f, err := os.Open(filepath.Join(dir, file))
+		if err != nil {
+			continue
+		}
+		io.WriteString(h, file+"\x00")
+		io.Copy(h, f)
+		f.Close()
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hit reports whether the node already ran with the same key and its
+// declared outputs still exist
+func (c *taskCache) hit(ws *workspace.Workspace, n *Node, key string) bool {
+	c.mu.Lock()
+	prev := c.entries[n.ID()]
+	c.mu.Unlock()
+	if prev != key {
+		return false
+	}
+	if len(n.Config.Outputs) == 0 {
+		return true
+	}
+	return len(MatchFiles(ws.Path(n.Package.Dir), n.Config.Outputs, nil)) > 0
+}
+
+func (c *taskCache) store(n *Node, key string) {
+	c.mu.Lock()
+	c.entries[n.ID()] = key
+	c.updated[n.ID()] = key
+	c.mu.Unlock()
+}
+
+func (c *taskCache) save(ws *workspace.Workspace) {
+	if len(c.updated) == 0 {
+		return
+	}
+
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	// Merge into the file as written by other runs since we loaded it
+	path := ws.Path(CacheFile)
+	entries := make(map[string]string)
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &entries)
+	}
+	for id, key := range c.updated {
+		entries[id] = key
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	os.WriteFile(path, data, 0644)
+}
+
+// MatchFiles lists files below dir matching any include glob and no exclude
+// glob, as sorted slash-separated relative paths. No includes matches every
+// file. node_modules is never descended into.
+func MatchFiles(dir string, include, exclude []string) []string {
+	var files []string
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if info.Name() == "node_modules" || (path != dir && strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if matchAny(exclude, rel) {
+			return nil
+		}
+		if len(include) == 0 || matchAny(include, rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files
+}
+
+func matchAny(globs []string, path string) bool {
+	for _, g := range globs {
+		if workspace.MatchPath(g, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package tasks
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+var prefixColors = []*color.Color{
+	color.New(color.FgCyan),
+	color.New(color.FgMagenta),
+	color.New(color.FgYellow),
+	color.New(color.FgGreen),
+	color.New(color.FgBlue),
+	color.New(color.FgHiCyan),
+	color.New(color.FgHiMagenta),
+}
+
+// prefixWriter labels every line with the node it came from
+type prefixWriter struct {
+	out    io.Writer
+	prefix string
+	mu     *sync.Mutex
+	buf    []byte
+}
+
+// LabelColor picks a stable color for a label such as a task or package
+func LabelColor(label string) *color.Color {
+	sum := 0
+	for _, c := range label {
+		sum += int(c)
+	}
+	return prefixColors[sum%len(prefixColors)]
+}
+
+func newPrefixWriter(out io.Writer, label string, mu *sync.Mutex) *prefixWriter {
+	return &prefixWriter{out: out, prefix: LabelColor(label).Sprint(label) + " │ ", mu: mu}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.mu.Lock()
+		_, err := io.WriteString(w.out, w.prefix+string(w.buf[:i+1]))
+		w.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a trailing line that did not end in a newline
+func (w *prefixWriter) Flush() {
+	if len(w.buf) == 0 {
+		return
+	}
+	w.mu.Lock()
+	io.WriteString(w.out, w.prefix+string(w.buf)+"\n")
+	w.mu.Unlock()
+	w.buf = nil
+}
@@ -0,0 +1,168 @@
+package tasks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/config"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// Node is one task in one package
+type Node struct {
+	Task    string
+	Config  *config.Task
+	Package *workspace.Package
+
+	// Deps are the nodes that must finish before this one starts
+	Deps []*Node
+
+	// Requested is false for nodes pulled in only as dependencies
+	Requested bool
+}
+
+// ID identifies the node, e.g. "@sldm/core#build"
+func (n *Node) ID() string {
+	return n.Package.Name + "#" + n.Task
+}
+
+// HasWork reports whether the node runs anything. Packages without a script
+// of the task's name are kept in the graph only to preserve ordering.
+func (n *Node) HasWork() bool {
+	return n.Config.Command != "" || n.Package.HasScript(n.Task)
+}
+
+// Script returns the shell command the node runs: the configured command,
+// else the package script
+func (n *Node) Script() string {
+	if n.Config.Command != "" {
+		return n.Config.Command
+	}
+	return n.Package.Scripts[n.Task]
+}
+
+// Plan is the ordered set of nodes for a run
+type Plan struct {
+	Nodes []*Node
+}
+
+// Requested returns the nodes the user asked for
+func (p *Plan) Requested() []*Node {
+	var nodes []*Node
+	for _, n := range p.Nodes {
+		if n.Requested {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// NewPlan builds the task graph for the given tasks across the selected
+// packages, adding dependency nodes declared with dependsOn
+func NewPlan(ws *workspace.Workspace, cfg *config.Config, taskNames []string, pkgs []*workspace.Package) (*Plan, error) {
+	layers, err := ws.Layers(ws.Packages)
+	if err != nil {
+		return nil, err
+	}
+	layerOf := make(map[string]int)
+	for i, layer := range layers {
+		for _, pkg := range layer {
+			layerOf[pkg.Name] = i
+		}
+	}
+
+	p := &planner{
+		ws:      ws,
+		cfg:     cfg,
+		layerOf: layerOf,
+		nodes:   make(map[string]*Node),
+		visits:  make(map[string]bool),
+	}
+
+	for _, name := range taskNames {
+		task, ok := cfg.Tasks[name]
+		if !ok {
+			task = &config.Task{}
+		}
+
+		targets := pkgs
+		if task.Root {
+			targets = []*workspace.Package{p.rootPackage()}
+		}
+
+		for _, pkg := range targets {
+			node, err := p.add(name, pkg)
+			if err != nil {
+				return nil, err
+			}
+			node.Requested = true
+		}
+	}
+
+	return &Plan{Nodes: p.order}, nil
+}
+
+type planner struct {
+	ws      *workspace.Workspace
+	cfg     *config.Config
+	layerOf map[string]int
+	nodes   map[string]*Node
+	visits  map[string]bool
+	order   []*Node
+}
+
+func (p *planner) rootPackage() *workspace.Package {
+	if p.ws.RootPackage != nil {
+		return p.ws.RootPackage
+	}
+	return &workspace.Package{Name: "//", Dir: "."}
+}
+
+func (p *planner) add(taskName string, pkg *workspace.Package) (*Node, error) {
+	id := pkg.Name + "#" + taskName
+	if n, ok := p.nodes[id]; ok {
+		return n, nil
+	}
+	if p.visits[id] {
+		return nil, fmt.Errorf("task cycle through %s", id)
+	}
+	p.visits[id] = true
+
+	task, ok := p.cfg.Tasks[taskName]
+	if !ok {
+		task = &config.Task{}
+	}
+	node := &Node{Task: taskName, Config: task, Package: pkg}
+
+	for _, dep := range task.DependsOn {
+		if strings.HasPrefix(dep, "^") {
+			upstream := strings.TrimPrefix(dep, "^")
+			for _, name := range p.ws.InternalDependencies(pkg, true) {
+				depPkg, _ := p.ws.Lookup(name)
+				// Edges that point to the same or a later layer only exist
+				// through devDependency cycles and are skipped
+				if p.layerOf[name] >= p.layerOf[pkg.Name] {
+					continue
+				}
+				depNode, err := p.add(upstream, depPkg)
+				if err != nil {
+					return nil, err
+				}
+				node.Deps = append(node.Deps, depNode)
+			}
+			continue
+		}
+
+		depNode, err := p.add(dep, pkg)
+		if err != nil {
+			return nil, err
+		}
+		node.Deps = append(node.Deps, depNode)
+	}
+
+	sort.Slice(node.Deps, func(i, j int) bool { return node.Deps[i].ID() < node.Deps[j].ID() })
+	p.nodes[id] = node
+	p.order = append(p.order, node)
+	return node, nil
+}
@@ -0,0 +1,275 @@
+package tasks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kluth/solidum-cli/internal/proc"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// Status is the outcome of a node
+type Status string
+
+const (
+	Success Status = "success"
+	Failed  Status = "failed"
+	Cached  Status = "cached"
+	Skipped Status = "skipped"
+	Blocked Status = "blocked"
+)
+
+// Result is the outcome of running one node
+type Result struct {
+	Node     *Node
+	Status   Status
+	Duration time.Duration
+	Output   string
+	Err      error
+}
+
+// Summary collects the results of a run in plan order
+type Summary struct {
+	Results  []*Result
+	Duration time.Duration
+}
+
+// Failed returns the results of nodes that failed
+func (s *Summary) Failed() []*Result {
+	var failed []*Result
+	for _, r := range s.Results {
+		if r.Status == Failed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Count returns how many nodes ended with the given status
+func (s *Summary) Count(status Status) int {
+	n := 0
+	for _, r := range s.Results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Options control how a plan is executed
+type Options struct {
+	// Concurrency limits how many nodes run at once (default: CPU count)
+	Concurrency int
+
+	// Args are appended to the command of every requested node
+	Args []string
+
+	// NodeArgs are appended after Args for the requested node with that ID
+	NodeArgs map[string][]string
+
+	// Parallel starts every node at once, ignoring dependsOn. Used for
+	// long-running watch tasks that never finish.
+	Parallel bool
+
+	// NoCache runs cacheable tasks even when their inputs are unchanged
+	NoCache bool
+
+	// Stream forwards output as it is produced. Otherwise output is only
+	// kept in the results.
+	Stream bool
+
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Run executes the plan, starting each node once its dependencies finished
+func Run(ctx context.Context, ws *workspace.Workspace, plan *Plan, opts Options) *Summary {
+	start := time.Now()
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = runtime.NumCPU()
+	}
+	if opts.Parallel {
+		opts.Concurrency = len(plan.Nodes)
+	}
+	if opts.Stdout == nil {
+		opts.Stdout = os.Stdout
+	}
+	if opts.Stderr == nil {
+		opts.Stderr = os.Stderr
+	}
+
+	cache := loadCache(ws)
+	results := make(map[*Node]*Result)
+	done := make(map[*Node]chan struct{})
+	for _, n := range plan.Nodes {
+		done[n] = make(chan struct{})
+	}
+
+	// A single node with work gets the terminal to itself
+	working := 0
+	for _, n := range plan.Nodes {
+		if n.HasWork() {
+			working++
+		}
+	}
+	prefixed := working > 1
+
+	var mu sync.Mutex
+	var outputMu sync.Mutex
+	sem := make(chan struct{}, opts.Concurrency)
+	var wg sync.WaitGroup
+
+	for _, n := range plan.Nodes {
+		wg.Add(1)
+		go func(n *Node) {
+			defer wg.Done()
+			defer close(done[n])
+
+			result := &Result{Node: n}
+			defer func() {
+				mu.Lock()
+				results[n] = result
+				mu.Unlock()
+			}()
+
+			if !opts.Parallel {
+				for _, dep := range n.Deps {
+					<-done[dep]
+					mu.Lock()
+					depResult := results[dep]
+					mu.Unlock()
+					if depResult.Status == Failed || depResult.Status == Blocked {
+						result.Status = Blocked
+						result.Err = fmt.Errorf("%s did not complete", dep.ID())
+						return
+					}
+				}
+			}
+
+			if !n.HasWork() {
+				result.Status = Skipped
+				return
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				result.Status = Blocked
+				result.Err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			var args []string
+			if n.Requested {
+				args = append(append([]string(nil), opts.Args...), opts.NodeArgs[n.ID()]...)
+			}
+
+			key := ""
+			if n.Config.Cacheable && !opts.NoCache {
+				key = cache.key(ws, n, args)
+				if cache.hit(ws, n, key) {
+					result.Status = Cached
+					return
+				}
+			}
+
+			var buf bytes.Buffer
+			var stdout, stderr io.Writer = &buf, &buf
+			var flushers []*prefixWriter
+			if opts.Stream {
+				out, errOut := opts.Stdout, opts.Stderr
+				if prefixed {
+					pw, pwErr := newPrefixWriter(opts.Stdout, n.ID(), &outputMu), newPrefixWriter(opts.Stderr, n.ID(), &outputMu)
+					flushers = append(flushers, pw, pwErr)
+					out, errOut = pw, pwErr
+				}
+				stdout = io.MultiWriter(&buf, out)
+				stderr = io.MultiWriter(&buf, errOut)
+			}
+
+			began := time.Now()
+			err := runNode(ctx, ws, n, args, stdout, stderr, !prefixed && opts.Stream)
+			result.Duration = time.Since(began)
+			for _, w := range flushers {
+				w.Flush()
+			}
+			result.Output = buf.String()
+
+			if err != nil {
+				result.Status = Failed
+				result.Err = err
+				return
+			}
+			result.Status = Success
+			if key != "" {
+				cache.store(n, key)
+			}
+		}(n)
+	}
+
+	wg.Wait()
+	cache.save(ws)
+
+	summary := &Summary{Duration: time.Since(start)}
+	for _, n := range plan.Nodes {
+		summary.Results = append(summary.Results, results[n])
+	}
+	return summary
+}
+
+// Command builds the process for a node: the configured shell command, or
+// `pnpm run <task>` for the package script
+func Command(ws *workspace.Workspace, n *Node, args []string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if n.Config.Command != "" {
+		if runtime.GOOS == "windows" {
+			line := n.Config.Command
+			for _, arg := range args {
+				line += " " + quoteWindows(arg)
+			}
+			cmd = exec.Command("cmd", "/C", line)
+		} else {
+			// Extra args are passed as positional parameters so the shell
+			// never splits or interprets them
+			cmd = exec.Command("sh", append([]string{"-c", n.Config.Command + ` "$@"`, "sh"}, args...)...)
+		}
+	} else {
+		cmd = exec.Command("pnpm", append([]string{"run", n.Task}, args...)...)
+	}
+
+	cmd.Dir = ws.Path(n.Package.Dir)
+	cmd.Env = os.Environ()
+	for k, v := range n.Config.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	return cmd
+}
+
+// quoteWindows quotes an argument for cmd /C, keeping spaces and shell
+// metacharacters literal
+func quoteWindows(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"&|<>^()%!") {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+}
+
+func runNode(ctx context.Context, ws *workspace.Workspace, n *Node, args []string, stdout, stderr io.Writer, interactive bool) error {
+	cmd := Command(ws, n, args)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if interactive {
+		cmd.Stdin = os.Stdin
+	}
+	return proc.Run(ctx, n.ID(), cmd)
+}
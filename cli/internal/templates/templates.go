@@ -232,9 +232,10 @@ export default defineConfig({
     },
   },
   server: {
-    port: 3000,
-    strictPort: false,
-    open: true,
+    // solidum dev assigns each package a stable port through PORT
+    port: Number(process.env.PORT) || 3000,
+    strictPort: Boolean(process.env.PORT),
+    open: !process.env.PORT,
   },
   optimizeDeps: {
     include: ['@sldm/core'],
@@ -0,0 +1,253 @@
+package tsproject
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/jsonc"
+	"github.com/kluth/solidum-cli/internal/workspace"
+)
+
+// ConfigFile is the tsconfig of each package
+const ConfigFile = "tsconfig.json"
+
+// SolutionFile references the projects a `tsc -b` run builds, relative to
+// the workspace root
+const SolutionFile = ".solidum/tsconfig.build.json"
+
+// config holds the parts of a tsconfig that matter for references
+type config struct {
+	CompilerOptions struct {
+		Composite   *bool `json:"composite"`
+		Incremental *bool `json:"incremental"`
+	} `json:"compilerOptions"`
+	References []struct {
+		Path string `json:"path"`
+	} `json:"references"`
+}
+
+// Project is a package with a tsconfig.json
+type Project struct {
+	Package *workspace.Package
+	Path    string
+
+	// Want are the references the workspace graph asks for, Have the ones
+	// to other packages the tsconfig has, both relative to the package
+	Want []string
+	Have []string
+
+	// Other references, e.g. to tsconfig.node.json, are kept as they are
+	Other []string
+
+	Composite   bool
+	Incremental bool
+
+	data []byte
+}
+
+// Load reads the tsconfig of every package that has one and derives the
+// references each should have from its internal dependencies
+func Load(ws *workspace.Workspace) ([]*Project, error) {
+	byDir := make(map[string]*Project)
+	var projects []*Project
+	for _, pkg := range ws.Packages {
+		p := &Project{Package: pkg, Path: ws.Path(pkg.Dir, ConfigFile)}
+		data, err := os.ReadFile(p.Path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var cfg config
+		if err := json.Unmarshal(jsonc.Strip(data), &cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", ws.Rel(p.Path), err)
+		}
+		p.data = data
+		p.Composite = cfg.CompilerOptions.Composite != nil && *cfg.CompilerOptions.Composite
+		p.Incremental = p.Composite || cfg.CompilerOptions.Incremental != nil && *cfg.CompilerOptions.Incremental
+		for _, ref := range cfg.References {
+			p.Other = append(p.Other, ref.Path)
+		}
+		projects = append(projects, p)
+		byDir[filepath.Clean(ws.Path(pkg.Dir))] = p
+	}
+
+	byName := make(map[string]*Project)
+	for _, p := range projects {
+		byName[p.Package.Name] = p
+	}
+	edges := ws.DependencyEdges()
+	for _, p := range projects {
+		dir := ws.Path(p.Package.Dir)
+
+		// Split the existing references into packages and anything else
+		var other []string
+		for _, ref := range p.Other {
+			target := filepath.Join(dir, filepath.FromSlash(ref))
+			if filepath.Base(target) == ConfigFile {
+				target = filepath.Dir(target)
+			}
+			if q, ok := byDir[filepath.Clean(target)]; ok && q != p {
+				p.Have = append(p.Have, relRef(dir, ws.Path(q.Package.Dir)))
+			} else {
+				other = append(other, ref)
+			}
+		}
+		p.Other = other
+
+		for _, dep := range edges[p.Package.Name] {
+			if q, ok := byName[dep]; ok {
+				p.Want = append(p.Want, relRef(dir, ws.Path(q.Package.Dir)))
+			}
+		}
+		sort.Strings(p.Want)
+	}
+	return projects, nil
+}
+
+// relRef is the reference from one package directory to another
+func relRef(from, to string) string {
+	rel, err := filepath.Rel(from, to)
+	if err != nil {
+		return filepath.ToSlash(to)
+	}
+	return path.Clean(filepath.ToSlash(rel))
+}
+
+// Missing returns the wanted references the tsconfig lacks
+func (p *Project) Missing() []string {
+	return minus(p.Want, p.Have)
+}
+
+// Stale returns the references to packages that are no dependency
+func (p *Project) Stale() []string {
+	return minus(p.Have, p.Want)
+}
+
+func minus(a, b []string) []string {
+	set := make(map[string]bool)
+	for _, s := range b {
+		set[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !set[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Synced returns the tsconfig with its package references replaced by the
+// wanted ones, keeping comments, formatting and other references. changed
+// is false when they already match.
+func (p *Project) Synced() (data []byte, changed bool, err error) {
+	if len(p.Missing()) == 0 && len(p.Stale()) == 0 {
+		return p.data, false, nil
+	}
+	refs := append(append([]string(nil), p.Other...), p.Want...)
+	data, err = jsonc.Set(p.data, []string{"references"}, func(indent string) string {
+		if len(refs) == 0 {
+			return "[]"
+		}
+		var b strings.Builder
+		b.WriteString("[\n")
+		for i, ref := range refs {
+			fmt.Fprintf(&b, "%s%s{ \"path\": %q }", indent, indent, ref)
+			if i < len(refs)-1 {
+				b.WriteString(",")
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + "]")
+		return b.String()
+	})
+	return data, err == nil, err
+}
+
+// Sync writes the wanted references into the tsconfig and reports whether
+// it changed
+func (p *Project) Sync() (bool, error) {
+	data, changed, err := p.Synced()
+	if err != nil || !changed {
+		return false, err
+	}
+	if err := os.WriteFile(p.Path, data, 0644); err != nil {
+		return false, err
+	}
+	p.data = data
+	p.Have = append([]string(nil), p.Want...)
+	return true, nil
+}
+
+// Original returns the tsconfig as read
+func (p *Project) Original() []byte {
+	return p.data
+}
+
+// Problem is a tsconfig that doesn't fit a `tsc -b` build
+type Problem struct {
+	Project *Project
+	Message string
+}
+
+// Check lists what keeps the projects from building with `tsc -b`: missing
+// or stale references, referenced projects that are not composite and
+// projects without incremental builds
+func Check(projects []*Project) []Problem {
+	var problems []Problem
+	referenced := make(map[string][]string)
+	for _, p := range projects {
+		for _, ref := range p.Missing() {
+			problems = append(problems, Problem{Project: p, Message: fmt.Sprintf("missing reference to %s", ref)})
+		}
+		for _, ref := range p.Stale() {
+			problems = append(problems, Problem{Project: p, Message: fmt.Sprintf("references %s, which is not a dependency", ref)})
+		}
+		for _, ref := range p.Want {
+			dir := path.Join(filepath.ToSlash(p.Package.Dir), ref)
+			referenced[dir] = append(referenced[dir], p.Package.Name)
+		}
+	}
+	for _, p := range projects {
+		by := referenced[path.Clean(filepath.ToSlash(p.Package.Dir))]
+		switch {
+		case len(by) > 0 && !p.Composite:
+			problems = append(problems, Problem{Project: p, Message: fmt.Sprintf("is referenced by %s but does not set compilerOptions.composite", strings.Join(by, ", "))})
+		case !p.Incremental:
+			problems = append(problems, Problem{Project: p, Message: "sets neither compilerOptions.composite nor incremental, so it has no .tsbuildinfo and is checked from scratch every time"})
+		}
+	}
+	return problems
+}
+
+// WriteSolution writes a tsconfig that only references projects, for
+// `tsc -b` to build them and everything they reference
+func WriteSolution(path string, projects []*Project) error {
+	type ref struct {
+		Path string `json:"path"`
+	}
+	solution := struct {
+		Files      []string `json:"files"`
+		References []ref    `json:"references"`
+	}{Files: []string{}, References: []ref{}}
+	for _, p := range projects {
+		rel := relRef(filepath.Dir(path), filepath.Dir(p.Path))
+		solution.References = append(solution.References, ref{Path: rel})
+	}
+
+	data, err := json.MarshalIndent(solution, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,85 @@
+package vitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// FlakyFile records tests that passed only on retry, relative to the
+// workspace root
+const FlakyFile = ".solidum/flaky.json"
+
+// Flaky is a test that failed and then passed on retry
+type Flaky struct {
+	Package string `json:"package"`
+
+	// File is the test file relative to the workspace root
+	File  string `json:"file"`
+	Test  string `json:"test"`
+	Count int    `json:"count"`
+
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+type flakyLog struct {
+	Tests []*Flaky `json:"tests"`
+}
+
+// LoadFlaky reads the flaky test log; a missing file is an empty log
+func LoadFlaky(path string) ([]*Flaky, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var log flakyLog
+	if err := json.Unmarshal(data, &log); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return log.Tests, nil
+}
+
+// RecordFlaky adds tests seen flaking now to the log at path, counting
+// repeat offenders
+func RecordFlaky(path string, seen []*Flaky) error {
+	tests, err := LoadFlaky(path)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, s := range seen {
+		var entry *Flaky
+		for _, t := range tests {
+			if t.Package == s.Package && t.File == s.File && t.Test == s.Test {
+				entry = t
+				break
+			}
+		}
+		if entry == nil {
+			entry = &Flaky{Package: s.Package, File: s.File, Test: s.Test, FirstSeen: now}
+			tests = append(tests, entry)
+		}
+		entry.Count++
+		entry.LastSeen = now
+	}
+
+	sort.Slice(tests, func(i, j int) bool {
+		a, b := tests[i], tests[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Test < b.Test
+	})
+
+	return writeJSON(path, flakyLog{Tests: tests})
+}
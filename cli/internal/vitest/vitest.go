@@ -0,0 +1,310 @@
+package vitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ResultsFile is where a package's JSON test results are written, relative
+// to the package directory
+const ResultsFile = ".solidum/test-results.json"
+
+// Status of a test
+type Status string
+
+const (
+	Passed  Status = "passed"
+	Failed  Status = "failed"
+	Skipped Status = "skipped"
+)
+
+// Test is one test case
+type Test struct {
+	// File is the test file, Suite the describe blocks joined with " > "
+	File     string
+	Suite    string
+	Name     string
+	Duration time.Duration
+	Status   Status
+
+	// Error is the failure message and stack, empty unless Failed
+	Error string
+}
+
+// FullName returns the suite and test name
+func (t *Test) FullName() string {
+	if t.Suite == "" {
+		return t.Name
+	}
+	return t.Suite + " > " + t.Name
+}
+
+// File is the outcome of one test file
+type File struct {
+	Name     string
+	Duration time.Duration
+
+	// Error is set when the file failed outside of any test, e.g. it
+	// doesn't compile
+	Error string
+	Tests []*Test
+}
+
+// Failed reports whether the file or any of its tests failed
+func (f *File) Failed() bool {
+	if f.Error != "" {
+		return true
+	}
+	for _, t := range f.Tests {
+		if t.Status == Failed {
+			return true
+		}
+	}
+	return false
+}
+
+// Results are the test results of one run
+type Results struct {
+	Files []*File
+}
+
+// Counts totals the tests by status
+func (r *Results) Counts() (passed, failed, skipped int) {
+	for _, f := range r.Files {
+		for _, t := range f.Tests {
+			switch t.Status {
+			case Passed:
+				passed++
+			case Failed:
+				failed++
+			default:
+				skipped++
+			}
+		}
+	}
+	return passed, failed, skipped
+}
+
+// Duration sums the file durations
+func (r *Results) Duration() time.Duration {
+	var d time.Duration
+	for _, f := range r.Files {
+		d += f.Duration
+	}
+	return d
+}
+
+// Failed returns the failed files
+func (r *Results) Failed() []*File {
+	var failed []*File
+	for _, f := range r.Files {
+		if f.Failed() {
+			failed = append(failed, f)
+		}
+	}
+	return failed
+}
+
+// report is the JSON reporter's output, which follows Jest's format
+type report struct {
+	TestResults []testResult `json:"testResults"`
+}
+
+type testResult struct {
+	Name             string            `json:"name"`
+	Status           string            `json:"status"`
+	Message          string            `json:"message"`
+	StartTime        float64           `json:"startTime"`
+	EndTime          float64           `json:"endTime"`
+	AssertionResults []assertionResult `json:"assertionResults"`
+}
+
+type assertionResult struct {
+	AncestorTitles  []string `json:"ancestorTitles"`
+	Title           string   `json:"title"`
+	Status          string   `json:"status"`
+	Duration        *float64 `json:"duration"`
+	FailureMessages []string `json:"failureMessages"`
+}
+
+// Parse reads the output of vitest's JSON reporter
+func Parse(data []byte) (*Results, error) {
+	var raw report
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	results := &Results{}
+	for _, tr := range raw.TestResults {
+		f := &File{Name: tr.Name, Duration: millis(tr.EndTime - tr.StartTime)}
+		for _, ar := range tr.AssertionResults {
+			t := &Test{
+				File:   tr.Name,
+				Suite:  strings.Join(ar.AncestorTitles, " > "),
+				Name:   ar.Title,
+				Status: status(ar.Status),
+				Error:  strings.Join(ar.FailureMessages, "\n"),
+			}
+			if ar.Duration != nil {
+				t.Duration = millis(*ar.Duration)
+			}
+			f.Tests = append(f.Tests, t)
+		}
+		if tr.Status == "failed" && !f.Failed() {
+			f.Error = strings.TrimSpace(tr.Message)
+			if f.Error == "" {
+				f.Error = "test file failed"
+			}
+		}
+		results.Files = append(results.Files, f)
+	}
+	return results, nil
+}
+
+// Write saves the results in the JSON reporter's format, so a run merged
+// from retries reads back like a single run
+func (r *Results) Write(path string) error {
+	return writeJSON(path, r)
+}
+
+// MarshalJSON encodes the results in the JSON reporter's format
+func (r *Results) MarshalJSON() ([]byte, error) {
+	raw := report{TestResults: []testResult{}}
+	for _, f := range r.Files {
+		tr := testResult{Name: f.Name, Status: "passed", Message: f.Error, EndTime: float64(f.Duration) / float64(time.Millisecond)}
+		if f.Failed() {
+			tr.Status = "failed"
+		}
+		for _, t := range f.Tests {
+			ar := assertionResult{Title: t.Name, Status: string(t.Status), AncestorTitles: []string{}, FailureMessages: []string{}}
+			if t.Suite != "" {
+				ar.AncestorTitles = strings.Split(t.Suite, " > ")
+			}
+			if t.Error != "" {
+				ar.FailureMessages = []string{t.Error}
+			}
+			d := float64(t.Duration) / float64(time.Millisecond)
+			ar.Duration = &d
+			tr.AssertionResults = append(tr.AssertionResults, ar)
+		}
+		raw.TestResults = append(raw.TestResults, tr)
+	}
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON decodes the JSON reporter's format
+func (r *Results) UnmarshalJSON(data []byte) error {
+	parsed, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	*r = *parsed
+	return nil
+}
+
+// Rebase maps the path of every test file through fn, e.g. to make them
+// relative to the workspace root
+func (r *Results) Rebase(fn func(string) string) {
+	for _, f := range r.Files {
+		f.Name = fn(f.Name)
+		for _, t := range f.Tests {
+			t.File = fn(t.File)
+		}
+	}
+}
+
+// Load reads a JSON results file
+func Load(path string) (*Results, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	results, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return results, nil
+}
+
+func status(s string) Status {
+	switch s {
+	case "passed":
+		return Passed
+	case "failed":
+		return Failed
+	default:
+		// pending, todo, skipped, disabled
+		return Skipped
+	}
+}
+
+func millis(ms float64) time.Duration {
+	if ms < 0 {
+		return 0
+	}
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// ReporterArgs makes vitest report to the console as usual and also write
+// JSON results to file
+func ReporterArgs(console, file string) []string {
+	return []string{"--reporter=" + console, "--reporter=json", "--outputFile.json=" + file}
+}
+
+var vitestCommand = regexp.MustCompile(`(^|[\s;&|/])vitest(\s|$)`)
+
+// Runs reports whether a script runs vitest, so it takes vitest's options
+// and can write JSON results
+func Runs(script string) bool {
+	return vitestCommand.MatchString(script)
+}
+
+// Replace swaps in the files of a rerun, returning the tests that failed
+// before and passed in the rerun
+func (r *Results) Replace(rerun *Results) (flaky []*Test) {
+	for _, nf := range rerun.Files {
+		for i, f := range r.Files {
+			if f.Name != nf.Name {
+				continue
+			}
+			for _, t := range nf.Tests {
+				if t.Status == Passed && f.failed(t.FullName()) {
+					flaky = append(flaky, t)
+				}
+			}
+			r.Files[i] = nf
+		}
+	}
+	return flaky
+}
+
+func (f *File) failed(name string) bool {
+	for _, t := range f.Tests {
+		if t.FullName() == name {
+			return t.Status == Failed
+		}
+	}
+	return false
+}
+
+// writeJSON writes v as indented JSON without escaping the ">" that joins
+// suite and test names
+func writeJSON(path string, v any) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
@@ -0,0 +1,107 @@
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultInterval is how often the tree is scanned
+const DefaultInterval = 300 * time.Millisecond
+
+// Watcher polls a directory tree for changes. Polling avoids platform file
+// notification limits and works the same on network drives and in containers.
+type Watcher struct {
+	Root     string
+	Interval time.Duration
+
+	// Skip, when set, leaves out files and directories by path relative to
+	// Root (slash separated)
+	Skip func(rel string, dir bool) bool
+}
+
+type stamp struct {
+	mod  time.Time
+	size int64
+}
+
+// Run calls onChange with the relative paths of files that were created,
+// modified or removed since the previous scan, until ctx is done
+func (w *Watcher) Run(ctx context.Context, onChange func(changed []string)) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+
+	prev, err := w.scan()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		next, err := w.scan()
+		if err != nil {
+			continue
+		}
+
+		var changed []string
+		for rel, s := range next {
+			if old, ok := prev[rel]; !ok || old != s {
+				changed = append(changed, rel)
+			}
+		}
+		for rel := range prev {
+			if _, ok := next[rel]; !ok {
+				changed = append(changed, rel)
+			}
+		}
+		prev = next
+
+		if len(changed) > 0 {
+			sort.Strings(changed)
+			onChange(changed)
+		}
+	}
+}
+
+func (w *Watcher) scan() (map[string]stamp, error) {
+	files := make(map[string]stamp)
+	err := filepath.WalkDir(w.Root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Files can disappear between listing and stat
+			return nil
+		}
+		rel, _ := filepath.Rel(w.Root, path)
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if w.Skip != nil && w.Skip(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		files[rel] = stamp{mod: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return files, err
+}
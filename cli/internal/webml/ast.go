@@ -0,0 +1,147 @@
+package webml
+
+import "fmt"
+
+// Pos is a 1-based line and column in the template
+type Pos struct {
+	Line int
+	Col  int
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Col)
+}
+
+// Context is where in the HTML a piece of the template sits
+type Context int
+
+const (
+	// ContextText is element content
+	ContextText Context = iota
+	// ContextTag is inside a start tag, between attributes
+	ContextTag
+	// ContextAttr is inside a quoted attribute value
+	ContextAttr
+	// ContextComment is inside an HTML comment
+	ContextComment
+)
+
+func (c Context) String() string {
+	switch c {
+	case ContextTag:
+		return "tag"
+	case ContextAttr:
+		return "attribute value"
+	case ContextComment:
+		return "comment"
+	default:
+		return "text"
+	}
+}
+
+// Node is an element of the template AST
+type Node interface {
+	Position() Pos
+}
+
+// Template is a parsed .webml file
+type Template struct {
+	Nodes []Node
+}
+
+// Text is literal markup
+type Text struct {
+	Pos   Pos
+	Value string
+}
+
+// Expr is a variable reference such as "classes", "item.name" or "@index"
+type Expr struct {
+	Pos Pos
+
+	// Path holds the dotted segments, e.g. ["item", "name"]
+	Path []string
+
+	// Context is where the value is interpolated
+	Context Context
+}
+
+// Root returns the first segment of the path
+func (e *Expr) Root() string {
+	return e.Path[0]
+}
+
+// String returns the path as written
+func (e *Expr) String() string {
+	s := e.Path[0]
+	for _, seg := range e.Path[1:] {
+		s += "." + seg
+	}
+	return s
+}
+
+// Interpolation inserts the value of an expression
+type Interpolation struct {
+	Expr *Expr
+}
+
+// If renders Then when Cond is truthy, otherwise Else
+type If struct {
+	Pos  Pos
+	Cond *Expr
+	Then []Node
+	Else []Node
+
+	// ElsePos is the position of {{else}}, zero when there is none
+	ElsePos Pos
+}
+
+// Each renders Body once per element of List
+type Each struct {
+	Pos  Pos
+	List *Expr
+	Body []Node
+
+	// Item and Index name the loop variables; they default to "item" and
+	// "@index" as in Handlebars
+	Item  string
+	Index string
+}
+
+func (n *Text) Position() Pos          { return n.Pos }
+func (n *Interpolation) Position() Pos { return n.Expr.Pos }
+func (n *If) Position() Pos            { return n.Pos }
+func (n *Each) Position() Pos          { return n.Pos }
+
+// Walk calls fn for every node in depth-first order, with the loop
+// variables in scope at that node
+func Walk(nodes []Node, fn func(n Node, scope []*Each)) {
+	walk(nodes, nil, fn)
+}
+
+func walk(nodes []Node, scope []*Each, fn func(Node, []*Each)) {
+	for _, n := range nodes {
+		fn(n, scope)
+		switch n := n.(type) {
+		case *If:
+			walk(n.Then, scope, fn)
+			walk(n.Else, scope, fn)
+		case *Each:
+			walk(n.Body, append(scope[:len(scope):len(scope)], n), fn)
+		}
+	}
+}
+
+// Error is a syntax error with its position
+type Error struct {
+	File string
+	Pos  Pos
+	Msg  string
+}
+
+func (e *Error) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+	}
+	return fmt.Sprintf("%s:%s: %s", e.File, e.Pos, e.Msg)
+}
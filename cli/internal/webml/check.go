@@ -0,0 +1,324 @@
+package webml
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Severity of a diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Check rules
+const (
+	RuleSyntax     = "syntax-error"
+	RuleUnknown    = "unknown-variable"
+	RuleMisspelled = "misspelled-variable"
+	RuleUnused     = "unused-prop"
+	RuleNoProps    = "missing-props"
+)
+
+// Rules describes every rule, for reports that list them
+var Rules = map[string]string{
+	RuleSyntax:     "The template can't be parsed",
+	RuleUnknown:    "The template references a variable the props interface doesn't declare",
+	RuleMisspelled: "The template references a variable that is close to a declared prop",
+	RuleUnused:     "The props interface declares a prop the template never uses",
+	RuleNoProps:    "No props interface was found next to the template",
+}
+
+// Diagnostic is a problem found by Check
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Col      int      `json:"column"`
+	Severity Severity `json:"severity"`
+	Rule     string   `json:"rule"`
+	Message  string   `json:"message"`
+
+	// Suggestion is the likely intended name for misspellings
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Component returns the files that may declare a template's props, in the
+// order they're searched: Button.webml → Button.webml.ts, Button.ts
+func Component(file string) []string {
+	base := strings.TrimSuffix(file, Ext)
+	return []string{OutputPath(file), base + ".ts"}
+}
+
+// PropsNames returns the interface names that may declare a template's props,
+// in order: Button.webml → ButtonTemplateProps, ButtonProps
+func PropsNames(file string) []string {
+	name := strings.TrimSuffix(filepath.Base(file), Ext)
+	return []string{name + "TemplateProps", name + "Props"}
+}
+
+// Check validates the variables a template references against the props
+// interface of its component
+func Check(file string) ([]Diagnostic, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	t, err := Parse(file, string(src))
+	if err != nil {
+		if perr, ok := err.(*Error); ok {
+			return []Diagnostic{{
+				File: file, Line: perr.Pos.Line, Col: perr.Pos.Col,
+				Severity: SeverityError, Rule: RuleSyntax, Message: perr.Msg,
+			}}, nil
+		}
+		return nil, err
+	}
+
+	props, decls, propsFile, err := findProps(file)
+	if err != nil {
+		return nil, err
+	}
+	if props == nil {
+		names := PropsNames(file)
+		return []Diagnostic{{
+			File: file, Line: 1, Col: 1, Severity: SeverityWarning, Rule: RuleNoProps,
+			Message: fmt.Sprintf("no %s or %s interface found in %s", names[0], names[1], strings.Join(baseNames(Component(file)), " or ")),
+		}}, nil
+	}
+
+	c := &checker{file: file, props: props, decls: decls, used: make(map[string]bool)}
+	Walk(t.Nodes, func(n Node, scope []*Each) {
+		switch n := n.(type) {
+		case *Interpolation:
+			c.expr(n.Expr, scope)
+		case *If:
+			c.expr(n.Cond, scope)
+		case *Each:
+			c.expr(n.List, scope)
+		}
+	})
+
+	for _, m := range props.Members {
+		if !c.used[m.Name] {
+			c.diags = append(c.diags, Diagnostic{
+				File: propsFile, Line: m.Pos.Line, Col: m.Pos.Col, Severity: SeverityWarning, Rule: RuleUnused,
+				Message: fmt.Sprintf("prop %q of %s is never used in %s", m.Name, props.Name, filepath.Base(file)),
+			})
+		}
+	}
+	return c.diags, nil
+}
+
+// findProps returns the first props interface found next to the template,
+// with the declarations of its file
+func findProps(file string) (*Props, map[string]*Props, string, error) {
+	for _, candidate := range Component(file) {
+		src, err := os.ReadFile(candidate)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, "", err
+		}
+		decls := ParseDeclarations(string(src))
+		for _, name := range PropsNames(file) {
+			if p, ok := decls[name]; ok {
+				return p, decls, candidate, nil
+			}
+		}
+	}
+	return nil, nil, "", nil
+}
+
+type checker struct {
+	file  string
+	props *Props
+	decls map[string]*Props
+	used  map[string]bool
+	diags []Diagnostic
+}
+
+// expr checks one variable reference. Loop variables resolve to the element
+// type of their list; everything else must be a prop.
+func (c *checker) expr(e *Expr, scope []*Each) {
+	root := e.Root()
+	for i := len(scope) - 1; i >= 0; i-- {
+		each := scope[i]
+		if root == each.Index {
+			return
+		}
+		if root == each.Item {
+			if typ := c.typeOf(each.List, scope[:i]); typ != "" {
+				c.members(e, 1, elementType(typ))
+			}
+			return
+		}
+	}
+
+	m := c.props.Member(root)
+	if m == nil {
+		c.unknown(e, 0, c.props, "")
+		return
+	}
+	c.used[root] = true
+	c.members(e, 1, m.Type)
+}
+
+// members checks e.Path[i:] against the members of typ, when its shape is
+// known
+func (c *checker) members(e *Expr, i int, typ string) {
+	for ; i < len(e.Path); i++ {
+		obj := objectType(typ, c.decls)
+		if obj == nil {
+			return
+		}
+		m := obj.Member(e.Path[i])
+		if m == nil {
+			c.unknown(e, i, obj, strings.Join(e.Path[:i], "."))
+			return
+		}
+		typ = m.Type
+	}
+}
+
+// typeOf returns the declared type of an expression, or "" when unknown
+func (c *checker) typeOf(e *Expr, scope []*Each) string {
+	root := e.Root()
+	var typ string
+	found := false
+	for i := len(scope) - 1; i >= 0 && !found; i-- {
+		if root == scope[i].Index {
+			return "number"
+		}
+		if root == scope[i].Item {
+			typ, found = elementType(c.typeOf(scope[i].List, scope[:i])), true
+		}
+	}
+	if !found {
+		m := c.props.Member(root)
+		if m == nil {
+			return ""
+		}
+		typ = m.Type
+	}
+
+	for _, seg := range e.Path[1:] {
+		obj := objectType(typ, c.decls)
+		if obj == nil {
+			return ""
+		}
+		m := obj.Member(seg)
+		if m == nil {
+			return ""
+		}
+		typ = m.Type
+	}
+	return typ
+}
+
+// unknown reports e.Path[i] missing from obj. owner is the path of the
+// object, empty for props.
+func (c *checker) unknown(e *Expr, i int, obj *Props, owner string) {
+	name := e.Path[i]
+	where := obj.Name
+	switch {
+	case owner != "" && obj.Name == "":
+		where = owner
+	case owner != "":
+		where = fmt.Sprintf("%s (%s)", owner, obj.Name)
+	}
+
+	d := Diagnostic{File: c.file, Line: e.Pos.Line, Col: e.Pos.Col}
+	if suggestion := closest(name, obj.Members); suggestion != "" {
+		d.Severity = SeverityError
+		d.Rule = RuleMisspelled
+		d.Suggestion = suggestion
+		d.Message = fmt.Sprintf("%q is not declared in %s, did you mean %q?", name, where, suggestion)
+		if owner == "" {
+			c.used[suggestion] = true
+		}
+	} else if obj.Open {
+		d.Severity = SeverityWarning
+		d.Rule = RuleUnknown
+		d.Message = fmt.Sprintf("%q is not declared in %s, which accepts other props too", name, where)
+	} else {
+		d.Severity = SeverityError
+		d.Rule = RuleUnknown
+		d.Message = fmt.Sprintf("%q is not declared in %s", name, where)
+	}
+	c.diags = append(c.diags, d)
+}
+
+// closest returns the member whose name is a likely misspelling of name
+func closest(name string, members []*Prop) string {
+	best, bestDist := "", 0
+	for _, m := range members {
+		if strings.EqualFold(m.Name, name) {
+			return m.Name
+		}
+		limit := len(m.Name) / 4
+		if limit > 2 {
+			limit = 2
+		}
+		if limit == 0 {
+			continue
+		}
+		if d := editDistance(strings.ToLower(name), strings.ToLower(m.Name)); d <= limit && (best == "" || d < bestDist) {
+			best, bestDist = m.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance is the edit distance between a and b, counting a swap of
+// adjacent letters ("titel") as one edit
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func baseNames(files []string) []string {
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = filepath.Base(f)
+	}
+	return names
+}
+
+// SortDiagnostics orders diagnostics by file and position
+func SortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i], diags[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+}
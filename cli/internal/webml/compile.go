@@ -0,0 +1,87 @@
+package webml
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Ext is the template file extension
+const Ext = ".webml"
+
+// Compile parses a template and returns its .webml.ts module
+func Compile(file, src string) (string, error) {
+	t, err := Parse(file, src)
+	if err != nil {
+		return "", err
+	}
+	return Generate(t), nil
+}
+
+// OutputPath returns the companion module of a template, e.g.
+// Button.webml → Button.webml.ts
+func OutputPath(file string) string {
+	return file + ".ts"
+}
+
+// CompileFile compiles file and writes its companion module when the
+// output changed. It reports whether the module was written.
+func CompileFile(file string) (bool, error) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	out, err := Compile(file, string(src))
+	if err != nil {
+		return false, err
+	}
+
+	target := OutputPath(file)
+	if old, err := os.ReadFile(target); err == nil && string(old) == out {
+		return false, nil
+	}
+	return true, os.WriteFile(target, []byte(out), 0644)
+}
+
+// FindFiles returns the .webml files under the given paths. Directories are
+// searched recursively, skipping node_modules, dist and hidden directories.
+func FindFiles(paths ...string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && SkipDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(path, Ext) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// SkipDir reports whether a directory never contains sources
+func SkipDir(name string) bool {
+	return name == "node_modules" || name == "dist" || strings.HasPrefix(name, ".")
+}
@@ -0,0 +1,115 @@
+package webml
+
+import (
+	"strings"
+)
+
+const moduleHeader = `import { webml } from '@sldm/core';
+import type { TemplateResult } from '@sldm/core';
+`
+
+// Generate emits the .webml.ts module for a template: a default export
+// that returns a webml tagged template with props interpolated
+func Generate(t *Template) string {
+	g := &generator{}
+	body := g.nodes(trim(t.Nodes), nil)
+
+	var b strings.Builder
+	if g.usesAny {
+		// Nested paths and loop items are untyped
+		b.WriteString("/* eslint-disable @typescript-eslint/no-explicit-any */\n")
+	}
+	b.WriteString(moduleHeader)
+	b.WriteString("\nexport default function template(props: Record<string, unknown>): TemplateResult {\n")
+	b.WriteString("  return webml`" + body + "`;\n")
+	b.WriteString("}\n")
+	return b.String()
+}
+
+type generator struct {
+	usesAny bool
+}
+
+func (g *generator) nodes(nodes []Node, scope []*Each) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case *Text:
+			b.WriteString(escapeTemplate(n.Value))
+		case *Interpolation:
+			b.WriteString("${" + g.expr(n.Expr, scope) + "}")
+		case *If:
+			b.WriteString("${" + g.expr(n.Cond, scope) + " ? `" + g.nodes(trim(n.Then), scope) + "` : ")
+			if n.ElsePos.Line != 0 {
+				b.WriteString("`" + g.nodes(trim(n.Else), scope) + "`")
+			} else {
+				b.WriteString("''")
+			}
+			b.WriteString("}")
+		case *Each:
+			g.usesAny = true
+			inner := append(scope[:len(scope):len(scope)], n)
+			b.WriteString("${((" + g.expr(n.List, scope) + " ?? []) as any[]).map((" +
+				jsName(n.Item) + ", " + jsName(n.Index) + ") => `" +
+				g.nodes(trim(n.Body), inner) + "`).join('')}")
+		}
+	}
+	return b.String()
+}
+
+// expr resolves a path against the loop variables in scope, innermost
+// first, falling back to props
+func (g *generator) expr(e *Expr, scope []*Each) string {
+	root := e.Root()
+	base := ""
+	for i := len(scope) - 1; i >= 0 && base == ""; i-- {
+		if root == scope[i].Item || root == scope[i].Index {
+			base = jsName(root)
+		}
+	}
+
+	if base == "" {
+		if len(e.Path) == 1 {
+			return "props." + root
+		}
+		g.usesAny = true
+		base = "(props." + root + " as any)"
+	}
+	for _, seg := range e.Path[1:] {
+		base += "?." + seg
+	}
+	return base
+}
+
+// jsName maps "@index" to the JavaScript parameter name
+func jsName(name string) string {
+	if name == "@index" {
+		return "index"
+	}
+	return name
+}
+
+// trim drops leading whitespace of the first text node and trailing
+// whitespace of the last, like the TypeScript compiler trims templates and
+// block bodies
+func trim(nodes []Node) []Node {
+	if len(nodes) == 0 {
+		return nodes
+	}
+	out := append([]Node(nil), nodes...)
+	if t, ok := out[0].(*Text); ok {
+		out[0] = &Text{Pos: t.Pos, Value: strings.TrimLeft(t.Value, " \t\r\n")}
+	}
+	last := len(out) - 1
+	if t, ok := out[last].(*Text); ok {
+		out[last] = &Text{Pos: t.Pos, Value: strings.TrimRight(t.Value, " \t\r\n")}
+	}
+	return out
+}
+
+// escapeTemplate escapes text for a JavaScript template literal
+func escapeTemplate(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "`", "\\`")
+	return strings.ReplaceAll(s, "${", "\\${")
+}
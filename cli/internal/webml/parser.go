@@ -0,0 +1,335 @@
+package webml
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	pathPattern  = regexp.MustCompile(`^@?[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+	identPattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+)
+
+// Parse parses a WebML template. file is only used in error messages.
+//
+// The syntax is HTML with mustache tags:
+//
+//	{{name}}, {{user.name}}       interpolate a prop
+//	{{#if cond}}…{{else}}…{{/if}} conditional
+//	{{#each items}}…{{/each}}     loop with {{item}} and {{@index}}
+//	{{#each items as todo, i}}    loop with named variables
+//	{{! comment }}, {{!-- … --}}  dropped from the output
+//	\{{                           a literal "{{"
+func Parse(file, src string) (*Template, error) {
+	p := &parser{file: file, src: src}
+	for i := 0; i < len(src); i++ {
+		if src[i] == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
+	}
+
+	nodes, err := p.parseNodes(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{Nodes: nodes}, nil
+}
+
+type parser struct {
+	file  string
+	src   string
+	off   int
+	lines []int // offsets where lines 2.. start
+
+	// ctx is the HTML context at off, and quote the quote character of
+	// the attribute value when in ContextAttr
+	ctx   Context
+	quote byte
+}
+
+// block is an open {{#if}} or {{#each}} while parsing its body
+type block struct {
+	kind string
+	pos  Pos
+	ctx  Context
+}
+
+// tag is a scanned {{…}}
+type tag struct {
+	off     int
+	end     int
+	content string // trimmed text between the braces
+	argOff  int    // offset of content in src
+}
+
+func (p *parser) pos(off int) Pos {
+	line := sort.Search(len(p.lines), func(i int) bool { return p.lines[i] > off })
+	start := 0
+	if line > 0 {
+		start = p.lines[line-1]
+	}
+	return Pos{Line: line + 1, Col: utf8.RuneCountInString(p.src[start:off]) + 1}
+}
+
+func (p *parser) errorf(off int, format string, args ...interface{}) error {
+	return &Error{File: p.file, Pos: p.pos(off), Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseNodes parses until the end of input or the {{else}} / {{/…}} that
+// ends the open block, which is left for the caller
+func (p *parser) parseNodes(open *block) ([]Node, error) {
+	var nodes []Node
+	var text strings.Builder
+	textOff := p.off
+
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, &Text{Pos: p.pos(textOff), Value: text.String()})
+			text.Reset()
+		}
+	}
+
+	for p.off < len(p.src) {
+		rest := p.src[p.off:]
+
+		if strings.HasPrefix(rest, `\{{`) {
+			if text.Len() == 0 {
+				textOff = p.off
+			}
+			text.WriteString("{{")
+			p.off += 3
+			continue
+		}
+
+		if !strings.HasPrefix(rest, "{{") {
+			if text.Len() == 0 {
+				textOff = p.off
+			}
+			c := p.src[p.off]
+			p.advanceContext()
+			text.WriteByte(c)
+			p.off++
+			continue
+		}
+
+		t, err := p.scanTag()
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case strings.HasPrefix(t.content, "!"):
+			// Comment
+			p.off = t.end
+
+		case t.content == "else" || strings.HasPrefix(t.content, "/"):
+			if open == nil {
+				return nil, p.errorf(t.off, "unexpected {{%s}} outside of a block", t.content)
+			}
+			flush()
+			return nodes, nil
+
+		case strings.HasPrefix(t.content, "#"):
+			flush()
+			n, err := p.parseBlock(t)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+			textOff = p.off
+
+		default:
+			flush()
+			expr, err := p.parseExpr(t.content, t.argOff)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, &Interpolation{Expr: expr})
+			p.off = t.end
+			textOff = p.off
+		}
+	}
+
+	if open != nil {
+		return nil, &Error{File: p.file, Pos: open.pos, Msg: fmt.Sprintf("{{#%s}} is never closed", open.kind)}
+	}
+	flush()
+	return nodes, nil
+}
+
+// scanTag reads the {{…}} at p.off without consuming it
+func (p *parser) scanTag() (tag, error) {
+	start := p.off
+	rest := p.src[start+2:]
+
+	closer := "}}"
+	if strings.HasPrefix(rest, "!--") {
+		closer = "--}}"
+	}
+	i := strings.Index(rest, closer)
+	if i < 0 {
+		return tag{}, p.errorf(start, "unclosed {{")
+	}
+	raw := rest[:i]
+	if closer == "}}" && strings.Contains(raw, "{{") {
+		return tag{}, p.errorf(start, "unclosed {{")
+	}
+
+	lead := len(raw) - len(strings.TrimLeft(raw, " \t\r\n"))
+	return tag{
+		off:     start,
+		end:     start + 2 + i + len(closer),
+		content: strings.TrimSpace(raw),
+		argOff:  start + 2 + lead,
+	}, nil
+}
+
+func (p *parser) parseBlock(t tag) (Node, error) {
+	kind, rest := t.content[1:], ""
+	if i := strings.IndexAny(kind, " \t\r\n"); i >= 0 {
+		kind, rest = kind[:i], kind[i:]
+	}
+	args := strings.TrimSpace(rest)
+	argOff := t.argOff + 1 + len(kind) + len(rest) - len(strings.TrimLeft(rest, " \t\r\n"))
+
+	if kind != "if" && kind != "each" {
+		return nil, p.errorf(t.off, "unknown block {{#%s}}, expected #if or #each", kind)
+	}
+	if args == "" {
+		return nil, p.errorf(t.off, "{{#%s}} needs an expression", kind)
+	}
+
+	open := &block{kind: kind, pos: p.pos(t.off), ctx: p.ctx}
+	p.off = t.end
+
+	var node Node
+	var body *[]Node
+
+	switch kind {
+	case "if":
+		cond, err := p.parseExpr(args, argOff)
+		if err != nil {
+			return nil, err
+		}
+		n := &If{Pos: open.pos, Cond: cond}
+		node, body = n, &n.Then
+	case "each":
+		n := &Each{Pos: open.pos, Item: "item", Index: "@index"}
+		list, err := p.parseEachArgs(n, args, argOff)
+		if err != nil {
+			return nil, err
+		}
+		n.List = list
+		node, body = n, &n.Body
+	}
+
+	for {
+		nodes, err := p.parseNodes(open)
+		if err != nil {
+			return nil, err
+		}
+		*body = nodes
+
+		t, err := p.scanTag()
+		if err != nil {
+			return nil, err
+		}
+		if p.ctx != open.ctx {
+			return nil, p.errorf(t.off, "{{%s}} is in %s context but {{#%s}} at %s opened in %s context",
+				t.content, p.ctx, open.kind, open.pos, open.ctx)
+		}
+		p.off = t.end
+
+		if t.content == "else" {
+			n, ok := node.(*If)
+			if !ok {
+				return nil, p.errorf(t.off, "{{else}} is only allowed in {{#if}}")
+			}
+			if n.ElsePos.Line != 0 {
+				return nil, p.errorf(t.off, "{{#if}} at %s already has an {{else}}", open.pos)
+			}
+			n.ElsePos = p.pos(t.off)
+			body = &n.Else
+			continue
+		}
+
+		closing := strings.TrimSpace(t.content[1:])
+		if closing != open.kind {
+			return nil, p.errorf(t.off, "{{/%s}} does not match {{#%s}} at %s", closing, open.kind, open.pos)
+		}
+		return node, nil
+	}
+}
+
+// parseEachArgs parses "items", "items as todo", "items as todo, i" and the
+// Handlebars form "items as |todo i|"
+func (p *parser) parseEachArgs(n *Each, args string, argOff int) (*Expr, error) {
+	listSrc, names, hasAs := strings.Cut(args, " as ")
+	list, err := p.parseExpr(strings.TrimSpace(listSrc), argOff)
+	if err != nil {
+		return nil, err
+	}
+	if !hasAs {
+		return list, nil
+	}
+
+	namesOff := argOff + len(listSrc) + len(" as ")
+	names = strings.Trim(strings.TrimSpace(names), "|")
+	fields := strings.FieldsFunc(names, func(r rune) bool { return r == ',' || r == ' ' })
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, p.errorf(namesOff, "expected {{#each list as item}} or {{#each list as item, index}}")
+	}
+	for _, name := range fields {
+		if !identPattern.MatchString(name) {
+			return nil, p.errorf(namesOff, "invalid loop variable %q", name)
+		}
+	}
+	n.Item = fields[0]
+	if len(fields) == 2 {
+		n.Index = fields[1]
+	}
+	return list, nil
+}
+
+func (p *parser) parseExpr(src string, off int) (*Expr, error) {
+	if !pathPattern.MatchString(src) {
+		return nil, p.errorf(off, "invalid expression %q, expected a name such as user.name", src)
+	}
+	return &Expr{Pos: p.pos(off), Path: strings.Split(src, "."), Context: p.ctx}, nil
+}
+
+// advanceContext updates the HTML context for the byte at p.off
+func (p *parser) advanceContext() {
+	rest := p.src[p.off:]
+	switch p.ctx {
+	case ContextText:
+		if strings.HasPrefix(rest, "<!--") {
+			p.ctx = ContextComment
+		} else if len(rest) > 1 && rest[0] == '<' && (isLetter(rest[1]) || rest[1] == '/') {
+			p.ctx = ContextTag
+		}
+	case ContextComment:
+		if rest[0] == '>' && p.off >= 2 && p.src[p.off-2:p.off] == "--" {
+			p.ctx = ContextText
+		}
+	case ContextTag:
+		switch rest[0] {
+		case '"', '\'':
+			p.ctx = ContextAttr
+			p.quote = rest[0]
+		case '>':
+			p.ctx = ContextText
+		}
+	case ContextAttr:
+		if rest[0] == p.quote {
+			p.ctx = ContextTag
+		}
+	}
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
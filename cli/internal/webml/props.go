@@ -0,0 +1,364 @@
+package webml
+
+import (
+	"strings"
+
+	"github.com/kluth/solidum-cli/internal/codemod"
+)
+
+// Prop is a member of a props interface
+type Prop struct {
+	Name     string
+	Type     string
+	Optional bool
+	Pos      Pos
+}
+
+// Props is an interface or object type alias declared in a component file
+type Props struct {
+	Name    string
+	Pos     Pos
+	Members []*Prop
+	Extends []string
+
+	// Open is set when the type accepts names it doesn't list: it has an
+	// index signature or extends a type that couldn't be resolved
+	Open bool
+}
+
+// Member returns the member called name, or nil
+func (p *Props) Member(name string) *Prop {
+	for _, m := range p.Members {
+		if m.Name == name {
+			return m
+		}
+	}
+	return nil
+}
+
+// ParseDeclarations extracts the interfaces and object type aliases of a
+// TypeScript file by name. It only reads declarations, it doesn't type check:
+// member types are kept as source text.
+func ParseDeclarations(src string) map[string]*Props {
+	var tokens []codemod.Token
+	for _, t := range codemod.Tokenize(src) {
+		if t.Kind != codemod.TokenComment {
+			tokens = append(tokens, t)
+		}
+	}
+	d := &declParser{tokens: tokens}
+
+	decls := make(map[string]*Props)
+	for d.i < len(d.tokens) {
+		t := d.next()
+		if t.Kind != codemod.TokenKeyword || (t.Text != "interface" && t.Text != "type") {
+			continue
+		}
+		name := d.next()
+		if name.Kind != codemod.TokenIdent {
+			continue
+		}
+		props := &Props{Name: name.Text, Pos: Pos{Line: name.Line, Col: name.Col}}
+		d.skipGenerics()
+
+		if t.Text == "interface" {
+			if d.peek().Text == "extends" {
+				d.next()
+				props.Extends = d.extendsList()
+			}
+		} else if d.peek().Text == "=" {
+			d.next()
+		} else {
+			continue
+		}
+
+		if d.peek().Text != "{" {
+			continue
+		}
+		d.next()
+		d.members(props)
+		decls[props.Name] = props
+	}
+
+	// Merge members of extended types declared in the same file
+	for _, p := range decls {
+		resolveExtends(p, decls, map[string]bool{p.Name: true})
+	}
+	return decls
+}
+
+func resolveExtends(p *Props, decls map[string]*Props, seen map[string]bool) {
+	for _, name := range p.Extends {
+		base, ok := decls[baseTypeName(name)]
+		if !ok || seen[base.Name] {
+			p.Open = true
+			continue
+		}
+		seen[base.Name] = true
+		resolveExtends(base, decls, seen)
+		for _, m := range base.Members {
+			if p.Member(m.Name) == nil {
+				p.Members = append(p.Members, m)
+			}
+		}
+		p.Open = p.Open || base.Open
+	}
+	p.Extends = nil
+}
+
+// baseTypeName strips type arguments, e.g. "Base<T>" → "Base"
+func baseTypeName(name string) string {
+	if i := strings.IndexByte(name, '<'); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
+
+type declParser struct {
+	tokens []codemod.Token
+	i      int
+}
+
+// next returns the next significant token, or an empty token at the end
+func (d *declParser) next() codemod.Token {
+	for d.i < len(d.tokens) {
+		t := d.tokens[d.i]
+		d.i++
+		if t.Kind != codemod.TokenWhitespace {
+			return t
+		}
+	}
+	return codemod.Token{}
+}
+
+func (d *declParser) peek() codemod.Token {
+	i := d.i
+	t := d.next()
+	d.i = i
+	return t
+}
+
+// skipGenerics skips a "<T extends X = Y>" parameter list
+func (d *declParser) skipGenerics() {
+	if d.peek().Text != "<" {
+		return
+	}
+	depth := 0
+	for d.i < len(d.tokens) {
+		t := d.next()
+		depth += angleDelta(t.Text)
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// extendsList reads "A, B<T>" up to the opening brace
+func (d *declParser) extendsList() []string {
+	var names []string
+	var cur strings.Builder
+	depth := 0
+	for d.i < len(d.tokens) {
+		t := d.peek()
+		if depth == 0 && (t.Text == "{" || t.Text == "") {
+			break
+		}
+		d.next()
+		depth += angleDelta(t.Text)
+		if depth == 0 && t.Text == "," {
+			names = append(names, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteString(t.Text)
+	}
+	if cur.Len() > 0 {
+		names = append(names, cur.String())
+	}
+	return names
+}
+
+// members reads an object type body after its opening brace, through the
+// closing brace
+func (d *declParser) members(props *Props) {
+	for d.i < len(d.tokens) {
+		t := d.next()
+		switch {
+		case t.Text == "}" || t.Text == "":
+			return
+		case t.Text == ";" || t.Text == ",":
+			continue
+		case t.Text == "[":
+			// Index signature or mapped type
+			props.Open = true
+			d.skipTo("]")
+			d.memberType()
+			continue
+		case t.Text == "readonly" && d.peek().Kind == codemod.TokenIdent:
+			t = d.next()
+		}
+
+		if t.Kind != codemod.TokenIdent && t.Kind != codemod.TokenKeyword && t.Kind != codemod.TokenString {
+			// Call or construct signature, or something we don't model
+			d.memberType()
+			continue
+		}
+
+		m := &Prop{Name: strings.Trim(t.Text, `'"`), Pos: Pos{Line: t.Line, Col: t.Col}}
+		if d.peek().Text == "?" {
+			d.next()
+			m.Optional = true
+		}
+		switch d.peek().Text {
+		case ":":
+			d.next()
+			m.Type = d.memberType()
+		case "(", "<":
+			// Method signature
+			m.Type = "function"
+			d.memberType()
+		}
+		props.Members = append(props.Members, m)
+	}
+}
+
+// skipTo advances past the next token with the given text at depth 0
+func (d *declParser) skipTo(text string) {
+	depth := 0
+	for d.i < len(d.tokens) {
+		t := d.next()
+		if depth == 0 && t.Text == text {
+			return
+		}
+		depth += bracketDelta(t.Text)
+	}
+}
+
+// memberType reads a member's type up to the separator that ends it: a
+// semicolon, comma, the closing brace (left unread), or a line break that
+// isn't in the middle of the type
+func (d *declParser) memberType() string {
+	var b strings.Builder
+	depth := 0
+	last := ""
+	for d.i < len(d.tokens) {
+		t := d.tokens[d.i]
+		if t.Kind == codemod.TokenWhitespace {
+			d.i++
+			if depth == 0 && strings.Contains(t.Text, "\n") && b.Len() > 0 && !continuesType(last) {
+				break
+			}
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			continue
+		}
+		if depth == 0 && (t.Text == ";" || t.Text == ",") {
+			d.i++
+			break
+		}
+		if depth == 0 && t.Text == "}" {
+			break
+		}
+		d.i++
+		depth += bracketDelta(t.Text) + angleDelta(t.Text)
+		b.WriteString(t.Text)
+		last = t.Text
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// continuesType reports whether a type can't end with tok, so the next line
+// carries on with it
+func continuesType(tok string) bool {
+	switch tok {
+	case "|", "&", "=>", ":", "?", ",", "(", "[", "{", "<", "extends", "keyof", "typeof":
+		return true
+	}
+	return false
+}
+
+func bracketDelta(tok string) int {
+	switch tok {
+	case "(", "[", "{":
+		return 1
+	case ")", "]", "}":
+		return -1
+	}
+	return 0
+}
+
+func angleDelta(tok string) int {
+	switch tok {
+	case "<":
+		return 1
+	case ">":
+		return -1
+	case ">>":
+		return -2
+	case ">>>":
+		return -3
+	}
+	return 0
+}
+
+// elementType returns the element type of an array type such as "Todo[]",
+// "Array<Todo>" or "readonly Todo[] | undefined", or "" when it isn't one
+func elementType(typ string) string {
+	typ = strings.TrimPrefix(nonNullable(typ), "readonly ")
+	switch {
+	case strings.HasSuffix(typ, "[]"):
+		return strings.Trim(strings.TrimSuffix(typ, "[]"), "()")
+	case strings.HasPrefix(typ, "Array<") && strings.HasSuffix(typ, ">"):
+		return strings.TrimSuffix(strings.TrimPrefix(typ, "Array<"), ">")
+	case strings.HasPrefix(typ, "ReadonlyArray<") && strings.HasSuffix(typ, ">"):
+		return strings.TrimSuffix(strings.TrimPrefix(typ, "ReadonlyArray<"), ">")
+	}
+	return ""
+}
+
+// nonNullable drops "| undefined" and "| null" from a union
+func nonNullable(typ string) string {
+	var parts []string
+	for _, part := range splitUnion(typ) {
+		if part != "undefined" && part != "null" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// splitUnion splits a type on its top-level "|"
+func splitUnion(typ string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(typ); i++ {
+		switch typ[i] {
+		case '(', '[', '{', '<':
+			depth++
+		case ')', ']', '}', '>':
+			depth--
+		case '|':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(typ[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, strings.TrimSpace(typ[start:]))
+}
+
+// objectType returns the members of typ when it is an object literal type or
+// names a declaration in decls, or nil when its shape is unknown. Object
+// literal types have no name.
+func objectType(typ string, decls map[string]*Props) *Props {
+	typ = nonNullable(typ)
+	if strings.HasPrefix(typ, "{") {
+		p := ParseDeclarations("type T = " + typ)["T"]
+		if p != nil {
+			p.Name = ""
+		}
+		return p
+	}
+	return decls[baseTypeName(typ)]
+}
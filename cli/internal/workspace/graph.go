@@ -0,0 +1,225 @@
+package workspace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// InternalDependencies returns the names of workspace packages that pkg
+// depends on. When includeDev is false, devDependencies are ignored.
+func (w *Workspace) InternalDependencies(pkg *Package, includeDev bool) []string {
+	var names []string
+	add := func(deps map[string]string) {
+		for name := range deps {
+			if _, ok := w.byName[name]; ok && name != pkg.Name {
+				names = append(names, name)
+			}
+		}
+	}
+	add(pkg.Dependencies)
+	add(pkg.PeerDependencies)
+	if includeDev {
+		add(pkg.DevDependencies)
+	}
+	sort.Strings(names)
+	return dedupe(names)
+}
+
+// Dependents returns the workspace packages that depend on the named package
+func (w *Workspace) Dependents(name string, includeDev bool) []*Package {
+	var dependents []*Package
+	for _, pkg := range w.Packages {
+		for _, dep := range w.InternalDependencies(pkg, includeDev) {
+			if dep == name {
+				dependents = append(dependents, pkg)
+				break
+			}
+		}
+	}
+	return dependents
+}
+
+// Layers groups packages into build layers: every package only depends on
+// packages in earlier layers. Cycles that exist only through
+// devDependencies (e.g. utils ↔ testing) are broken by dropping the dev edge.
+func (w *Workspace) Layers(pkgs []*Package) ([][]*Package, error) {
+	edges := w.buildEdges(pkgs)
+
+	remaining := make(map[string]*Package)
+	for _, pkg := range pkgs {
+		remaining[pkg.Name] = pkg
+	}
+
+	var layers [][]*Package
+	for len(remaining) > 0 {
+		var layer []*Package
+		for _, pkg := range remaining {
+			ready := true
+			for _, dep := range edges[pkg.Name] {
+				if _, pending := remaining[dep]; pending {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				layer = append(layer, pkg)
+			}
+		}
+		if len(layer) == 0 {
+			var names []string
+			for name := range remaining {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("dependency cycle between %s", strings.Join(names, ", "))
+		}
+
+		sort.Slice(layer, func(i, j int) bool { return layer[i].Name < layer[j].Name })
+		for _, pkg := range layer {
+			delete(remaining, pkg.Name)
+		}
+		layers = append(layers, layer)
+	}
+
+	return layers, nil
+}
+
+// DependencyEdges returns the internal dependencies of every package by
+// name, as used for ordering: dev-only cycles are broken
+func (w *Workspace) DependencyEdges() map[string][]string {
+	return w.buildEdges(w.Packages)
+}
+
+// buildEdges returns the dependency edges used for ordering. Within a
+// strongly connected component, edges that exist only as devDependencies
+// are dropped.
+func (w *Workspace) buildEdges(pkgs []*Package) map[string][]string {
+	all := make(map[string][]string)
+	for _, pkg := range pkgs {
+		all[pkg.Name] = w.InternalDependencies(pkg, true)
+	}
+	component := stronglyConnected(pkgs, all)
+
+	edges := make(map[string][]string)
+	for _, pkg := range pkgs {
+		prod := make(map[string]bool)
+		for _, dep := range w.InternalDependencies(pkg, false) {
+			prod[dep] = true
+		}
+		for _, dep := range all[pkg.Name] {
+			if !prod[dep] && component[dep] == component[pkg.Name] {
+				continue
+			}
+			edges[pkg.Name] = append(edges[pkg.Name], dep)
+		}
+	}
+	return edges
+}
+
+// stronglyConnected labels every package with its component using
+// Tarjan's algorithm
+func stronglyConnected(pkgs []*Package, edges map[string][]string) map[string]int {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	component := make(map[string]int)
+	var stack []string
+	next, label := 0, 0
+
+	var visit func(name string)
+	visit = func(name string) {
+		index[name] = next
+		low[name] = next
+		next++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, dep := range edges[name] {
+			if _, seen := index[dep]; !seen {
+				if _, known := edges[dep]; !known {
+					continue
+				}
+				visit(dep)
+				if low[dep] < low[name] {
+					low[name] = low[dep]
+				}
+			} else if onStack[dep] && index[dep] < low[name] {
+				low[name] = index[dep]
+			}
+		}
+
+		if low[name] == index[name] {
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component[top] = label
+				if top == name {
+					break
+				}
+			}
+			label++
+		}
+	}
+
+	for _, pkg := range pkgs {
+		if _, seen := index[pkg.Name]; !seen {
+			visit(pkg.Name)
+		}
+	}
+	return component
+}
+
+// TopoSort orders packages so that dependencies come before dependents
+func (w *Workspace) TopoSort(pkgs []*Package) ([]*Package, error) {
+	layers, err := w.Layers(pkgs)
+	if err != nil {
+		return nil, err
+	}
+	var sorted []*Package
+	for _, layer := range layers {
+		sorted = append(sorted, layer...)
+	}
+	return sorted, nil
+}
+
+// Filter selects packages by name or directory. Patterns may end in "*",
+// e.g. "@sldm/*" or "./packages/*". No patterns selects every package.
+func (w *Workspace) Filter(patterns ...string) []*Package {
+	if len(patterns) == 0 {
+		return w.Packages
+	}
+	var selected []*Package
+	for _, pkg := range w.Packages {
+		for _, pattern := range patterns {
+			if matchPackage(pkg, pattern) {
+				selected = append(selected, pkg)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+func matchPackage(pkg *Package, pattern string) bool {
+	target := pkg.Name
+	if strings.HasPrefix(pattern, "./") {
+		target = pkg.Dir
+		pattern = strings.TrimPrefix(pattern, "./")
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(target, strings.TrimSuffix(pattern, "*"))
+	}
+	return target == pattern
+}
+
+func dedupe(sorted []string) []string {
+	out := sorted[:0]
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
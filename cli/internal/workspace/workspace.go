@@ -0,0 +1,305 @@
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Package is a package.json inside the workspace
+type Package struct {
+	Name             string            `json:"name"`
+	Version          string            `json:"version"`
+	Private          bool              `json:"private"`
+	Scripts          map[string]string `json:"scripts"`
+	Dependencies     map[string]string `json:"dependencies"`
+	DevDependencies  map[string]string `json:"devDependencies"`
+	PeerDependencies map[string]string `json:"peerDependencies"`
+	Engines          map[string]string `json:"engines"`
+	PackageManager   string            `json:"packageManager"`
+	Homepage         string            `json:"homepage"`
+	Main             string            `json:"main"`
+	Module           string            `json:"module"`
+	Exports          json.RawMessage   `json:"exports"`
+
+	// Dir is the package directory relative to the workspace root
+	Dir string `json:"-"`
+}
+
+// HasScript reports whether the package defines the given npm script
+func (p *Package) HasScript(name string) bool {
+	_, ok := p.Scripts[name]
+	return ok
+}
+
+// AllDependencies merges dependencies, devDependencies and peerDependencies
+func (p *Package) AllDependencies() map[string]string {
+	all := make(map[string]string)
+	for _, deps := range []map[string]string{p.PeerDependencies, p.DevDependencies, p.Dependencies} {
+		for name, spec := range deps {
+			all[name] = spec
+		}
+	}
+	return all
+}
+
+// Workspace is a pnpm monorepo or a single project
+type Workspace struct {
+	Root        string
+	Globs       []string
+	RootPackage *Package
+	Packages    []*Package
+
+	byName map[string]*Package
+}
+
+// IsMonorepo reports whether the workspace is driven by pnpm-workspace.yaml
+func (w *Workspace) IsMonorepo() bool {
+	return len(w.Globs) > 0
+}
+
+// Lookup finds a workspace package by name
+func (w *Workspace) Lookup(name string) (*Package, bool) {
+	pkg, ok := w.byName[name]
+	return pkg, ok
+}
+
+// Path resolves a workspace-relative path
+func (w *Workspace) Path(rel ...string) string {
+	return filepath.Join(append([]string{w.Root}, rel...)...)
+}
+
+// Rel returns path relative to the workspace root with forward slashes, or
+// path itself when it lies outside the workspace
+func (w *Workspace) Rel(path string) string {
+	rel, err := filepath.Rel(w.Root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Owner returns the package whose directory holds the workspace-relative
+// path, falling back to the root package
+func (w *Workspace) Owner(rel string) *Package {
+	var owner *Package
+	for _, pkg := range w.Packages {
+		dir := filepath.ToSlash(pkg.Dir)
+		if dir != "." && !strings.HasPrefix(rel, dir+"/") {
+			continue
+		}
+		if owner == nil || len(dir) > len(owner.Dir) {
+			owner = pkg
+		}
+	}
+	if owner == nil {
+		return w.RootPackage
+	}
+	return owner
+}
+
+// Load reads the workspace rooted at dir. Directories without
+// pnpm-workspace.yaml are treated as a single-package project.
+func Load(dir string) (*Workspace, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &Workspace{Root: root, byName: make(map[string]*Package)}
+
+	if rootPkg, err := ReadPackage(filepath.Join(root, "package.json")); err == nil {
+		rootPkg.Dir = "."
+		ws.RootPackage = rootPkg
+	}
+
+	globs, err := ReadGlobs(filepath.Join(root, "pnpm-workspace.yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			if ws.RootPackage != nil {
+				ws.Packages = []*Package{ws.RootPackage}
+				ws.byName[ws.RootPackage.Name] = ws.RootPackage
+			}
+			return ws, nil
+		}
+		return nil, err
+	}
+	ws.Globs = globs
+
+	dirs, err := ResolveGlobs(root, globs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rel := range dirs {
+		pkg, err := ReadPackage(filepath.Join(root, rel, "package.json"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		pkg.Dir = rel
+		ws.Packages = append(ws.Packages, pkg)
+		if pkg.Name != "" {
+			ws.byName[pkg.Name] = pkg
+		}
+	}
+
+	return ws, nil
+}
+
+// ReadPackage parses a package.json file
+func ReadPackage(path string) (*Package, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg Package
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &pkg, nil
+}
+
+// ReadGlobs reads the `packages:` list from pnpm-workspace.yaml
+func ReadGlobs(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var globs []string
+	inPackages := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.HasPrefix(trimmed, "packages:")
+			continue
+		}
+
+		if inPackages && strings.HasPrefix(trimmed, "-") {
+			glob := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+			glob = strings.Trim(glob, `'"`)
+			globs = append(globs, glob)
+		}
+	}
+
+	return globs, scanner.Err()
+}
+
+// ResolveGlobs expands workspace globs into package directories relative to
+// root. Globs prefixed with "!" exclude directories.
+func ResolveGlobs(root string, globs []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var excluded []string
+
+	for _, glob := range globs {
+		if strings.HasPrefix(glob, "!") {
+			excluded = append(excluded, strings.TrimPrefix(glob, "!"))
+			continue
+		}
+		matches, err := MatchGlob(root, glob)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			seen[m] = true
+		}
+	}
+
+	var dirs []string
+	for dir := range seen {
+		skip := false
+		for _, ex := range excluded {
+			if ok, _ := filepath.Match(ex, dir); ok {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// MatchGlob returns the directories under root matching a single workspace
+// glob. "**" matches any number of directories.
+func MatchGlob(root, glob string) ([]string, error) {
+	glob = strings.TrimSuffix(filepath.ToSlash(glob), "/")
+
+	if !strings.Contains(glob, "**") {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(glob)))
+		if err != nil {
+			return nil, err
+		}
+		var dirs []string
+		for _, m := range matches {
+			if info, err := os.Stat(m); err == nil && info.IsDir() {
+				rel, _ := filepath.Rel(root, m)
+				dirs = append(dirs, filepath.ToSlash(rel))
+			}
+		}
+		return dirs, nil
+	}
+
+	base := strings.SplitN(glob, "**", 2)[0]
+	var dirs []string
+	err := filepath.Walk(filepath.Join(root, filepath.FromSlash(base)), func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		if path != root && (info.Name() == "node_modules" || strings.HasPrefix(info.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		if MatchPath(glob, rel) {
+			dirs = append(dirs, rel)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// MatchPath matches a slash-separated path against a glob where "**"
+// spans directories and "*" matches within a single segment
+func MatchPath(glob, path string) bool {
+	return matchSegments(strings.Split(glob, "/"), strings.Split(path, "/"))
+}
+
+func matchSegments(glob, path []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(glob[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(glob[0], path[0]); !ok {
+			return false
+		}
+		glob, path = glob[1:], path[1:]
+	}
+	return len(path) == 0
+}
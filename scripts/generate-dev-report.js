@@ -4,15 +4,20 @@
  * Generate development report for Solidum framework
  */
 
-import { writeFileSync, mkdirSync, existsSync } from 'fs';
+import { writeFileSync, mkdirSync, existsSync, readFileSync } from 'fs';
 import { join, dirname } from 'path';
 import { fileURLToPath } from 'url';
 
 const __dirname = dirname(fileURLToPath(import.meta.url));
 const rootDir = join(__dirname, '..');
 
-// Simulated bundle analyzer (in real world, you'd analyze actual bundles)
+// Bundle sizes measured by `solidum size`, falling back to simulated numbers
 function analyzeBundles() {
+  const measured = join(rootDir, '.solidum', 'size.json');
+  if (existsSync(measured)) {
+    return JSON.parse(readFileSync(measured, 'utf8'));
+  }
+
   const packages = [
     { file: '@sldm/core.js', size: 26400, gzipSize: 8800 },
     { file: '@sldm/ui.js', size: 30100, gzipSize: 10000 },